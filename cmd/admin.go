@@ -6,6 +6,7 @@ package cmd
 import (
 	"github.com/alist-org/alist/v3/internal/db"
 	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/pkg/utils/random"
 	"github.com/spf13/cobra"
 )
 
@@ -13,20 +14,60 @@ import (
 var passwordCmd = &cobra.Command{
 	Use:     "admin",
 	Aliases: []string{"password"},
-	Short:   "Show admin user's info",
+	Short:   "Show admin user's info and manage its password",
 	Run: func(cmd *cobra.Command, args []string) {
 		Init()
 		admin, err := db.GetAdmin()
 		if err != nil {
 			utils.Log.Errorf("failed get admin user: %+v", err)
 		} else {
-			utils.Log.Infof("admin user's info: \nusername: %s\npassword: %s", admin.Username, admin.Password)
+			utils.Log.Infof("admin user's info: \nusername: %s\n"+
+				"the password is stored hashed, use `admin set-password` or `admin reset` to change it", admin.Username)
 		}
 	},
 }
 
+var setPasswordCmd = &cobra.Command{
+	Use:   "set-password <password>",
+	Short: "Set admin user's password",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		Init()
+		setAdminPassword(args[0])
+	},
+}
+
+var resetPasswordCmd = &cobra.Command{
+	Use:   "reset",
+	Short: "Reset admin user's password to a random one",
+	Run: func(cmd *cobra.Command, args []string) {
+		Init()
+		password := random.SecureString(16)
+		if setAdminPassword(password) {
+			utils.Log.Infof("admin user's new password: %s", password)
+		}
+	},
+}
+
+func setAdminPassword(password string) bool {
+	admin, err := db.GetAdmin()
+	if err != nil {
+		utils.Log.Errorf("failed get admin user: %+v", err)
+		return false
+	}
+	admin.Password = password
+	if err := db.UpdateUser(admin); err != nil {
+		utils.Log.Errorf("failed update admin user: %+v", err)
+		return false
+	}
+	utils.Log.Infof("admin user's password has been updated")
+	return true
+}
+
 func init() {
 	rootCmd.AddCommand(passwordCmd)
+	passwordCmd.AddCommand(setPasswordCmd)
+	passwordCmd.AddCommand(resetPasswordCmd)
 
 	// Here you will define your flags and configuration settings.
 
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/backup"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var backupPassword string
+var backupReplace bool
+
+// backupCmd represents the backup command
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Create or restore a backup of users, metas, storages and settings",
+}
+
+var backupCreateCmd = &cobra.Command{
+	Use:   "create [file]",
+	Short: "Create a backup to the json file",
+	Long: `Create a backup of users, groups, acl rules, metas, storages and settings to the json file,
+which can be restored to any type of database. The secrets are encrypted if the password is set.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		Init()
+		file := fmt.Sprintf("alist-backup-%s.json", time.Now().Format("20060102150405"))
+		if len(args) > 0 {
+			file = args[0]
+		}
+		b, err := backup.Create(backupPassword)
+		if err != nil {
+			utils.Log.Fatalf("failed create backup: %+v", err)
+		}
+		data, err := utils.Json.MarshalIndent(b, "", "  ")
+		if err != nil {
+			utils.Log.Fatalf("failed marshal backup: %+v", err)
+		}
+		if err := os.WriteFile(file, data, 0600); err != nil {
+			utils.Log.Fatalf("failed write backup: %+v", err)
+		}
+		utils.Log.Infof("backup is created: %s", file)
+	},
+}
+
+var backupRestoreCmd = &cobra.Command{
+	Use:   "restore <file>",
+	Short: "Restore the backup from the json file",
+	Long: `Restore the backup from the json file, the data is merged with the existing one by default,
+use --replace to delete the users, groups, acl rules, metas and storages not in the backup.
+The server should be stopped while restoring.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		Init()
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			utils.Log.Fatalf("failed read backup: %+v", err)
+		}
+		var b model.Backup
+		if err := utils.Json.Unmarshal(data, &b); err != nil {
+			utils.Log.Fatalf("failed unmarshal backup: %+v", err)
+		}
+		if err := backup.Restore(&b, backupPassword, backupReplace); err != nil {
+			utils.Log.Fatalf("failed restore backup: %+v", err)
+		}
+		utils.Log.Infof("backup is restored: %s", args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(backupCmd)
+	backupCmd.AddCommand(backupCreateCmd)
+	backupCmd.AddCommand(backupRestoreCmd)
+	backupCmd.PersistentFlags().StringVar(&backupPassword, "password", "", "the password to encrypt or decrypt the secrets")
+	backupRestoreCmd.Flags().BoolVar(&backupReplace, "replace", false, "replace the existing data instead of merging")
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/alist-org/alist/v3/internal/bootstrap"
+	"github.com/alist-org/alist/v3/internal/fuse"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/spf13/cobra"
+	cgofuse "github.com/winfsp/cgofuse/fuse"
+)
+
+var mountPath string
+
+// mountCmd represents the mount command
+var mountCmd = &cobra.Command{
+	Use:   "mount <mountpoint>",
+	Short: "Mount the storages as a local directory with FUSE",
+	Long: `Mount the storages as a local directory with FUSE,
+the files are read by range and written back to the storage while closing.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		Init()
+		bootstrap.LoadStorages()
+		host := cgofuse.NewFileSystemHost(&fuse.Fs{RootFolder: utils.StandardizePath(mountPath)})
+		quit := make(chan os.Signal, 1)
+		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+		go func() {
+			<-quit
+			utils.Log.Println("Unmount ...")
+			host.Unmount()
+		}()
+		utils.Log.Infof("mount [%s] at %s", mountPath, args[0])
+		if !host.Mount(args[0], []string{"-o", "fsname=alist"}) {
+			utils.Log.Fatalf("failed to mount at %s", args[0])
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mountCmd)
+	mountCmd.Flags().StringVar(&mountPath, "path", "/", "the path in alist to mount")
+}
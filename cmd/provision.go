@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/provision"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var provisionDryRun bool
+
+// provisionCmd represents the provision command
+var provisionCmd = &cobra.Command{
+	Use:   "provision [file]",
+	Short: "Reconcile the storages, users, metas and settings with the provisioning file",
+	Long: `Reconcile the storages, users, metas and settings with the provisioning file,
+the file in config is used by default. Use --dry-run to print the changes without applying them.
+The server should be stopped while applying, or send SIGHUP to the server instead.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		Init()
+		file := conf.Conf.Provision
+		if len(args) > 0 {
+			file = args[0]
+		}
+		if file == "" {
+			utils.Log.Fatalf("the provisioning file is not set")
+		}
+		f, err := provision.Load(file)
+		if err != nil {
+			utils.Log.Fatalf("failed load provisioning file: %+v", err)
+		}
+		changes, err := provision.Reconcile(context.Background(), f, provision.Options{DryRun: provisionDryRun})
+		for _, c := range changes {
+			fmt.Println(c)
+		}
+		if err != nil {
+			utils.Log.Fatalf("failed provision: %+v", err)
+		}
+		if len(changes) == 0 {
+			fmt.Println("no changes")
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(provisionCmd)
+	provisionCmd.Flags().BoolVar(&provisionDryRun, "dry-run", false, "print the changes without applying them")
+}
@@ -15,6 +15,8 @@ import (
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/alist-org/alist/v3/server"
+	"github.com/alist-org/alist/v3/server/handles"
+	"github.com/alist-org/alist/v3/server/s3"
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -29,7 +31,15 @@ the address is defined in config file`,
 	Run: func(cmd *cobra.Command, args []string) {
 		Init()
 		bootstrap.InitAria2()
+		bootstrap.InitWebhook()
+		bootstrap.InitProvision()
 		bootstrap.LoadStorages()
+		bootstrap.InitHealth()
+		bootstrap.InitTaskManager()
+		bootstrap.InitAudit()
+		if conf.Conf.Metrics.Enable {
+			bootstrap.InitMetrics()
+		}
 		if !flags.Debug && !flags.Dev {
 			gin.SetMode(gin.ReleaseMode)
 		}
@@ -51,6 +61,41 @@ the address is defined in config file`,
 				utils.Log.Fatalf("failed to start: %s", err.Error())
 			}
 		}()
+		var s3Srv *http.Server
+		if conf.Conf.S3.Enable {
+			s3r := gin.New()
+			s3r.Use(gin.LoggerWithWriter(log.StandardLogger().Out), gin.RecoveryWithWriter(log.StandardLogger().Out))
+			s3.Init(s3r)
+			s3Base := fmt.Sprintf("%s:%d", conf.Conf.Address, conf.Conf.S3.Port)
+			utils.Log.Infof("start s3 server @ %s", s3Base)
+			s3Srv = &http.Server{Addr: s3Base, Handler: s3r}
+			go func() {
+				var err error
+				if conf.Conf.S3.SSL {
+					err = s3Srv.ListenAndServeTLS(conf.Conf.Scheme.CertFile, conf.Conf.Scheme.KeyFile)
+				} else {
+					err = s3Srv.ListenAndServe()
+				}
+				if err != nil && err != http.ErrServerClosed {
+					utils.Log.Fatalf("failed to start s3 server: %s", err.Error())
+				}
+			}()
+		}
+		var metricsSrv *http.Server
+		if conf.Conf.Metrics.Enable && conf.Conf.Metrics.Port != 0 {
+			mr := gin.New()
+			mr.Use(gin.RecoveryWithWriter(log.StandardLogger().Out))
+			mr.GET("/metrics", handles.Metrics)
+			metricsBase := fmt.Sprintf("%s:%d", conf.Conf.Address, conf.Conf.Metrics.Port)
+			utils.Log.Infof("start metrics server @ %s", metricsBase)
+			metricsSrv = &http.Server{Addr: metricsBase, Handler: mr}
+			go func() {
+				err := metricsSrv.ListenAndServe()
+				if err != nil && err != http.ErrServerClosed {
+					utils.Log.Fatalf("failed to start metrics server: %s", err.Error())
+				}
+			}()
+		}
 		// Wait for interrupt signal to gracefully shutdown the server with
 		// a timeout of 5 seconds.
 		quit := make(chan os.Signal)
@@ -66,6 +111,16 @@ the address is defined in config file`,
 		if err := srv.Shutdown(ctx); err != nil {
 			utils.Log.Fatal("Server Shutdown:", err)
 		}
+		if s3Srv != nil {
+			if err := s3Srv.Shutdown(ctx); err != nil {
+				utils.Log.Fatal("S3 Server Shutdown:", err)
+			}
+		}
+		if metricsSrv != nil {
+			if err := metricsSrv.Shutdown(ctx); err != nil {
+				utils.Log.Fatal("Metrics Server Shutdown:", err)
+			}
+		}
 		// catching ctx.Done(). timeout of 3 seconds.
 		select {
 		case <-ctx.Done():
@@ -39,12 +39,12 @@ func (d *Pan115) List(ctx context.Context, dir model.Obj, args model.ListArgs) (
 		return nil, err
 	}
 	return utils.SliceConvert(files, func(src driver115.File) (model.Obj, error) {
-		return src, nil
+		return FileObj{File: src}, nil
 	})
 }
 
 func (d *Pan115) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
-	downloadInfo, err := d.client.Download(file.(driver115.File).PickCode)
+	downloadInfo, err := d.client.Download(file.(FileObj).PickCode)
 	if err != nil {
 		return nil, err
 	}
@@ -90,4 +90,14 @@ func (d *Pan115) Put(ctx context.Context, dstDir model.Obj, stream model.FileStr
 	return d.client.UploadFastOrByMultipart(dstDir.GetID(), stream.GetName(), stream.GetSize(), tempFile)
 }
 
+func (d *Pan115) GetSpace(ctx context.Context) (int64, int64, error) {
+	resp, err := d.getSpace()
+	if err != nil {
+		return 0, 0, err
+	}
+	info := resp.Data.SpaceInfo
+	return int64(info.AllTotal.Size), int64(info.AllUse.Size), nil
+}
+
 var _ driver.Driver = (*Pan115)(nil)
+var _ driver.Quota = (*Pan115)(nil)
@@ -3,6 +3,33 @@ package _115
 import (
 	"github.com/SheltonZhu/115driver/pkg/driver"
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
 )
 
-var _ model.Obj = (*driver.File)(nil)
+// FileObj is the file of 115 with its sha1
+type FileObj struct {
+	driver.File
+}
+
+func (f FileObj) GetHash() utils.HashInfo {
+	return utils.NewHashInfo(utils.SHA1, f.Sha1)
+}
+
+var _ model.Obj = (*FileObj)(nil)
+var _ model.Hash = (*FileObj)(nil)
+
+// IndexInfoResp is the response of the space info of 115
+type IndexInfoResp struct {
+	State bool   `json:"state"`
+	Error string `json:"error"`
+	Data  struct {
+		SpaceInfo struct {
+			AllTotal struct {
+				Size float64 `json:"size"`
+			} `json:"all_total"`
+			AllUse struct {
+				Size float64 `json:"size"`
+			} `json:"all_use"`
+		} `json:"space_info"`
+	} `json:"data"`
+}
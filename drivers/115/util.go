@@ -36,6 +36,20 @@ func (d *Pan115) login() error {
 	return d.client.LoginCheck()
 }
 
+func (d *Pan115) getSpace() (*IndexInfoResp, error) {
+	var resp IndexInfoResp
+	_, err := d.client.NewRequest().
+		SetResult(&resp).
+		Get("https://webapi.115.com/files/index_info")
+	if err != nil {
+		return nil, err
+	}
+	if !resp.State {
+		return nil, errors.Errorf("failed to get space info: %s", resp.Error)
+	}
+	return &resp, nil
+}
+
 func (d *Pan115) getFiles(fileId string) ([]driver.File, error) {
 	res := make([]driver.File, 0)
 	files, err := d.client.List(fileId)
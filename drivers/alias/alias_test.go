@@ -0,0 +1,133 @@
+package alias
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/alist-org/alist/v3/drivers/local"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	conf.Conf = conf.DefaultConfig()
+	d, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	db.Init(d)
+}
+
+func writeFile(t *testing.T, path, content string, modified time.Time) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o777); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modified, modified); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAlias(t *testing.T) {
+	admin := &model.User{Username: "admin", Password: "admin", Role: model.ADMIN}
+	if err := db.CreateUser(admin); err != nil {
+		t.Fatalf("failed create admin: %+v", err)
+	}
+	ctx := context.WithValue(context.Background(), "meta", (*model.Meta)(nil))
+	ctx = context.WithValue(ctx, "user", admin)
+	now := time.Now()
+	a, b := t.TempDir(), t.TempDir()
+	writeFile(t, filepath.Join(a, "x.txt"), "a", now.Add(-time.Hour))
+	writeFile(t, filepath.Join(b, "x.txt"), "b", now)
+	writeFile(t, filepath.Join(a, "dir", "1.txt"), "1", now)
+	writeFile(t, filepath.Join(b, "dir", "2.txt"), "2", now)
+	for mountPath, root := range map[string]string{"/a": a, "/b": b} {
+		addition := `{"root_folder_path":"` + filepath.ToSlash(root) + `"}`
+		if _, err := op.CreateStorage(ctx, model.Storage{Driver: "Local", MountPath: mountPath, Addition: addition}); err != nil {
+			t.Fatalf("failed create local storage: %+v", err)
+		}
+	}
+	for _, policy := range []string{"first", "newest", "rename"} {
+		addition := `{"paths":"/a\n/b","conflict_policy":"` + policy + `","write_policy":"fixed","write_path":"/b"}`
+		if _, err := op.CreateStorage(ctx, model.Storage{Driver: "Alias", MountPath: "/" + policy, Addition: addition}); err != nil {
+			t.Fatalf("failed create alias storage: %+v", err)
+		}
+	}
+
+	names := func(path string) []string {
+		objs, err := fs.List(ctx, path)
+		if err != nil {
+			t.Fatalf("failed list %s: %+v", path, err)
+		}
+		var res []string
+		for _, obj := range objs {
+			res = append(res, obj.GetName())
+		}
+		sort.Strings(res)
+		return res
+	}
+	read := func(path string) string {
+		link, _, err := fs.Link(ctx, path, model.LinkArgs{})
+		if err != nil {
+			t.Fatalf("failed link %s: %+v", path, err)
+		}
+		rc, err := link.RangeReader(ctx, 0, -1)
+		if err != nil {
+			t.Fatalf("failed read %s: %+v", path, err)
+		}
+		defer rc.Close()
+		res, _ := io.ReadAll(rc)
+		return string(res)
+	}
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"/first", []string{"dir", "x.txt"}},
+		{"/first/dir", []string{"1.txt", "2.txt"}},
+		{"/rename", []string{"dir", "x (2).txt", "x.txt"}},
+	}
+	for _, tt := range tests {
+		if got := names(tt.path); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("unexpected names of %s: %v, want %v", tt.path, got, tt.want)
+		}
+	}
+	for path, want := range map[string]string{"/first/x.txt": "a", "/newest/x.txt": "b", "/rename/x (2).txt": "b", "/first/dir/2.txt": "2"} {
+		if got := read(path); got != want {
+			t.Errorf("unexpected content of %s: %s, want %s", path, got, want)
+		}
+	}
+
+	err := fs.PutDirectly(ctx, "/first/dir", &model.FileStream{
+		Obj:        &model.Object{Name: "new.txt", Size: 3, Modified: now},
+		ReadCloser: io.NopCloser(bytes.NewReader([]byte("new"))),
+	})
+	if err != nil {
+		t.Fatalf("failed put: %+v", err)
+	}
+	if _, err := os.Stat(filepath.Join(b, "dir", "new.txt")); err != nil {
+		t.Errorf("the file should be written to the fixed path: %+v", err)
+	}
+	if err := fs.Rename(ctx, "/first/dir", "renamed"); err != nil {
+		t.Fatalf("failed rename: %+v", err)
+	}
+	for _, root := range []string{a, b} {
+		if _, err := os.Stat(filepath.Join(root, "renamed")); err != nil {
+			t.Errorf("the folder should be renamed in all sources: %+v", err)
+		}
+	}
+}
@@ -0,0 +1,441 @@
+package alias
+
+import (
+	"context"
+	"fmt"
+	"io"
+	stdpath "path"
+	"strings"
+	"sync"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+type Alias struct {
+	model.Storage
+	Addition
+	sources []string
+}
+
+func (d *Alias) Config() driver.Config {
+	return config
+}
+
+func (d *Alias) GetAddition() driver.Additional {
+	return &d.Addition
+}
+
+func (d *Alias) Init(ctx context.Context) error {
+	mountPath := utils.StandardizePath(d.MountPath)
+	d.sources = nil
+	for _, p := range strings.Split(d.Paths, "\n") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		p = utils.StandardizePath(p)
+		if strings.HasPrefix(p+"/", mountPath+"/") || strings.HasPrefix(mountPath+"/", p+"/") {
+			return errors.Errorf("the path %s can't be in the mount path of alias, or contain it", p)
+		}
+		if !utils.SliceContains(d.sources, p) {
+			d.sources = append(d.sources, p)
+		}
+	}
+	if len(d.sources) == 0 {
+		return errors.New("paths is empty")
+	}
+	if d.WritePolicy == "fixed" {
+		d.WritePath = utils.StandardizePath(d.WritePath)
+		if !utils.SliceContains(d.sources, d.WritePath) {
+			return errors.New("the write path must be one of the paths")
+		}
+	}
+	return nil
+}
+
+func (d *Alias) Drop(ctx context.Context) error {
+	return nil
+}
+
+func (d *Alias) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
+	res, err := d.collect(func(source string) ([]model.Obj, error) {
+		return list(ctx, stdpath.Join(source, dir.GetPath()))
+	})
+	if err != nil {
+		return nil, err
+	}
+	var (
+		objs  []model.Obj
+		from  []int // the index of source of objs
+		index = make(map[string]int)
+	)
+	add := func(i int, obj model.Obj, name string) {
+		index[name] = len(objs)
+		objs = append(objs, d.convert(d.sources[i], dir.GetPath(), obj, name))
+		from = append(from, i)
+	}
+	rename := func(i int, obj model.Obj) {
+		ext := stdpath.Ext(obj.GetName())
+		name := fmt.Sprintf("%s (%d)%s", strings.TrimSuffix(obj.GetName(), ext), i+1, ext)
+		if _, ok := index[name]; !ok {
+			add(i, obj, name)
+		}
+	}
+	for i, sourceObjs := range res {
+		for _, obj := range sourceObjs {
+			name := obj.GetName()
+			j, ok := index[name]
+			if !ok {
+				add(i, obj, name)
+				continue
+			}
+			exist := objs[j]
+			switch {
+			case exist.IsDir() && obj.IsDir():
+				// the folders with the same name are always merged
+				if obj.ModTime().After(exist.ModTime()) {
+					exist.(*model.Object).Modified = obj.ModTime()
+				}
+			case exist.IsDir() || obj.IsDir():
+				// the folder wins if a file has the same name
+				file, fileFrom := obj, i
+				if obj.IsDir() {
+					file, fileFrom = exist, from[j]
+					objs[j], from[j] = d.convert(d.sources[i], dir.GetPath(), obj, name), i
+				}
+				if d.ConflictPolicy == "rename" {
+					rename(fileFrom, file)
+				}
+			case d.ConflictPolicy == "rename":
+				rename(i, obj)
+			case d.ConflictPolicy == "newest" && obj.ModTime().After(exist.ModTime()):
+				objs[j], from[j] = d.convert(d.sources[i], dir.GetPath(), obj, name), i
+			}
+		}
+	}
+	return objs, nil
+}
+
+func (d *Alias) Get(ctx context.Context, path string) (model.Obj, error) {
+	if utils.PathEqual(path, "/") {
+		return &model.Object{Path: "/", Name: "root", Modified: d.Modified, IsFolder: true}, nil
+	}
+	res, err := d.collect(func(source string) ([]model.Obj, error) {
+		obj, err := get(ctx, stdpath.Join(source, path))
+		if err != nil {
+			return nil, err
+		}
+		return []model.Obj{obj}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	var dir, file model.Obj
+	for i, objs := range res {
+		if len(objs) == 0 {
+			continue
+		}
+		obj := d.convert(d.sources[i], stdpath.Dir(path), objs[0], objs[0].GetName())
+		switch {
+		case obj.IsDir():
+			if dir == nil || obj.ModTime().After(dir.ModTime()) {
+				dir = obj
+			}
+		case file == nil || d.ConflictPolicy == "newest" && obj.ModTime().After(file.ModTime()):
+			file = obj
+		}
+	}
+	if dir != nil {
+		return dir, nil
+	}
+	return file, nil
+}
+
+func (d *Alias) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
+	storage, err := fs.GetStorage(file.GetPath())
+	if err != nil {
+		return nil, err
+	}
+	if storage.Config().MustProxy() || storage.GetStorage().WebProxy {
+		// the link of the source can't be redirected to, so read it by alist
+		rangeReader, _, err := fs.GetRangeReader(ctx, file.GetPath())
+		if err != nil {
+			return nil, err
+		}
+		return &model.Link{RangeReader: rangeReader}, nil
+	}
+	link, _, err := fs.Link(ctx, file.GetPath(), args)
+	return link, err
+}
+
+func (d *Alias) MakeDir(ctx context.Context, parentDir model.Obj, dirName string) error {
+	source, err := d.writeSource(ctx)
+	if err != nil {
+		return err
+	}
+	return fs.MakeDir(ctx, stdpath.Join(source, parentDir.GetPath(), dirName))
+}
+
+func (d *Alias) Move(ctx context.Context, srcObj, dstDir model.Obj) error {
+	return d.transfer(ctx, srcObj, dstDir, func(ctx context.Context, srcPath, dstDirPath string) error {
+		_, err := fs.Move(ctx, srcPath, dstDirPath)
+		return err
+	})
+}
+
+func (d *Alias) Rename(ctx context.Context, srcObj model.Obj, newName string) error {
+	return d.each(ctx, srcObj, func(path string) error {
+		return fs.Rename(ctx, path, newName)
+	})
+}
+
+func (d *Alias) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
+	return d.transfer(ctx, srcObj, dstDir, func(ctx context.Context, srcPath, dstDirPath string) error {
+		_, err := fs.Copy(ctx, srcPath, dstDirPath)
+		return err
+	})
+}
+
+func (d *Alias) Remove(ctx context.Context, obj model.Obj) error {
+	return d.each(ctx, obj, func(path string) error {
+		return fs.Remove(ctx, path)
+	})
+}
+
+func (d *Alias) Put(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, up driver.UpdateProgress) error {
+	source, err := d.writeSource(ctx)
+	if err != nil {
+		return err
+	}
+	// the stream is closed by op.Put of alias
+	err = fs.PutDirectly(ctx, stdpath.Join(source, dstDir.GetPath()), &model.FileStream{
+		Obj:        stream,
+		ReadCloser: io.NopCloser(stream),
+		Mimetype:   stream.GetMimetype(),
+	})
+	if err == nil {
+		up(100)
+	}
+	return err
+}
+
+// GetSpace sums the space of the storages of sources
+func (d *Alias) GetSpace(ctx context.Context) (int64, int64, error) {
+	var total, used int64
+	counted := make(map[string]bool)
+	for _, source := range d.sources {
+		storage, err := fs.GetStorage(source)
+		if err != nil || counted[storage.GetStorage().MountPath] {
+			continue
+		}
+		counted[storage.GetStorage().MountPath] = true
+		space, err := fs.GetSpace(ctx, source)
+		if err != nil {
+			continue
+		}
+		total += space.Total
+		used += space.Used
+	}
+	if len(counted) == 0 || total == 0 && used == 0 {
+		return 0, 0, errs.NotImplement
+	}
+	return total, used, nil
+}
+
+// get and list the objs of the sources by op, so that not found
+// in some of the sources is not logged as the error of fs
+func get(ctx context.Context, path string) (model.Obj, error) {
+	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	if err != nil {
+		return nil, err
+	}
+	return op.Get(ctx, storage, actualPath)
+}
+
+func list(ctx context.Context, path string) ([]model.Obj, error) {
+	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	if err != nil {
+		return nil, err
+	}
+	return op.List(ctx, storage, actualPath, model.ListArgs{ReqPath: path})
+}
+
+func isNotFound(err error) bool {
+	return errs.IsObjectNotFound(err) || errors.Is(err, errs.NotFolder)
+}
+
+// collect calls fn with the sources concurrently and returns the results in the order of sources,
+// the error is returned only if fn fails with all of them
+func (d *Alias) collect(fn func(source string) ([]model.Obj, error)) ([][]model.Obj, error) {
+	res := make([][]model.Obj, len(d.sources))
+	failures := make([]error, len(d.sources))
+	var wg sync.WaitGroup
+	for i, source := range d.sources {
+		wg.Add(1)
+		go func(i int, source string) {
+			defer wg.Done()
+			res[i], failures[i] = fn(source)
+		}(i, source)
+	}
+	wg.Wait()
+	var err error
+	succeeded := false
+	for i, e := range failures {
+		if e == nil {
+			succeeded = true
+			continue
+		}
+		if !isNotFound(e) {
+			log.Warnf("failed read source %s of alias %s: %+v", d.sources[i], d.MountPath, e)
+			if err == nil {
+				err = e
+			}
+		}
+	}
+	if succeeded {
+		return res, nil
+	}
+	if err == nil {
+		err = errors.WithStack(errs.ObjectNotFound)
+	}
+	return nil, err
+}
+
+// convert returns the obj of alias, the path of file is the actual path in the source
+// so that it can be linked, and the path of folder is the one in alias to be merged
+func (d *Alias) convert(source, dir string, obj model.Obj, name string) model.Obj {
+	path := stdpath.Join(dir, name)
+	if !obj.IsDir() {
+		path = stdpath.Join(source, dir, obj.GetName())
+	}
+	return &model.Object{
+		Path:     path,
+		Name:     name,
+		Size:     obj.GetSize(),
+		Modified: obj.ModTime(),
+		IsFolder: obj.IsDir(),
+	}
+}
+
+// sourceOf returns the source that the actual path of file is in
+func (d *Alias) sourceOf(path string) (string, error) {
+	res := ""
+	for _, source := range d.sources {
+		// the longest one if the sources are nested
+		if strings.HasPrefix(path, source+"/") && len(source) > len(res) {
+			res = source
+		}
+	}
+	if res == "" {
+		return "", errors.Errorf("%s is not in the sources of alias", path)
+	}
+	return res, nil
+}
+
+// dirSources returns the sources that have the folder
+func (d *Alias) dirSources(ctx context.Context, path string) ([]string, error) {
+	res, err := d.collect(func(source string) ([]model.Obj, error) {
+		obj, err := get(ctx, stdpath.Join(source, path))
+		if err != nil {
+			return nil, err
+		}
+		if !obj.IsDir() {
+			return nil, errors.WithStack(errs.NotFolder)
+		}
+		return []model.Obj{obj}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	var sources []string
+	for i, objs := range res {
+		if len(objs) > 0 {
+			sources = append(sources, d.sources[i])
+		}
+	}
+	return sources, nil
+}
+
+// each calls fn with the actual path of file, or the ones of folder in all sources that have it
+func (d *Alias) each(ctx context.Context, obj model.Obj, fn func(path string) error) error {
+	if !obj.IsDir() {
+		return fn(obj.GetPath())
+	}
+	sources, err := d.dirSources(ctx, obj.GetPath())
+	if err != nil {
+		return err
+	}
+	for _, source := range sources {
+		if err := fn(stdpath.Join(source, obj.GetPath())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// transfer moves or copies the obj to the dst dir in the same source, the dst dir
+// is created in the source if it doesn't exist
+func (d *Alias) transfer(ctx context.Context, srcObj, dstDir model.Obj, fn func(ctx context.Context, srcPath, dstDirPath string) error) error {
+	return d.each(ctx, srcObj, func(path string) error {
+		source, err := d.sourceOf(path)
+		if err != nil {
+			return err
+		}
+		dstDirPath := stdpath.Join(source, dstDir.GetPath())
+		if err := fs.MakeDir(ctx, dstDirPath); err != nil {
+			return err
+		}
+		return fn(ctx, path, dstDirPath)
+	})
+}
+
+// writeSource returns the source to write new files and folders by the write policy
+func (d *Alias) writeSource(ctx context.Context) (string, error) {
+	switch d.WritePolicy {
+	case "fixed":
+		return d.WritePath, nil
+	case "most_free_space":
+		best, free := "", int64(-1)
+		for _, source := range d.sources {
+			if !writable(source) {
+				continue
+			}
+			space, err := fs.GetSpace(ctx, source)
+			if err != nil {
+				continue
+			}
+			if space.Free() > free {
+				best, free = source, space.Free()
+			}
+		}
+		if best != "" {
+			return best, nil
+		}
+	}
+	// first_writable, or none of the sources reports the free space
+	for _, source := range d.sources {
+		if writable(source) {
+			return source, nil
+		}
+	}
+	return "", errors.WithStack(errs.UploadNotSupported)
+}
+
+func writable(source string) bool {
+	storage, err := fs.GetStorage(source)
+	if err != nil {
+		return false
+	}
+	return !storage.Config().NoUpload && storage.GetStorage().Status == op.WORK
+}
+
+var _ driver.Driver = (*Alias)(nil)
+var _ driver.Getter = (*Alias)(nil)
+var _ driver.Quota = (*Alias)(nil)
@@ -0,0 +1,26 @@
+package alias
+
+import (
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+type Addition struct {
+	Paths          string `json:"paths" type:"text" required:"true" help:"one source path per line, such as /115/media, the folders with the same name are merged"`
+	ConflictPolicy string `json:"conflict_policy" type:"select" options:"first,newest,rename" default:"first" help:"which file is shown and read if the names collide, rename shows all of them with the number of source"`
+	WritePolicy    string `json:"write_policy" type:"select" options:"first_writable,most_free_space,fixed" default:"first_writable"`
+	WritePath      string `json:"write_path" help:"the source path to write by the fixed policy"`
+}
+
+var config = driver.Config{
+	Name:        "Alias",
+	LocalSort:   true,
+	NoCache:     true,
+	DefaultRoot: "/",
+}
+
+func init() {
+	op.RegisterDriver(func() driver.Driver {
+		return &Alias{}
+	})
+}
@@ -309,4 +309,16 @@ func (d *AliDrive) Other(ctx context.Context, args model.OtherArgs) (interface{}
 	return resp, nil
 }
 
+func (d *AliDrive) GetSpace(ctx context.Context) (int64, int64, error) {
+	res, err, _ := d.request("https://api.aliyundrive.com/v2/databox/get_personal_info", http.MethodPost, func(req *resty.Request) {
+		req.SetBody(base.Json{})
+	}, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	info := utils.Json.Get(res, "personal_space_info")
+	return info.Get("total_size").ToInt64(), info.Get("used_size").ToInt64(), nil
+}
+
 var _ driver.Driver = (*AliDrive)(nil)
+var _ driver.Quota = (*AliDrive)(nil)
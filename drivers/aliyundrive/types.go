@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
 )
 
 type RespErr struct {
@@ -17,18 +18,20 @@ type Files struct {
 }
 
 type File struct {
-	DriveId       string     `json:"drive_id"`
-	CreatedAt     *time.Time `json:"created_at"`
-	FileExtension string     `json:"file_extension"`
-	FileId        string     `json:"file_id"`
-	Type          string     `json:"type"`
-	Name          string     `json:"name"`
-	Category      string     `json:"category"`
-	ParentFileId  string     `json:"parent_file_id"`
-	UpdatedAt     time.Time  `json:"updated_at"`
-	Size          int64      `json:"size"`
-	Thumbnail     string     `json:"thumbnail"`
-	Url           string     `json:"url"`
+	DriveId         string     `json:"drive_id"`
+	CreatedAt       *time.Time `json:"created_at"`
+	FileExtension   string     `json:"file_extension"`
+	FileId          string     `json:"file_id"`
+	Type            string     `json:"type"`
+	Name            string     `json:"name"`
+	Category        string     `json:"category"`
+	ParentFileId    string     `json:"parent_file_id"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	Size            int64      `json:"size"`
+	Thumbnail       string     `json:"thumbnail"`
+	Url             string     `json:"url"`
+	ContentHash     string     `json:"content_hash"`
+	ContentHashName string     `json:"content_hash_name"`
 }
 
 func fileToObj(f File) *model.ObjThumb {
@@ -39,11 +42,20 @@ func fileToObj(f File) *model.ObjThumb {
 			Size:     f.Size,
 			Modified: f.UpdatedAt,
 			IsFolder: f.Type == "folder",
+			HashInfo: getHashInfo(f),
 		},
 		Thumbnail: model.Thumbnail{Thumbnail: f.Thumbnail},
 	}
 }
 
+func getHashInfo(f File) utils.HashInfo {
+	ht, ok := utils.GetHashType(f.ContentHashName)
+	if !ok {
+		return utils.HashInfo{}
+	}
+	return utils.NewHashInfo(ht, f.ContentHash)
+}
+
 type UploadResp struct {
 	FileId       string `json:"file_id"`
 	UploadId     string `json:"upload_id"`
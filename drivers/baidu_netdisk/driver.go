@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"net/http"
 	"os"
 	stdpath "path"
 	"strconv"
@@ -17,6 +18,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/go-resty/resty/v2"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -225,4 +227,19 @@ func (d *BaiduNetdisk) Put(ctx context.Context, dstDir model.Obj, stream model.F
 	return err
 }
 
+func (d *BaiduNetdisk) GetSpace(ctx context.Context) (int64, int64, error) {
+	var resp QuotaResp
+	_, err := d.request("https://pan.baidu.com/api/quota", http.MethodGet, func(req *resty.Request) {
+		req.SetQueryParams(map[string]string{
+			"checkfree":   "1",
+			"checkexpire": "1",
+		})
+	}, &resp)
+	if err != nil {
+		return 0, 0, err
+	}
+	return resp.Total, resp.Used, nil
+}
+
 var _ driver.Driver = (*BaiduNetdisk)(nil)
+var _ driver.Quota = (*BaiduNetdisk)(nil)
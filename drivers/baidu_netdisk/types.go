@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
 )
 
 type TokenErrResp struct {
@@ -37,7 +38,7 @@ type File struct {
 	//Pl             int    `json:"pl"`
 	//LocalCtime     int    `json:"local_ctime"`
 	ServerFilename string `json:"server_filename"`
-	//Md5            string `json:"md5"`
+	Md5            string `json:"md5"`
 	//OwnerId        int    `json:"owner_id"`
 	//Unlist int `json:"unlist"`
 	Isdir int `json:"isdir"`
@@ -51,6 +52,7 @@ func fileToObj(f File) *model.ObjThumb {
 			Size:     f.Size,
 			Modified: time.Unix(f.ServerMtime, 0),
 			IsFolder: f.Isdir == 1,
+			HashInfo: utils.NewHashInfo(utils.MD5, decryptMd5(f.Md5)),
 		},
 		Thumbnail: model.Thumbnail{Thumbnail: f.Thumbs.Url3},
 	}
@@ -161,3 +163,9 @@ type PrecreateResp struct {
 	Errno      int    `json:"errno"`
 	RequestId  int64  `json:"request_id"`
 }
+
+type QuotaResp struct {
+	Errno int   `json:"errno"`
+	Total int64 `json:"total"`
+	Used  int64 `json:"used"`
+}
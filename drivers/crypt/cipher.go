@@ -0,0 +1,195 @@
+package crypt
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// The encrypted file is the header followed by the chunks,
+// the header is the magic and the random nonce of the file,
+// each chunk is sealed by XChaCha20-Poly1305 with the nonce of file xor the index of chunk,
+// the additional data marks the last chunk, so the truncated file can't be decrypted.
+const (
+	magic      = "ALCRYPT1"
+	headerSize = len(magic) + chacha20poly1305.NonceSizeX
+	chunkSize  = 64 * 1024
+	tagSize    = chacha20poly1305.Overhead
+	blockSize  = chunkSize + tagSize
+)
+
+var (
+	lastChunk  = []byte{1}
+	otherChunk = []byte{0}
+)
+
+// numChunks returns the number of chunks of the plaintext, the empty file has an empty chunk
+func numChunks(size int64) int64 {
+	n := (size + chunkSize - 1) / chunkSize
+	if n == 0 {
+		n = 1
+	}
+	return n
+}
+
+// encryptedSize returns the size of the encrypted file
+func encryptedSize(size int64) int64 {
+	return int64(headerSize) + size + numChunks(size)*tagSize
+}
+
+// decryptedSize returns the size of the plaintext of the encrypted file
+func decryptedSize(size int64) (int64, error) {
+	size -= int64(headerSize)
+	full, rem := size/blockSize, size%blockSize
+	if size < tagSize || (rem > 0 && rem < tagSize) || (rem == tagSize && full > 0) {
+		return 0, errors.New("invalid size of encrypted file")
+	}
+	if rem == 0 {
+		return full * chunkSize, nil
+	}
+	return full*chunkSize + rem - tagSize, nil
+}
+
+func chunkNonce(nonce []byte, index int64) []byte {
+	n := make([]byte, len(nonce))
+	copy(n, nonce)
+	var i [8]byte
+	binary.LittleEndian.PutUint64(i[:], uint64(index))
+	for j := range i {
+		n[len(n)-8+j] ^= i[j]
+	}
+	return n
+}
+
+// encrypter encrypts the plaintext read from src
+type encrypter struct {
+	aead  cipher.AEAD
+	src   io.Reader
+	nonce []byte
+	index int64
+	buf   []byte
+	// the first byte of the next chunk, which is read to know whether the current chunk is the last
+	peek []byte
+	out  []byte
+	done bool
+}
+
+func newEncrypter(aead cipher.AEAD, src io.Reader) (*encrypter, error) {
+	nonce := make([]byte, chacha20poly1305.NonceSizeX)
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	header := append([]byte(magic), nonce...)
+	return &encrypter{aead: aead, src: src, nonce: nonce, buf: make([]byte, chunkSize), out: header}, nil
+}
+
+func (e *encrypter) Read(p []byte) (int, error) {
+	for len(e.out) == 0 {
+		if e.done {
+			return 0, io.EOF
+		}
+		if err := e.seal(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, e.out)
+	e.out = e.out[n:]
+	return n, nil
+}
+
+// seal reads and encrypts the next chunk
+func (e *encrypter) seal() error {
+	n := copy(e.buf, e.peek)
+	e.peek = e.peek[:0]
+	m, err := io.ReadFull(e.src, e.buf[n:])
+	n += m
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return errors.WithStack(err)
+	}
+	last := n < chunkSize
+	if !last {
+		var b [1]byte
+		m, err := io.ReadFull(e.src, b[:])
+		if err != nil && err != io.EOF {
+			return errors.WithStack(err)
+		}
+		last = m == 0
+		e.peek = append(e.peek, b[:m]...)
+	}
+	ad := otherChunk
+	if last {
+		ad = lastChunk
+		e.done = true
+	}
+	e.out = e.aead.Seal(e.out[:0], chunkNonce(e.nonce, e.index), e.buf[:n], ad)
+	e.index++
+	return nil
+}
+
+// decrypter decrypts the chunks from the index read from src
+type decrypter struct {
+	aead  cipher.AEAD
+	src   io.ReadCloser
+	nonce []byte
+	index int64
+	// the index of the last chunk of the file
+	last int64
+	buf  []byte
+	out  []byte
+}
+
+func newDecrypter(aead cipher.AEAD, src io.ReadCloser, nonce []byte, index, last int64) *decrypter {
+	return &decrypter{aead: aead, src: src, nonce: nonce, index: index, last: last, buf: make([]byte, blockSize)}
+}
+
+func (d *decrypter) Read(p []byte) (int, error) {
+	for len(d.out) == 0 {
+		if d.index > d.last {
+			return 0, io.EOF
+		}
+		if err := d.open(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, d.out)
+	d.out = d.out[n:]
+	return n, nil
+}
+
+// open reads and decrypts the next chunk
+func (d *decrypter) open() error {
+	n, err := io.ReadFull(d.src, d.buf)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		if err == io.EOF {
+			return errors.WithStack(io.ErrUnexpectedEOF)
+		}
+		return errors.WithStack(err)
+	}
+	ad := otherChunk
+	if d.index == d.last {
+		ad = lastChunk
+	}
+	d.out, err = d.aead.Open(d.buf[:0], chunkNonce(d.nonce, d.index), d.buf[:n], ad)
+	if err != nil {
+		return errors.New("failed to decrypt the chunk, the file is corrupted or the password is wrong")
+	}
+	d.index++
+	return nil
+}
+
+func (d *decrypter) Close() error {
+	return d.src.Close()
+}
+
+// parseHeader returns the nonce in the header of the encrypted file
+func parseHeader(header []byte) ([]byte, error) {
+	if len(header) < headerSize || !bytes.Equal(header[:len(magic)], []byte(magic)) {
+		return nil, errors.New("not an encrypted file")
+	}
+	return header[len(magic):headerSize], nil
+}
@@ -0,0 +1,155 @@
+package crypt
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/alist-org/alist/v3/drivers/local"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils/random"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	conf.Conf = conf.DefaultConfig()
+	d, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	db.Init(d)
+}
+
+func TestCipher(t *testing.T) {
+	k, err := deriveKeys("password", "salt")
+	if err != nil {
+		t.Fatalf("failed derive keys: %+v", err)
+	}
+	for _, size := range []int{0, 1, chunkSize - 1, chunkSize, chunkSize + 1, 3*chunkSize + 5} {
+		plain := []byte(random.String(size))
+		enc, err := newEncrypter(k.data, bytes.NewReader(plain))
+		if err != nil {
+			t.Fatalf("failed create encrypter: %+v", err)
+		}
+		encrypted, err := io.ReadAll(enc)
+		if err != nil {
+			t.Fatalf("failed encrypt: %+v", err)
+		}
+		if int64(len(encrypted)) != encryptedSize(int64(size)) {
+			t.Fatalf("encrypted size of %d is %d, want %d", size, len(encrypted), encryptedSize(int64(size)))
+		}
+		if s, err := decryptedSize(int64(len(encrypted))); err != nil || s != int64(size) {
+			t.Fatalf("decrypted size of %d is %d: %+v", size, s, err)
+		}
+		nonce, err := parseHeader(encrypted)
+		if err != nil {
+			t.Fatalf("failed parse header: %+v", err)
+		}
+		last := numChunks(int64(size)) - 1
+		dec := newDecrypter(k.data, io.NopCloser(bytes.NewReader(encrypted[headerSize:])), nonce, 0, last)
+		if res, err := io.ReadAll(dec); err != nil || !bytes.Equal(res, plain) {
+			t.Fatalf("failed decrypt file of size %d: %+v", size, err)
+		}
+		// the file truncated at the boundary of chunks can't be decrypted
+		if last > 0 {
+			truncated := encrypted[:headerSize+blockSize]
+			dec := newDecrypter(k.data, io.NopCloser(bytes.NewReader(truncated[headerSize:])), nonce, 0, 0)
+			if _, err := io.ReadAll(dec); err == nil {
+				t.Fatalf("the truncated file of size %d shouldn't be decrypted", size)
+			}
+		}
+	}
+}
+
+func TestName(t *testing.T) {
+	k, err := deriveKeys("password", "salt")
+	if err != nil {
+		t.Fatalf("failed derive keys: %+v", err)
+	}
+	encrypted, err := k.encryptName("文件 name.txt")
+	if err != nil {
+		t.Fatalf("failed encrypt name: %+v", err)
+	}
+	if again, _ := k.encryptName("文件 name.txt"); again != encrypted {
+		t.Fatalf("the name should be encrypted deterministically")
+	}
+	if name, err := k.decryptName(encrypted); err != nil || name != "文件 name.txt" {
+		t.Fatalf("failed decrypt name: %s %+v", name, err)
+	}
+	other, _ := deriveKeys("other", "salt")
+	if _, err := other.decryptName(encrypted); err == nil {
+		t.Fatalf("the name shouldn't be decrypted by other password")
+	}
+}
+
+func TestCrypt(t *testing.T) {
+	admin := &model.User{Username: "admin", Password: "admin", Role: model.ADMIN}
+	if err := db.CreateUser(admin); err != nil {
+		t.Fatalf("failed create admin: %+v", err)
+	}
+	ctx := context.WithValue(context.Background(), "meta", (*model.Meta)(nil))
+	root := t.TempDir()
+	if _, err := op.CreateStorage(ctx, model.Storage{Driver: "Local", MountPath: "/plain", Addition: `{"root_folder_path":"` + filepath.ToSlash(root) + `"}`}); err != nil {
+		t.Fatalf("failed create local storage: %+v", err)
+	}
+	addition := `{"remote_path":"/plain","password":"password","file_name_encryption":true,"dir_name_encryption":true}`
+	if _, err := op.CreateStorage(ctx, model.Storage{Driver: "Crypt", MountPath: "/secret", Addition: addition}); err != nil {
+		t.Fatalf("failed create crypt storage: %+v", err)
+	}
+	if err := fs.MakeDir(ctx, "/secret/dir"); err != nil {
+		t.Fatalf("failed mkdir: %+v", err)
+	}
+	content := []byte(random.String(2*chunkSize + 100))
+	err := fs.PutDirectly(ctx, "/secret/dir", &model.FileStream{
+		Obj:        &model.Object{Name: "file.txt", Size: int64(len(content)), Modified: time.Now()},
+		ReadCloser: io.NopCloser(bytes.NewReader(content)),
+	})
+	if err != nil {
+		t.Fatalf("failed put: %+v", err)
+	}
+
+	entries, _ := os.ReadDir(root)
+	if len(entries) != 1 || entries[0].Name() == "dir" {
+		t.Fatalf("the dir name should be encrypted: %+v", entries)
+	}
+	files, err := fs.List(context.WithValue(ctx, "user", admin), "/secret/dir")
+	if err != nil || len(files) != 1 || files[0].GetName() != "file.txt" || files[0].GetSize() != int64(len(content)) {
+		t.Fatalf("unexpected files: %+v %+v", files, err)
+	}
+
+	link, _, err := fs.Link(ctx, "/secret/dir/file.txt", model.LinkArgs{})
+	if err != nil {
+		t.Fatalf("failed link: %+v", err)
+	}
+	for _, r := range [][2]int64{{0, -1}, {10, 20}, {chunkSize - 5, 10}, {chunkSize + 3, chunkSize + 50}, {int64(len(content)) - 1, 10}} {
+		rc, err := link.RangeReader(ctx, r[0], r[1])
+		if err != nil {
+			t.Fatalf("failed read range %v: %+v", r, err)
+		}
+		res, err := io.ReadAll(rc)
+		_ = rc.Close()
+		end := int64(len(content))
+		if r[1] >= 0 && r[0]+r[1] < end {
+			end = r[0] + r[1]
+		}
+		if err != nil || !bytes.Equal(res, content[r[0]:end]) {
+			t.Fatalf("unexpected content of range %v: %+v", r, err)
+		}
+	}
+
+	if err := fs.Rename(ctx, "/secret/dir/file.txt", "renamed.txt"); err != nil {
+		t.Fatalf("failed rename: %+v", err)
+	}
+	if _, err := fs.Get(ctx, "/secret/dir/renamed.txt"); err != nil {
+		t.Fatalf("failed get renamed file: %+v", err)
+	}
+}
@@ -0,0 +1,306 @@
+package crypt
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	stdpath "path"
+	"strings"
+	"sync"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+type Crypt struct {
+	model.Storage
+	Addition
+	keys *keys
+}
+
+func (d *Crypt) Config() driver.Config {
+	return config
+}
+
+func (d *Crypt) GetAddition() driver.Additional {
+	return &d.Addition
+}
+
+func (d *Crypt) Init(ctx context.Context) error {
+	d.RemotePath = utils.StandardizePath(d.RemotePath)
+	mountPath := utils.StandardizePath(d.MountPath)
+	if strings.HasPrefix(d.RemotePath+"/", mountPath+"/") || strings.HasPrefix(mountPath+"/", d.RemotePath+"/") {
+		return errors.New("the remote path can't be in the mount path of crypt, or contain it")
+	}
+	if d.Salt == "" {
+		salt := make([]byte, 16)
+		if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+			return errors.WithStack(err)
+		}
+		d.Salt = base64.RawStdEncoding.EncodeToString(salt)
+		op.MustSaveDriverStorage(d)
+	}
+	keys, err := deriveKeys(d.Password, d.Salt)
+	if err != nil {
+		return err
+	}
+	d.keys = keys
+	return nil
+}
+
+func (d *Crypt) Drop(ctx context.Context) error {
+	return nil
+}
+
+func (d *Crypt) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
+	ctx, err := listCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	objs, err := fs.List(ctx, dir.GetPath())
+	if err != nil {
+		return nil, err
+	}
+	res := make([]model.Obj, 0, len(objs))
+	for _, obj := range objs {
+		file, err := d.convert(dir.GetPath(), obj)
+		if err != nil {
+			// the files which are not encrypted by crypt are skipped
+			log.Debugf("skip %s in crypt: %+v", stdpath.Join(dir.GetPath(), obj.GetName()), err)
+			continue
+		}
+		res = append(res, file)
+	}
+	return res, nil
+}
+
+func (d *Crypt) Get(ctx context.Context, path string) (model.Obj, error) {
+	if utils.PathEqual(path, "/") {
+		obj, err := fs.Get(ctx, d.RemotePath)
+		if err != nil {
+			return nil, err
+		}
+		return &model.Object{Path: d.RemotePath, Name: "root", Modified: obj.ModTime(), IsFolder: true}, nil
+	}
+	dir, name := stdpath.Split(path)
+	remoteDir := d.RemotePath
+	for _, p := range strings.Split(strings.Trim(dir, "/"), "/") {
+		if p == "" {
+			continue
+		}
+		encrypted, err := d.encryptName(p, true)
+		if err != nil {
+			return nil, err
+		}
+		remoteDir = stdpath.Join(remoteDir, encrypted)
+	}
+	// the name of the file and folder may be encrypted differently
+	tried := make(map[string]bool, 2)
+	for _, isDir := range []bool{false, true} {
+		encrypted, err := d.encryptName(name, isDir)
+		if err != nil || tried[encrypted] {
+			continue
+		}
+		tried[encrypted] = true
+		obj, err := fs.Get(ctx, stdpath.Join(remoteDir, encrypted))
+		if err != nil || (obj.IsDir() != isDir && d.FileNameEncryption != d.DirNameEncryption) {
+			continue
+		}
+		return d.convert(remoteDir, obj)
+	}
+	return nil, errors.WithStack(errs.ObjectNotFound)
+}
+
+func (d *Crypt) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
+	remote, obj, err := fs.GetRangeReader(ctx, file.GetPath())
+	if err != nil {
+		return nil, err
+	}
+	size, err := decryptedSize(obj.GetSize())
+	if err != nil {
+		return nil, err
+	}
+	var (
+		mu    sync.Mutex
+		nonce []byte
+	)
+	// readNonce reads the nonce in the header once for the link
+	readNonce := func(ctx context.Context) ([]byte, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if nonce != nil {
+			return nonce, nil
+		}
+		rc, err := remote(ctx, 0, int64(headerSize))
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		header := make([]byte, headerSize)
+		if _, err := io.ReadFull(rc, header); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		nonce, err = parseHeader(header)
+		return nonce, err
+	}
+	return &model.Link{
+		RangeReader: func(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+			if offset < 0 || offset > size {
+				return nil, errors.Errorf("invalid offset %d of file with size %d", offset, size)
+			}
+			if length < 0 || offset+length > size {
+				length = size - offset
+			}
+			if length == 0 {
+				return io.NopCloser(strings.NewReader("")), nil
+			}
+			first, last := offset/chunkSize, (offset+length-1)/chunkSize
+			start := int64(headerSize) + first*blockSize
+			end := int64(headerSize) + (last+1)*blockSize
+			if encrypted := obj.GetSize(); end > encrypted {
+				end = encrypted
+			}
+			var fileNonce []byte
+			var err error
+			if first == 0 {
+				// read the header with the chunks
+				start = 0
+			} else if fileNonce, err = readNonce(ctx); err != nil {
+				return nil, err
+			}
+			rc, err := remote(ctx, start, end-start)
+			if err != nil {
+				return nil, err
+			}
+			if first == 0 {
+				header := make([]byte, headerSize)
+				if _, err := io.ReadFull(rc, header); err != nil {
+					_ = rc.Close()
+					return nil, errors.WithStack(err)
+				}
+				if fileNonce, err = parseHeader(header); err != nil {
+					_ = rc.Close()
+					return nil, err
+				}
+			}
+			dec := newDecrypter(d.keys.data, rc, fileNonce, first, numChunks(size)-1)
+			if _, err := io.CopyN(io.Discard, dec, offset-first*chunkSize); err != nil {
+				_ = dec.Close()
+				return nil, err
+			}
+			return utils.ReadCloser{Reader: io.LimitReader(dec, length), Closer: dec}, nil
+		},
+	}, nil
+}
+
+func (d *Crypt) MakeDir(ctx context.Context, parentDir model.Obj, dirName string) error {
+	name, err := d.encryptName(dirName, true)
+	if err != nil {
+		return err
+	}
+	return fs.MakeDir(ctx, stdpath.Join(parentDir.GetPath(), name))
+}
+
+func (d *Crypt) Move(ctx context.Context, srcObj, dstDir model.Obj) error {
+	_, err := fs.Move(ctx, srcObj.GetPath(), dstDir.GetPath())
+	return err
+}
+
+func (d *Crypt) Rename(ctx context.Context, srcObj model.Obj, newName string) error {
+	name, err := d.encryptName(newName, srcObj.IsDir())
+	if err != nil {
+		return err
+	}
+	return fs.Rename(ctx, srcObj.GetPath(), name)
+}
+
+func (d *Crypt) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
+	_, err := fs.Copy(ctx, srcObj.GetPath(), dstDir.GetPath())
+	return err
+}
+
+func (d *Crypt) Remove(ctx context.Context, obj model.Obj) error {
+	return fs.Remove(ctx, obj.GetPath())
+}
+
+func (d *Crypt) Put(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, up driver.UpdateProgress) error {
+	name, err := d.encryptName(stream.GetName(), false)
+	if err != nil {
+		return err
+	}
+	enc, err := newEncrypter(d.keys.data, stream)
+	if err != nil {
+		return err
+	}
+	// the stream is closed by op.Put of crypt
+	err = fs.PutDirectly(ctx, dstDir.GetPath(), &model.FileStream{
+		Obj: &model.Object{
+			Name:     name,
+			Size:     encryptedSize(stream.GetSize()),
+			Modified: stream.ModTime(),
+		},
+		ReadCloser: io.NopCloser(enc),
+		Mimetype:   "application/octet-stream",
+	})
+	if err == nil {
+		up(100)
+	}
+	return err
+}
+
+// listCtx returns the context to list the remote path, the meta of remote path doesn't apply,
+// and the admin lists it if there is no user in ctx, such as checking the health
+func listCtx(ctx context.Context) (context.Context, error) {
+	if user, ok := ctx.Value("user").(*model.User); !ok || user == nil {
+		admin, err := db.GetAdmin()
+		if err != nil {
+			return nil, err
+		}
+		ctx = context.WithValue(ctx, "user", admin)
+	}
+	return context.WithValue(ctx, "meta", (*model.Meta)(nil)), nil
+}
+
+// encryptName returns the name in the remote storage
+func (d *Crypt) encryptName(name string, isDir bool) (string, error) {
+	if isDir && !d.DirNameEncryption || !isDir && !d.FileNameEncryption {
+		return name, nil
+	}
+	return d.keys.encryptName(name)
+}
+
+// convert returns the decrypted obj of the obj in the remote dir
+func (d *Crypt) convert(remoteDir string, obj model.Obj) (model.Obj, error) {
+	name := obj.GetName()
+	if obj.IsDir() && d.DirNameEncryption || !obj.IsDir() && d.FileNameEncryption {
+		var err error
+		if name, err = d.keys.decryptName(name); err != nil {
+			return nil, err
+		}
+	}
+	size := obj.GetSize()
+	if !obj.IsDir() {
+		var err error
+		if size, err = decryptedSize(size); err != nil {
+			return nil, err
+		}
+	}
+	return &model.Object{
+		Path:     stdpath.Join(remoteDir, obj.GetName()),
+		Name:     name,
+		Size:     size,
+		Modified: obj.ModTime(),
+		IsFolder: obj.IsDir(),
+	}, nil
+}
+
+var _ driver.Driver = (*Crypt)(nil)
+var _ driver.Getter = (*Crypt)(nil)
@@ -0,0 +1,28 @@
+package crypt
+
+import (
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+type Addition struct {
+	RemotePath         string `json:"remote_path" required:"true" help:"the mount path of the storage where the encrypted files are stored"`
+	Password           string `json:"password" required:"true"`
+	Salt               string `json:"salt" help:"generated randomly if it's empty, the password and salt are both required to decrypt the files"`
+	FileNameEncryption bool   `json:"file_name_encryption" default:"true"`
+	DirNameEncryption  bool   `json:"dir_name_encryption" default:"true"`
+}
+
+var config = driver.Config{
+	Name:        "Crypt",
+	LocalSort:   true,
+	OnlyProxy:   true,
+	NoCache:     true,
+	DefaultRoot: "/",
+}
+
+func init() {
+	op.RegisterDriver(func() driver.Driver {
+		return &Crypt{}
+	})
+}
@@ -0,0 +1,79 @@
+package crypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base32"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	ivSize = 16
+	// the max length of the encrypted name is 255
+	maxNameSize = 255*5/8 - ivSize
+)
+
+// the encrypted names are case-insensitive, because some storages are
+var nameEncoding = base32.HexEncoding.WithPadding(base32.NoPadding)
+
+type keys struct {
+	data    cipher.AEAD
+	name    cipher.Block
+	nameMac []byte
+}
+
+// deriveKeys derives the keys of the data and names from the password and salt
+func deriveKeys(password, salt string) (*keys, error) {
+	key, err := scrypt.Key([]byte(password), []byte(salt), 1<<15, 8, 1, 96)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	data, err := chacha20poly1305.NewX(key[:32])
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	name, err := aes.NewCipher(key[32:64])
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &keys{data: data, name: name, nameMac: key[64:]}, nil
+}
+
+// encryptName encrypts the name deterministically, so the file can be found by its name.
+// The iv is the mac of the name, which authenticates the name when decrypting.
+func (k *keys) encryptName(name string) (string, error) {
+	if len(name) > maxNameSize {
+		return "", errors.Errorf("the name is longer than %d bytes: %s", maxNameSize, name)
+	}
+	iv := k.mac(name)
+	buf := make([]byte, ivSize+len(name))
+	copy(buf, iv)
+	cipher.NewCTR(k.name, iv).XORKeyStream(buf[ivSize:], []byte(name))
+	return strings.ToLower(nameEncoding.EncodeToString(buf)), nil
+}
+
+func (k *keys) decryptName(name string) (string, error) {
+	buf, err := nameEncoding.DecodeString(strings.ToUpper(name))
+	if err != nil || len(buf) < ivSize {
+		return "", errors.Errorf("invalid encrypted name: %s", name)
+	}
+	iv := buf[:ivSize]
+	plain := make([]byte, len(buf)-ivSize)
+	cipher.NewCTR(k.name, iv).XORKeyStream(plain, buf[ivSize:])
+	if !hmac.Equal(iv, k.mac(string(plain))) {
+		return "", errors.Errorf("failed to decrypt name: %s", name)
+	}
+	return string(plain), nil
+}
+
+func (k *keys) mac(name string) []byte {
+	h := hmac.New(sha256.New, k.nameMac)
+	h.Write([]byte(name))
+	return h.Sum(nil)[:ivSize]
+}
@@ -2,11 +2,13 @@ package ftp
 
 import (
 	"context"
+	"io"
 	stdpath "path"
 
 	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/jlaffaye/ftp"
 )
 
@@ -69,12 +71,20 @@ func (d *FTP) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*m
 	if err := d.login(); err != nil {
 		return nil, err
 	}
-	resp, err := d.conn.Retr(file.GetPath())
-	if err != nil {
-		return nil, err
-	}
 	return &model.Link{
-		Data: resp,
+		RangeReader: func(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+			if err := d.login(); err != nil {
+				return nil, err
+			}
+			resp, err := d.conn.RetrFrom(file.GetPath(), uint64(offset))
+			if err != nil {
+				return nil, err
+			}
+			if length < 0 {
+				return resp, nil
+			}
+			return utils.ReadCloser{Reader: io.LimitReader(resp, length), Closer: resp}, nil
+		},
 	}, nil
 }
 
@@ -172,4 +172,19 @@ func (d *GoogleDrive) Put(ctx context.Context, dstDir model.Obj, stream model.Fi
 	return err
 }
 
+func (d *GoogleDrive) GetSpace(ctx context.Context) (int64, int64, error) {
+	var resp About
+	_, err := d.request("https://www.googleapis.com/drive/v3/about", http.MethodGet, func(req *resty.Request) {
+		req.SetQueryParam("fields", "storageQuota")
+	}, &resp)
+	if err != nil {
+		return 0, 0, err
+	}
+	// the limit is empty if the storage is unlimited
+	total, _ := strconv.ParseInt(resp.StorageQuota.Limit, 10, 64)
+	used, _ := strconv.ParseInt(resp.StorageQuota.Usage, 10, 64)
+	return total, used, nil
+}
+
 var _ driver.Driver = (*GoogleDrive)(nil)
+var _ driver.Quota = (*GoogleDrive)(nil)
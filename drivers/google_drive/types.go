@@ -64,3 +64,10 @@ type Error struct {
 		Message string `json:"message"`
 	} `json:"error"`
 }
+
+type About struct {
+	StorageQuota struct {
+		Limit string `json:"limit"`
+		Usage string `json:"usage"`
+	} `json:"storageQuota"`
+}
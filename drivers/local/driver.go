@@ -224,4 +224,13 @@ func (d *Local) Put(ctx context.Context, dstDir model.Obj, stream model.FileStre
 	return nil
 }
 
+func (d *Local) GetSpace(ctx context.Context) (int64, int64, error) {
+	total, free, err := getSpace(d.GetRootPath())
+	if err != nil {
+		return 0, 0, err
+	}
+	return total, total - free, nil
+}
+
 var _ driver.Driver = (*Local)(nil)
+var _ driver.Quota = (*Local)(nil)
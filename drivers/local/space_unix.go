@@ -0,0 +1,15 @@
+//go:build !windows
+
+package local
+
+import "syscall"
+
+// getSpace get the total and free bytes of the filesystem the path is on
+func getSpace(path string) (total, free int64, err error) {
+	var stat syscall.Statfs_t
+	if err = syscall.Statfs(path, &stat); err != nil {
+		return 0, 0, err
+	}
+	bsize := int64(stat.Bsize)
+	return int64(stat.Blocks) * bsize, int64(stat.Bavail) * bsize, nil
+}
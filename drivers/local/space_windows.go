@@ -0,0 +1,18 @@
+//go:build windows
+
+package local
+
+import "golang.org/x/sys/windows"
+
+// getSpace get the total and free bytes of the volume the path is on
+func getSpace(path string) (total, free int64, err error) {
+	p, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	var avail, all, allFree uint64
+	if err = windows.GetDiskFreeSpaceEx(p, &avail, &all, &allFree); err != nil {
+		return 0, 0, err
+	}
+	return int64(all), int64(avail), nil
+}
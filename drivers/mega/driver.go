@@ -9,7 +9,6 @@ import (
 	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
-	"github.com/alist-org/alist/v3/pkg/chanio"
 	log "github.com/sirupsen/logrus"
 	"github.com/t3rm1n4l/go-mega"
 )
@@ -79,33 +78,19 @@ func (d *Mega) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*
 		//u := down.GetResourceUrl()
 		//u = strings.Replace(u, "http", "https", 1)
 		//return &model.Link{URL: u}, nil
-		c := chanio.New()
-		go func() {
-			defer func() {
-				_ = recover()
-			}()
-			log.Debugf("chunk size: %d", down.Chunks())
-			for id := 0; id < down.Chunks(); id++ {
-				chunk, err := down.DownloadChunk(id)
-				if err != nil {
-					log.Errorf("mega down: %+v", err)
-					return
+		size := file.GetSize()
+		return &model.Link{
+			RangeReader: func(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+				if length < 0 || offset+length > size {
+					length = size - offset
 				}
-				log.Debugf("id: %d,len: %d", id, len(chunk))
-				//_, _, err = down.ChunkLocation(id)
-				//if err != nil {
-				//	log.Errorf("mega down: %+v", err)
-				//	return
-				//}
-				//_, err = c.Write(chunk)
-				_, err = c.Write(chunk)
-			}
-			err := c.Close()
-			if err != nil {
-				log.Errorf("mega down: %+v", err)
-			}
-		}()
-		return &model.Link{Data: c}, nil
+				pr, pw := io.Pipe()
+				go func() {
+					pw.CloseWithError(downloadRange(down, pw, offset, offset+length))
+				}()
+				return pr, nil
+			},
+		}, nil
 	}
 	return nil, fmt.Errorf("unable to convert dir to mega node")
 }
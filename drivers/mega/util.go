@@ -1,3 +1,43 @@
 package mega
 
+import (
+	"io"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/t3rm1n4l/go-mega"
+)
+
 // do others that not defined in Driver interface
+
+// downloadRange writes the bytes in [start, end) of the download to w,
+// only the chunks in the range are downloaded.
+func downloadRange(down *mega.Download, w io.Writer, start, end int64) error {
+	for id := 0; id < down.Chunks(); id++ {
+		pos, size, err := down.ChunkLocation(id)
+		if err != nil {
+			return err
+		}
+		if pos+int64(size) <= start {
+			continue
+		}
+		if pos >= end {
+			break
+		}
+		chunk, err := down.DownloadChunk(id)
+		if err != nil {
+			log.Errorf("mega down: %+v", err)
+			return err
+		}
+		lo, hi := int64(0), int64(len(chunk))
+		if start > pos {
+			lo = start - pos
+		}
+		if end-pos < hi {
+			hi = end - pos
+		}
+		if _, err = w.Write(chunk[lo:hi]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -142,4 +142,14 @@ func (d *Onedrive) Put(ctx context.Context, dstDir model.Obj, stream model.FileS
 	return err
 }
 
+func (d *Onedrive) GetSpace(ctx context.Context) (int64, int64, error) {
+	var resp Drive
+	_, err := d.Request(d.GetDriveUrl()+"?$select=quota", http.MethodGet, nil, &resp)
+	if err != nil {
+		return 0, 0, err
+	}
+	return resp.Quota.Total, resp.Quota.Used, nil
+}
+
 var _ driver.Driver = (*Onedrive)(nil)
+var _ driver.Quota = (*Onedrive)(nil)
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
 )
 
 type Host struct {
@@ -31,6 +32,11 @@ type File struct {
 	Url                  string    `json:"@microsoft.graph.downloadUrl"`
 	File                 *struct {
 		MimeType string `json:"mimeType"`
+		Hashes   struct {
+			QuickXorHash string `json:"quickXorHash"`
+			Sha1Hash     string `json:"sha1Hash"`
+			Sha256Hash   string `json:"sha256Hash"`
+		} `json:"hashes"`
 	} `json:"file"`
 	Thumbnails []struct {
 		Medium struct {
@@ -42,11 +48,20 @@ type File struct {
 	} `json:"parentReference"`
 }
 
+// QuickXorHash is the hash of onedrive for business, it's base64 encoded
+var QuickXorHash = utils.RegisterHash("quickxor", 28, nil)
+
 func fileToObj(f File) *model.ObjThumbURL {
 	thumb := ""
 	if len(f.Thumbnails) > 0 {
 		thumb = f.Thumbnails[0].Medium.Url
 	}
+	var hashInfo utils.HashInfo
+	if f.File != nil {
+		hashInfo = utils.NewHashInfo(QuickXorHash, f.File.Hashes.QuickXorHash).
+			With(utils.SHA1, f.File.Hashes.Sha1Hash).
+			With(utils.SHA256, f.File.Hashes.Sha256Hash)
+	}
 	return &model.ObjThumbURL{
 		Object: model.Object{
 			ID:       f.Id,
@@ -54,6 +69,7 @@ func fileToObj(f File) *model.ObjThumbURL {
 			Size:     f.Size,
 			Modified: f.LastModifiedDateTime,
 			IsFolder: f.File == nil,
+			HashInfo: hashInfo,
 		},
 		Thumbnail: model.Thumbnail{Thumbnail: thumb},
 		Url:       model.Url{Url: f.Url},
@@ -64,3 +80,11 @@ type Files struct {
 	Value    []File `json:"value"`
 	NextLink string `json:"@odata.nextLink"`
 }
+
+type Drive struct {
+	Quota struct {
+		Total     int64 `json:"total"`
+		Used      int64 `json:"used"`
+		Remaining int64 `json:"remaining"`
+	} `json:"quota"`
+}
@@ -61,6 +61,15 @@ func (d *Onedrive) GetMetaUrl(auth bool, path string) string {
 	}
 }
 
+// GetDriveUrl get the url of the drive, which has the quota
+func (d *Onedrive) GetDriveUrl() string {
+	host, _ := onedriveHostMap[d.Region]
+	if d.IsSharepoint {
+		return fmt.Sprintf("%s/v1.0/sites/%s/drive", host.Api, d.SiteId)
+	}
+	return fmt.Sprintf("%s/v1.0/me/drive", host.Api)
+}
+
 func (d *Onedrive) refreshToken() error {
 	var err error
 	for i := 0; i < 3; i++ {
@@ -9,6 +9,7 @@ import (
 
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/request"
@@ -97,6 +98,7 @@ func (d *S3) listV1(prefix string) ([]model.Obj, error) {
 				Name:     name,
 				Size:     *object.Size,
 				Modified: *object.LastModified,
+				HashInfo: etagToHashInfo(object.ETag),
 			}
 			files = append(files, &file)
 		}
@@ -149,6 +151,7 @@ func (d *S3) listV2(prefix string) ([]model.Obj, error) {
 				Name:     name,
 				Size:     *object.Size,
 				Modified: *object.LastModified,
+				HashInfo: etagToHashInfo(object.ETag),
 			}
 			files = append(files, &file)
 		}
@@ -236,3 +239,13 @@ func (d *S3) removeFile(src string) error {
 	_, err := d.client.DeleteObject(input)
 	return err
 }
+
+// etagToHashInfo returns the md5 of the object from its etag,
+// the etag of multipart uploaded object isn't md5, which contains "-".
+func etagToHashInfo(etag *string) utils.HashInfo {
+	e := strings.Trim(aws.StringValue(etag), `"`)
+	if strings.Contains(e, "-") {
+		return utils.HashInfo{}
+	}
+	return utils.NewHashInfo(utils.MD5, e)
+}
@@ -94,4 +94,14 @@ func (d *SFTP) Put(ctx context.Context, dstDir model.Obj, stream model.FileStrea
 	return err
 }
 
+func (d *SFTP) GetSpace(ctx context.Context) (int64, int64, error) {
+	stat, err := d.client.StatVFS(d.GetRootPath())
+	if err != nil {
+		return 0, 0, err
+	}
+	total := int64(stat.TotalSpace())
+	return total, total - int64(stat.Frsize*stat.Bavail), nil
+}
+
 var _ driver.Driver = (*SFTP)(nil)
+var _ driver.Quota = (*SFTP)(nil)
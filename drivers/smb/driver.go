@@ -197,8 +197,24 @@ func (d *SMB) Put(ctx context.Context, dstDir model.Obj, stream model.FileStream
 	return nil
 }
 
+func (d *SMB) GetSpace(ctx context.Context) (int64, int64, error) {
+	if err := d.checkConn(); err != nil {
+		return 0, 0, err
+	}
+	info, err := d.fs.Statfs(d.GetRootPath())
+	if err != nil {
+		d.cleanLastConnTime()
+		return 0, 0, err
+	}
+	d.updateLastConnTime()
+	unit := int64(info.BlockSize() * info.FragmentSize())
+	total := int64(info.TotalBlockCount()) * unit
+	return total, total - int64(info.AvailableBlockCount())*unit, nil
+}
+
 //func (d *SMB) Other(ctx context.Context, args model.OtherArgs) (interface{}, error) {
 //	return nil, errs.NotSupport
 //}
 
 var _ driver.Driver = (*SMB)(nil)
+var _ driver.Quota = (*SMB)(nil)
@@ -54,7 +54,12 @@ func (d *Virtual) List(ctx context.Context, dir model.Obj, args model.ListArgs)
 
 func (d *Virtual) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
 	return &model.Link{
-		Data: io.NopCloser(io.LimitReader(random.Rand, file.GetSize())),
+		RangeReader: func(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+			if length < 0 || offset+length > file.GetSize() {
+				length = file.GetSize() - offset
+			}
+			return io.NopCloser(io.LimitReader(random.Rand, length)), nil
+		},
 	}, nil
 }
 
@@ -82,10 +82,7 @@ func (m *Monitor) Update() (bool, error) {
 		log.Debugf("followen by: %+v", info.FollowedBy)
 		gid := info.FollowedBy[0]
 		notify.Signals.Delete(m.tsk.ID)
-		oldId := m.tsk.ID
-		m.tsk.ID = gid
-		DownTaskManager.RawTasks().Delete(oldId)
-		DownTaskManager.RawTasks().Store(m.tsk.ID, m.tsk)
+		DownTaskManager.ChangeID(m.tsk.ID, gid)
 		notify.Signals.Store(gid, m.c)
 		return false, nil
 	}
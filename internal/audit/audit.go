@@ -0,0 +1,96 @@
+// Package audit records the operations which change the files or the settings.
+// The user, client ip and the entrance of an operation are taken from the values
+// "user", "ip" and "via" of the context.
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/setting"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	FsMkdir  = "fs.mkdir"
+	FsMove   = "fs.move"
+	FsCopy   = "fs.copy"
+	FsRename = "fs.rename"
+	FsRemove = "fs.remove"
+	FsPut    = "fs.put"
+
+	WebdavProppatch = "webdav.proppatch"
+
+	Login = "auth.login"
+
+	StorageCreate  = "admin.storage.create"
+	StorageUpdate  = "admin.storage.update"
+	StorageDelete  = "admin.storage.delete"
+	StorageEnable  = "admin.storage.enable"
+	StorageDisable = "admin.storage.disable"
+	UserCreate     = "admin.user.create"
+	UserUpdate     = "admin.user.update"
+	UserDelete     = "admin.user.delete"
+	MetaCreate     = "admin.meta.create"
+	MetaUpdate     = "admin.meta.update"
+	MetaDelete     = "admin.meta.delete"
+	SettingSave    = "admin.setting.save"
+	SettingDelete  = "admin.setting.delete"
+	SettingToken   = "admin.setting.reset_token"
+	BackupExport   = "admin.backup.export"
+	BackupImport   = "admin.backup.import"
+)
+
+// Log records the action of the user in ctx, err is the result of the action
+func Log(ctx context.Context, action, src, dst string, err error) {
+	user, _ := ctx.Value("user").(*model.User)
+	LogUser(ctx, user, action, src, dst, err)
+}
+
+// LogUser records the action of user, it's used when the user isn't in ctx, such as login
+func LogUser(ctx context.Context, user *model.User, action, src, dst string, err error) {
+	if !setting.GetBool(conf.AuditEnabled) {
+		return
+	}
+	l := model.AuditLog{
+		Time:    time.Now(),
+		Action:  action,
+		Src:     src,
+		Dst:     dst,
+		Success: err == nil,
+	}
+	if user != nil {
+		l.UserID, l.Username = user.ID, user.Username
+	}
+	if ip, ok := ctx.Value("ip").(string); ok {
+		l.IP = ip
+	}
+	if via, ok := ctx.Value("via").(string); ok {
+		l.Via = via
+	}
+	if err != nil {
+		l.Error = err.Error()
+	}
+	if err := db.CreateAuditLog(&l); err != nil {
+		log.Errorf("failed save audit log: %+v", err)
+	}
+}
+
+// Clean deletes the audit logs out of the retention days
+func Clean() {
+	days := setting.GetInt(conf.AuditRetentionDays, 90)
+	if days <= 0 {
+		return
+	}
+	n, err := db.DeleteAuditLogsBefore(time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		log.Errorf("failed clean audit logs: %+v", err)
+		return
+	}
+	if n > 0 {
+		log.Infof("cleaned %d audit logs older than %d days", n, days)
+	}
+}
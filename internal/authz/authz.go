@@ -0,0 +1,120 @@
+// Package authz decides whether a user can do an action on a path,
+// by the acl rules of the user and its group, then by the permission bits.
+package authz
+
+import (
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// permissionBits maps the actions to the bits of User.Permission,
+// the actions which are not in it are allowed without rules
+var permissionBits = map[model.Action]uint{
+	model.ActionAria2:        2,
+	model.ActionWrite:        3,
+	model.ActionRename:       4,
+	model.ActionMove:         5,
+	model.ActionCopy:         6,
+	model.ActionRemove:       7,
+	model.ActionWebdav:       8,
+	model.ActionWebdavManage: 9,
+}
+
+// Permission returns the permission bits of user combined with its group
+func Permission(user *model.User) int32 {
+	p := user.Permission
+	if user.GroupID != 0 {
+		if g, ok := db.GetCachedGroup(user.GroupID); ok {
+			p |= g.Permission
+		}
+	}
+	return p
+}
+
+func CanSeeHides(user *model.User) bool {
+	return user.IsAdmin() || Permission(user)&1 == 1
+}
+
+func CanAccessWithoutPassword(user *model.User) bool {
+	return user.IsAdmin() || (Permission(user)>>1)&1 == 1
+}
+
+// Can checks if the user can do the action on the path, which is the full path in alist
+func Can(user *model.User, action model.Action, path string) bool {
+	if user.Token != nil && !user.Token.Allows(action, path) {
+		return false
+	}
+	if user.IsAdmin() {
+		return true
+	}
+	if allow, ok := evalRules(user, action, path); ok {
+		return allow
+	}
+	if action == model.ActionWrite && metaCanWrite(path) {
+		return true
+	}
+	bit, ok := permissionBits[action]
+	if !ok {
+		return true
+	}
+	return (Permission(user)>>bit)&1 == 1
+}
+
+// CanWebdav checks the actions of webdav. The ones with permission bits are decided by the acl rules
+// which mention the action, otherwise by the webdav manage permission only, as before the acl rules.
+func CanWebdav(user *model.User, action model.Action, path string) bool {
+	if _, ok := permissionBits[action]; !ok {
+		return Can(user, action, path)
+	}
+	if user.Token != nil && !user.Token.Allows(action, path) {
+		return false
+	}
+	if user.IsAdmin() {
+		return true
+	}
+	if allow, ok := evalRules(user, action, path); ok {
+		return allow
+	}
+	return Can(user, model.ActionWebdavManage, path)
+}
+
+// evalRules returns the decision of the rules with the longest path which mention the action,
+// a deny wins over an allow at the same path. ok is false if no rule mentions the action.
+func evalRules(user *model.User, action model.Action, path string) (allow bool, ok bool) {
+	rules, err := db.GetUserACLRules(user)
+	if err != nil {
+		log.Errorf("failed get acl rules of user [%s]: %+v", user.Username, err)
+		return false, true
+	}
+	longest := -1
+	for _, r := range rules {
+		if (r.Allow|r.Deny)&action == 0 || !r.Match(path) {
+			continue
+		}
+		denied := r.Deny&action != 0
+		if len(r.Path) > longest {
+			longest, allow = len(r.Path), !denied
+		} else if len(r.Path) == longest && denied {
+			allow = false
+		}
+	}
+	return allow, longest >= 0
+}
+
+// metaCanWrite checks if the meta of path allows anyone to write
+func metaCanWrite(path string) bool {
+	meta, err := db.GetNearestMeta(path)
+	if err != nil {
+		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			log.Errorf("failed get meta of %s: %+v", path, err)
+		}
+		return false
+	}
+	if !meta.Write {
+		return false
+	}
+	return meta.WSub || meta.Path == path
+}
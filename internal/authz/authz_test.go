@@ -0,0 +1,77 @@
+package authz
+
+import (
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	conf.Conf = conf.DefaultConfig()
+	d, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	db.Init(d)
+}
+
+func TestCan(t *testing.T) {
+	group := model.Group{Name: "editors", Permission: 1 << 7}
+	if err := db.CreateGroup(&group); err != nil {
+		t.Fatalf("failed create group: %+v", err)
+	}
+	user := &model.User{ID: 100, Username: "acl", GroupID: group.ID}
+	rules := []model.ACLRule{
+		{GroupID: group.ID, Path: "/docs", Inherit: true, Allow: model.ActionWrite},
+		{UserID: user.ID, Path: "/docs/private", Inherit: true, Deny: model.ActionList | model.ActionRead},
+		{GroupID: group.ID, Path: "/docs/private/shared", Allow: model.ActionRead},
+		{UserID: user.ID, Path: "/docs/private/shared", Deny: model.ActionRead},
+	}
+	for i := range rules {
+		if err := db.CreateACLRule(&rules[i]); err != nil {
+			t.Fatalf("failed create rule: %+v", err)
+		}
+	}
+	cases := []struct {
+		action model.Action
+		path   string
+		expect bool
+	}{
+		{model.ActionWrite, "/docs/a", true},
+		{model.ActionWrite, "/other", false},
+		{model.ActionRemove, "/other", true}, // by the group permission
+		{model.ActionRename, "/docs", false},
+		{model.ActionList, "/docs", true},
+		{model.ActionList, "/docs/private/a", false},
+		{model.ActionRead, "/docs/private/shared", false}, // deny wins at the same path
+		{model.ActionList, "/docs/privateer", true},
+	}
+	for _, c := range cases {
+		if got := Can(user, c.action, c.path); got != c.expect {
+			t.Errorf("Can(%s, %s) = %v, expect %v", c.action, c.path, got, c.expect)
+		}
+	}
+}
+
+func TestCanWebdav(t *testing.T) {
+	user := &model.User{ID: 101, Username: "dav", Permission: 1 << 9}
+	rule := model.ACLRule{UserID: user.ID, Path: "/readonly", Inherit: true, Deny: model.ActionRemove}
+	if err := db.CreateACLRule(&rule); err != nil {
+		t.Fatalf("failed create rule: %+v", err)
+	}
+	// the webdav manage permission is enough without the bits of actions
+	if !CanWebdav(user, model.ActionRemove, "/a") || Can(user, model.ActionRemove, "/a") {
+		t.Errorf("the remove should be allowed by webdav manage only")
+	}
+	if CanWebdav(user, model.ActionRemove, "/readonly/a") || !CanWebdav(user, model.ActionWrite, "/readonly/a") {
+		t.Errorf("the rule should decide the actions it mentions only")
+	}
+	user.Permission = 0
+	if CanWebdav(user, model.ActionWrite, "/a") || !CanWebdav(user, model.ActionList, "/a") {
+		t.Errorf("the write shouldn't be allowed without webdav manage, but the list should")
+	}
+}
@@ -0,0 +1,227 @@
+package backup
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Version is the version of the backup format
+const Version = 1
+
+// checkText is encrypted in the backup to verify the password
+const checkText = "alist"
+
+// the settings which are not restored, because they are the state of instance
+var skippedSettings = []string{conf.VERSION, conf.IndexProgress, conf.Provisioned}
+
+// the settings whose values are encrypted
+var secretSettings = []string{conf.Token, conf.Aria2Secret}
+
+// Create reads the backup of the instance, the secrets are encrypted if the password is not empty
+func Create(password string) (*model.Backup, error) {
+	b, err := db.GetBackup()
+	if err != nil {
+		return nil, err
+	}
+	b.Version = Version
+	b.AlistVersion = conf.Version
+	b.CreatedAt = time.Now()
+	skipSettings(b)
+	if password == "" {
+		return b, nil
+	}
+	salt := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	b.Encrypted = true
+	b.Salt = base64.StdEncoding.EncodeToString(salt)
+	aead, err := newAEAD(password, b.Salt)
+	if err != nil {
+		return nil, err
+	}
+	b.Check = checkText
+	if err := encrypt(aead, &b.Check); err != nil {
+		return nil, err
+	}
+	if err := walkSecrets(b, func(s *string) error {
+		return encrypt(aead, s)
+	}); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Restore validates the backup then writes it to the database, the password is required if it's encrypted.
+// If replace, the users, groups, acl rules, metas and storages not in the backup are deleted,
+// otherwise they are merged. The loaded storages should be reloaded after restoring.
+func Restore(b *model.Backup, password string, replace bool) error {
+	if b.Version <= 0 || b.Version > Version {
+		return errors.Errorf("unsupported backup version: %d", b.Version)
+	}
+	if b.Encrypted {
+		if password == "" {
+			return errors.New("the backup is encrypted, password is required")
+		}
+		aead, err := newAEAD(password, b.Salt)
+		if err != nil {
+			return err
+		}
+		if err := decrypt(aead, &b.Check); err != nil || b.Check != checkText {
+			return errors.New("wrong password of the backup")
+		}
+		if err := walkSecrets(b, func(s *string) error {
+			return decrypt(aead, s)
+		}); err != nil {
+			return err
+		}
+		b.Encrypted, b.Salt, b.Check = false, "", ""
+	}
+	if err := validate(b, replace); err != nil {
+		return err
+	}
+	return db.RestoreBackup(b, replace)
+}
+
+// validate checks the drivers of storages, and the admin and guest are required to replace
+func validate(b *model.Backup, replace bool) error {
+	for i := range b.Storages {
+		s := &b.Storages[i]
+		s.MountPath = utils.StandardizePath(s.MountPath)
+		if _, err := op.GetDriverNew(s.Driver); err != nil {
+			return errors.WithMessagef(err, "invalid storage [%s]", s.MountPath)
+		}
+		if s.Addition == "" {
+			continue
+		}
+		if err := utils.Json.UnmarshalFromString(s.Addition, &map[string]interface{}{}); err != nil {
+			return errors.Wrapf(err, "invalid addition of storage [%s]", s.MountPath)
+		}
+	}
+	skipSettings(b)
+	if !replace {
+		return nil
+	}
+	var admins, guests int
+	for _, u := range b.Users {
+		if u.IsAdmin() {
+			admins++
+		}
+		if u.IsGuest() {
+			guests++
+		}
+	}
+	if admins == 0 || guests != 1 {
+		return errors.New("the backup to replace must have an admin and a guest")
+	}
+	return nil
+}
+
+// Reload drops the loaded storages and loads the enabled storages in database in background
+func Reload(ctx context.Context) error {
+	op.DropStorages(ctx)
+	storages, err := db.GetEnabledStorages()
+	if err != nil {
+		return errors.WithMessage(err, "failed get enabled storages")
+	}
+	go func() {
+		for i := range storages {
+			if err := op.LoadStorage(context.Background(), storages[i]); err != nil {
+				log.Errorf("failed load storage [%s]: %+v", storages[i].MountPath, err)
+			}
+		}
+	}()
+	return nil
+}
+
+func skipSettings(b *model.Backup) {
+	settings := b.Settings[:0]
+	for _, item := range b.Settings {
+		if !utils.SliceContains(skippedSettings, item.Key) {
+			settings = append(settings, item)
+		}
+	}
+	b.Settings = settings
+}
+
+// walkSecrets calls fn with the pointers of secrets in backup
+func walkSecrets(b *model.Backup, fn func(s *string) error) error {
+	var secrets []*string
+	for i := range b.Users {
+		secrets = append(secrets, &b.Users[i].Password, &b.Users[i].OtpSecret)
+	}
+	for i := range b.Metas {
+		secrets = append(secrets, &b.Metas[i].Password)
+	}
+	for i := range b.Storages {
+		secrets = append(secrets, &b.Storages[i].Addition)
+	}
+	for i := range b.Settings {
+		if utils.SliceContains(secretSettings, b.Settings[i].Key) {
+			secrets = append(secrets, &b.Settings[i].Value)
+		}
+	}
+	for _, s := range secrets {
+		if *s == "" {
+			continue
+		}
+		if err := fn(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newAEAD(password, salt string) (cipher.AEAD, error) {
+	s, err := base64.StdEncoding.DecodeString(salt)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid salt")
+	}
+	key, err := scrypt.Key([]byte(password), s, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	return aead, errors.WithStack(err)
+}
+
+// encrypt replaces the plaintext with the base64 of nonce and ciphertext
+func encrypt(aead cipher.AEAD, s *string) error {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return errors.WithStack(err)
+	}
+	*s = base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, []byte(*s), nil))
+	return nil
+}
+
+func decrypt(aead cipher.AEAD, s *string) error {
+	data, err := base64.StdEncoding.DecodeString(*s)
+	if err != nil || len(data) < aead.NonceSize() {
+		return errors.New("invalid encrypted secret")
+	}
+	plain, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+	if err != nil {
+		return errors.New("failed decrypt secret, the password may be wrong")
+	}
+	*s = string(plain)
+	return nil
+}
@@ -0,0 +1,126 @@
+package backup
+
+import (
+	"strings"
+	"testing"
+
+	_ "github.com/alist-org/alist/v3/drivers/local"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	conf.Conf = conf.DefaultConfig()
+	d, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	db.Init(d)
+}
+
+// roundTrip creates the backup and decodes it from json like reading it from a file
+func roundTrip(t *testing.T, password string) *model.Backup {
+	b, err := Create(password)
+	if err != nil {
+		t.Fatalf("failed create backup: %+v", err)
+	}
+	data, err := utils.Json.Marshal(b)
+	if err != nil {
+		t.Fatalf("failed marshal backup: %+v", err)
+	}
+	var res model.Backup
+	if err := utils.Json.Unmarshal(data, &res); err != nil {
+		t.Fatalf("failed unmarshal backup: %+v", err)
+	}
+	return &res
+}
+
+func TestBackup(t *testing.T) {
+	users := []model.User{
+		{Username: "admin", Password: "admin", Role: model.ADMIN},
+		{Username: "guest", Role: model.GUEST},
+		{Username: "alice", Password: "alice", Role: model.GENERAL, OtpSecret: "otp"},
+	}
+	for i := range users {
+		if err := db.CreateUser(&users[i]); err != nil {
+			t.Fatalf("failed create user: %+v", err)
+		}
+	}
+	if err := db.CreateMeta(&model.Meta{Path: "/secret", Password: "meta"}); err != nil {
+		t.Fatalf("failed create meta: %+v", err)
+	}
+	if err := db.CreateStorage(&model.Storage{Driver: "Local", MountPath: "/local", Addition: `{"root_folder_path":"/data"}`}); err != nil {
+		t.Fatalf("failed create storage: %+v", err)
+	}
+
+	b := roundTrip(t, "pass")
+	plain := roundTrip(t, "")
+	data, _ := utils.Json.MarshalToString(b)
+	if !b.Encrypted || strings.Contains(data, "/data") || strings.Contains(data, `"otp"`) {
+		t.Fatalf("the secrets should be encrypted: %s", data)
+	}
+	if err := Restore(roundTrip(t, "pass"), "wrong", false); err == nil {
+		t.Fatalf("expect failed to restore with wrong password")
+	}
+
+	// merge keeps the users not in the backup and restores the deleted meta
+	if err := db.CreateUser(&model.User{Username: "bob", Role: model.GENERAL}); err != nil {
+		t.Fatalf("failed create user: %+v", err)
+	}
+	meta, _ := db.GetMetaByPath("/secret")
+	if err := db.DeleteMetaById(meta.ID); err != nil {
+		t.Fatalf("failed delete meta: %+v", err)
+	}
+	if err := Restore(b, "pass", false); err != nil {
+		t.Fatalf("failed restore backup: %+v", err)
+	}
+	if _, err := db.GetUserByName("bob"); err != nil {
+		t.Fatalf("the user should be kept by merge: %+v", err)
+	}
+	meta, err := db.GetMetaByPath("/secret")
+	if err != nil || !meta.ValidatePassword("meta") {
+		t.Fatalf("the meta should be restored: %+v %+v", meta, err)
+	}
+	alice, err := db.GetUserByName("alice")
+	if err != nil || alice.OtpSecret != "otp" || alice.ValidatePassword("alice") != nil {
+		t.Fatalf("the user should be restored: %+v %+v", alice, err)
+	}
+
+	// replace removes the users not in the backup
+	if err := Restore(plain, "", true); err != nil {
+		t.Fatalf("failed restore backup: %+v", err)
+	}
+	if _, err := db.GetUserByName("bob"); err == nil {
+		t.Fatalf("the user should be removed by replace")
+	}
+	storages, total, err := db.GetStorages(1, 10)
+	if err != nil || total != 1 || storages[0].Addition != `{"root_folder_path":"/data"}` {
+		t.Fatalf("unexpected storages: %+v %+v", storages, err)
+	}
+}
+
+func TestRestoreSettingHooks(t *testing.T) {
+	conf.TypesMap[conf.VideoTypes] = []string{"mp4"}
+	b := &model.Backup{
+		// the duplicated group names fail the transaction if replace
+		Groups:   []model.Group{{Name: "dup"}, {Name: "dup"}},
+		Settings: []model.SettingItem{{Key: conf.VideoTypes, Value: "mkv", Type: conf.TypeText, Group: model.PREVIEW}},
+	}
+	if err := db.RestoreBackup(b, true); err == nil {
+		t.Fatalf("expect failed to restore the duplicated groups")
+	}
+	if strings.Join(conf.TypesMap[conf.VideoTypes], ",") != "mp4" {
+		t.Errorf("the setting hooks shouldn't run if the restore fails: %v", conf.TypesMap[conf.VideoTypes])
+	}
+	b.Groups = nil
+	if err := db.RestoreBackup(b, false); err != nil {
+		t.Fatalf("failed restore backup: %+v", err)
+	}
+	if strings.Join(conf.TypesMap[conf.VideoTypes], ",") != "mkv" {
+		t.Errorf("the setting hooks should run after the restore: %v", conf.TypesMap[conf.VideoTypes])
+	}
+}
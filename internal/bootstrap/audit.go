@@ -0,0 +1,17 @@
+package bootstrap
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/audit"
+)
+
+// InitAudit cleans the expired audit logs now and every day
+func InitAudit() {
+	go func() {
+		for {
+			audit.Clean()
+			time.Sleep(24 * time.Hour)
+		}
+	}()
+}
@@ -130,12 +130,35 @@ func InitialSettings() []model.SettingItem {
 		{Key: conf.Aria2Uri, Value: "http://localhost:6800/jsonrpc", Type: conf.TypeString, Group: model.ARIA2, Flag: model.PRIVATE},
 		{Key: conf.Aria2Secret, Value: "", Type: conf.TypeString, Group: model.ARIA2, Flag: model.PRIVATE},
 
+		// s3 settings
+		{Key: conf.S3Buckets, Value: "[]", Type: conf.TypeText, Group: model.S3, Flag: model.PRIVATE, Help: `[{"name":"bucket","path":"/path/in/alist"}], the path is relative to the base path of user`},
+
+		// audit settings
+		{Key: conf.AuditEnabled, Value: "true", Type: conf.TypeBool, Group: model.AUDIT, Flag: model.PRIVATE},
+		{Key: conf.AuditRetentionDays, Value: "90", Type: conf.TypeNumber, Group: model.AUDIT, Flag: model.PRIVATE, Help: `the audit logs older than it are deleted, 0 means keep forever`},
+
+		// rate limit settings
+		{Key: conf.RateLimitRequests, Value: "0", Type: conf.TypeNumber, Group: model.LIMIT, Flag: model.PRIVATE, Help: `requests per second of a user or an ip, 0 means unlimited`},
+		{Key: conf.RateLimitDownload, Value: "0", Type: conf.TypeNumber, Group: model.LIMIT, Flag: model.PRIVATE, Help: `download bytes per second of all clients, 0 means unlimited`},
+		{Key: conf.RateLimitUpload, Value: "0", Type: conf.TypeNumber, Group: model.LIMIT, Flag: model.PRIVATE, Help: `upload bytes per second of all clients, 0 means unlimited`},
+		{Key: conf.RateLimitUserDownload, Value: "0", Type: conf.TypeNumber, Group: model.LIMIT, Flag: model.PRIVATE, Help: `download bytes per second of a user or an ip, 0 means unlimited`},
+		{Key: conf.RateLimitUserUpload, Value: "0", Type: conf.TypeNumber, Group: model.LIMIT, Flag: model.PRIVATE, Help: `upload bytes per second of a user or an ip, 0 means unlimited`},
+		{Key: conf.RateLimitRules, Value: "{}", Type: conf.TypeText, Group: model.LIMIT, Flag: model.PRIVATE, Help: `{"users":{"name":{"requests":10,"download":1048576,"upload":0}},"storages":{"/mount/path":{"download":1048576,"upload":0}}}, the limits of users override the ones above, 0 means unlimited`},
+
+		// storage health settings
+		{Key: conf.StorageHealthInterval, Value: "5", Type: conf.TypeNumber, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `minutes between the health checks of a working storage, 0 means never check`},
+		{Key: conf.StorageHealthMaxBackoff, Value: "60", Type: conf.TypeNumber, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `max minutes between the re-initializations of a failed storage, the delay starts from 1 minute and doubles after each failure`},
+
 		// single settings
 		{Key: conf.Token, Value: token, Type: conf.TypeString, Group: model.SINGLE, Flag: model.PRIVATE},
 		{Key: conf.SearchIndex, Value: "none", Type: conf.TypeSelect, Options: "database,bleve,none", Group: model.INDEX},
 		{Key: conf.IndexPaths, Value: "/", Type: conf.TypeText, Group: model.INDEX, Flag: model.PRIVATE, Help: `one path per line`},
 		{Key: conf.IgnorePaths, Value: "", Type: conf.TypeText, Group: model.INDEX, Flag: model.PRIVATE, Help: `one path per line`},
+		{Key: conf.IndexContent, Value: "false", Type: conf.TypeBool, Group: model.INDEX, Flag: model.PRIVATE, Help: `index the content of text types, only for bleve`},
+		{Key: conf.IndexContentMaxSize, Value: "1048576", Type: conf.TypeNumber, Group: model.INDEX, Flag: model.PRIVATE, Help: `max size in bytes of the file whose content is indexed`},
+		{Key: conf.IndexContentPaths, Value: "", Type: conf.TypeText, Group: model.INDEX, Flag: model.PRIVATE, Help: `one path per line, all index paths if empty`},
 		{Key: conf.IndexProgress, Value: "{}", Type: conf.TypeText, Group: model.SINGLE, Flag: model.PRIVATE},
+		{Key: conf.Provisioned, Value: "{}", Type: conf.TypeText, Group: model.SINGLE, Flag: model.PRIVATE},
 	}
 	if flags.Dev {
 		initialSettingItems = append(initialSettingItems, []model.SettingItem{
@@ -32,12 +32,15 @@ func initUser() {
 			if err := db.CreateUser(admin); err != nil {
 				panic(err)
 			} else {
-				utils.Log.Infof("Successfully created the admin user and the initial password is: %s", admin.Password)
+				utils.Log.Infof("Successfully created the admin user and the initial password is: %s", adminPassword)
 			}
 		} else {
 			panic(err)
 		}
 	}
+	if err := db.MigratePasswords(); err != nil {
+		utils.Log.Fatalf("failed migrate passwords: %+v", err)
+	}
 	guest, err := db.GetGuest()
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -0,0 +1,10 @@
+package bootstrap
+
+import (
+	"github.com/alist-org/alist/v3/internal/health"
+)
+
+// InitHealth starts the health checks of storages, which wait until the storages are loaded
+func InitHealth() {
+	health.Init()
+}
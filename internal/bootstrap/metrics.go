@@ -0,0 +1,71 @@
+package bootstrap
+
+import (
+	"github.com/alist-org/alist/v3/internal/aria2"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/metrics"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/search"
+	"github.com/alist-org/alist/v3/pkg/task"
+)
+
+func taskStates[K comparable](kind string, tm *task.Manager[K]) []metrics.Sample {
+	counts := make(map[string]int)
+	for _, t := range tm.GetAll() {
+		counts[t.GetState()]++
+	}
+	samples := make([]metrics.Sample, 0, len(counts))
+	for state, n := range counts {
+		samples = append(samples, metrics.Sample{
+			Labels: map[string]string{"kind": kind, "state": state},
+			Value:  float64(n),
+		})
+	}
+	return samples
+}
+
+// InitMetrics registers the gauges collected on scraping
+func InitMetrics() {
+	metrics.RegisterGaugeFunc("alist_tasks", "Count of tasks by kind and state.", func() []metrics.Sample {
+		var samples []metrics.Sample
+		samples = append(samples, taskStates("upload", fs.UploadTaskManager)...)
+		samples = append(samples, taskStates("copy", fs.CopyTaskManager)...)
+		samples = append(samples, taskStates("move", fs.MoveTaskManager)...)
+		samples = append(samples, taskStates("aria2_down", aria2.DownTaskManager)...)
+		samples = append(samples, taskStates("aria2_transfer", aria2.TransferTaskManager)...)
+		return samples
+	})
+	metrics.RegisterGaugeFunc("alist_index_objects", "Count of objects in the search index.", func() []metrics.Sample {
+		progress, err := search.Progress()
+		if err != nil {
+			return nil
+		}
+		return []metrics.Sample{{Value: float64(progress.ObjCount)}}
+	})
+	metrics.RegisterGaugeFunc("alist_index_done", "Whether the building of search index is done.", func() []metrics.Sample {
+		progress, err := search.Progress()
+		if err != nil {
+			return nil
+		}
+		done := 0.0
+		if progress.IsDone {
+			done = 1
+		}
+		return []metrics.Sample{{Value: done}}
+	})
+	metrics.RegisterGaugeFunc("alist_storage_up", "Whether the storage works, 1 if its status is work.", func() []metrics.Sample {
+		var samples []metrics.Sample
+		for _, s := range op.GetAllStorages() {
+			storage := s.GetStorage()
+			up := 0.0
+			if storage.Status == op.WORK {
+				up = 1
+			}
+			samples = append(samples, metrics.Sample{
+				Labels: map[string]string{"mount_path": storage.MountPath, "driver": storage.Driver},
+				Value:  up,
+			})
+		}
+		return samples
+	})
+}
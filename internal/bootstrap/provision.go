@@ -0,0 +1,45 @@
+package bootstrap
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/provision"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+// InitProvision reconciles the provisioning file before loading storages,
+// and again with the loaded storages on SIGHUP
+func InitProvision() {
+	if conf.Conf.Provision == "" {
+		return
+	}
+	if err := reconcileProvision(false); err != nil {
+		utils.Log.Fatalf("failed provision: %+v", err)
+	}
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			utils.Log.Infof("reload provisioning file: %s", conf.Conf.Provision)
+			if err := reconcileProvision(true); err != nil {
+				utils.Log.Errorf("failed provision: %+v", err)
+			}
+		}
+	}()
+}
+
+func reconcileProvision(load bool) error {
+	f, err := provision.Load(conf.Conf.Provision)
+	if err != nil {
+		return err
+	}
+	changes, err := provision.Reconcile(context.Background(), f, provision.Options{Load: load})
+	for _, c := range changes {
+		utils.Log.Infof("provision: %s", c)
+	}
+	return err
+}
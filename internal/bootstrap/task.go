@@ -0,0 +1,116 @@
+package bootstrap
+
+import (
+	"strconv"
+
+	"github.com/alist-org/alist/v3/internal/aria2"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/task"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+// dbTaskStore persists tasks with the database
+type dbTaskStore struct{}
+
+func (dbTaskStore) SaveTask(info *task.Info) error {
+	return db.SaveTaskItem(&model.TaskItem{
+		Kind:      info.Kind,
+		Tid:       info.ID,
+		ParentTid: info.ParentID,
+		Name:      info.Name,
+		Args:      info.Args,
+		State:     info.State,
+		Status:    info.Status,
+		Progress:  info.Progress,
+		Size:      info.Size,
+		Error:     info.Error,
+	})
+}
+
+func (dbTaskStore) DeleteTask(kind string, id string) error {
+	return db.DeleteTaskItem(kind, id)
+}
+
+func (dbTaskStore) GetTasks(kind string) ([]task.Info, error) {
+	items, err := db.GetTaskItemsByKind(kind)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]task.Info, len(items))
+	for i, item := range items {
+		infos[i] = task.Info{
+			ID:       item.Tid,
+			ParentID: item.ParentTid,
+			Kind:     item.Kind,
+			Name:     item.Name,
+			Args:     item.Args,
+			State:    item.State,
+			Status:   item.Status,
+			Progress: item.Progress,
+			Size:     item.Size,
+			Error:    item.Error,
+		}
+	}
+	return infos, nil
+}
+
+// emitTaskFinished returns the callback emitting the event of finished tasks
+func emitTaskFinished[K comparable](kind string) task.Callback[K] {
+	return func(t *task.Task[K]) {
+		op.Emit(op.Event{
+			Type:     op.EventTaskFinished,
+			Status:   t.GetState(),
+			Task:     t.Name,
+			TaskKind: kind,
+			Error:    t.GetErrMsg(),
+		})
+	}
+}
+
+func parseUintID(id string) (uint64, error) {
+	return strconv.ParseUint(id, 10, 64)
+}
+
+func parseStrID(id string) (string, error) {
+	return id, nil
+}
+
+// InitTaskManager restore the tasks persisted in database,
+// only copy and move tasks can be resumed, because the temp files of other tasks are removed while booting.
+func InitTaskManager() {
+	store := dbTaskStore{}
+	uintManagers := []struct {
+		kind   string
+		tm     *task.Manager[uint64]
+		resume func(info *task.Info) task.Func[uint64]
+	}{
+		{kind: "copy", tm: fs.CopyTaskManager, resume: fs.ResumeCopyTask},
+		{kind: "move", tm: fs.MoveTaskManager, resume: fs.ResumeMoveTask},
+		{kind: "upload", tm: fs.UploadTaskManager},
+		{kind: "aria2_transfer", tm: aria2.TransferTaskManager},
+	}
+	for _, m := range uintManagers {
+		m.tm.OnDone(emitTaskFinished[uint64](m.kind))
+		err := m.tm.Persist(&task.Persistence[uint64]{
+			Kind:    m.kind,
+			Store:   store,
+			ParseID: parseUintID,
+			Resume:  m.resume,
+		})
+		if err != nil {
+			utils.Log.Errorf("failed restore %s tasks: %+v", m.kind, err)
+		}
+	}
+	aria2.DownTaskManager.OnDone(emitTaskFinished[string]("aria2_down"))
+	err := aria2.DownTaskManager.Persist(&task.Persistence[string]{
+		Kind:    "aria2_down",
+		Store:   store,
+		ParseID: parseStrID,
+	})
+	if err != nil {
+		utils.Log.Errorf("failed restore aria2_down tasks: %+v", err)
+	}
+}
@@ -0,0 +1,19 @@
+package bootstrap
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/webhook"
+)
+
+// InitWebhook starts sending the events to webhooks, it should be called before loading storages
+// to send their status. The expired deliveries are cleaned now and every day.
+func InitWebhook() {
+	webhook.Init()
+	go func() {
+		for {
+			webhook.Clean()
+			time.Sleep(24 * time.Hour)
+		}
+	}()
+}
@@ -34,6 +34,18 @@ type LogConfig struct {
 	Compress   bool   `json:"compress" env:"COMPRESS"`
 }
 
+type S3 struct {
+	Enable bool `json:"enable" env:"S3_ENABLE"`
+	Port   int  `json:"port" env:"S3_PORT"`
+	SSL    bool `json:"ssl" env:"S3_SSL"` // use the cert and key of scheme
+}
+
+type Metrics struct {
+	Enable bool   `json:"enable" env:"METRICS_ENABLE"`
+	Token  string `json:"token" env:"METRICS_TOKEN"` // the bearer token of /metrics, it's required if port is 0
+	Port   int    `json:"port" env:"METRICS_PORT"`   // serve /metrics on a separate port if it's not 0
+}
+
 type Config struct {
 	Force          bool      `json:"force" env:"FORCE"`
 	Address        string    `json:"address" env:"ADDR"`
@@ -49,6 +61,11 @@ type Config struct {
 	BleveDir       string    `json:"bleve_dir" env:"BLEVE_DIR"`
 	Log            LogConfig `json:"log"`
 	MaxConnections int       `json:"max_connections" env:"MAX_CONNECTIONS"`
+	S3             S3        `json:"s3"`
+	Metrics        Metrics   `json:"metrics"`
+	// Provision is the yaml or json file of the storages, users, metas and settings
+	// which are reconciled at startup and on SIGHUP
+	Provision string `json:"provision" env:"PROVISION"`
 }
 
 func DefaultConfig() *Config {
@@ -80,5 +97,9 @@ func DefaultConfig() *Config {
 			MaxAge:     28,
 		},
 		MaxConnections: 0,
+		S3: S3{
+			Enable: false,
+			Port:   5246,
+		},
 	}
 }
@@ -48,13 +48,37 @@ const (
 	IndexPaths  = "index_paths"
 	IgnorePaths = "ignore_paths"
 
+	IndexContent        = "index_content"
+	IndexContentMaxSize = "index_content_max_size"
+	IndexContentPaths   = "index_content_paths"
+
 	// aria2
 	Aria2Uri    = "aria2_uri"
 	Aria2Secret = "aria2_secret"
 
+	// s3
+	S3Buckets = "s3_buckets"
+
+	// audit
+	AuditEnabled       = "audit_enabled"
+	AuditRetentionDays = "audit_retention_days"
+
+	// rate limit
+	RateLimitRequests     = "rate_limit_requests"
+	RateLimitDownload     = "rate_limit_download"
+	RateLimitUpload       = "rate_limit_upload"
+	RateLimitUserDownload = "rate_limit_user_download"
+	RateLimitUserUpload   = "rate_limit_user_upload"
+	RateLimitRules        = "rate_limit_rules"
+
+	// storage health
+	StorageHealthInterval   = "storage_health_interval"
+	StorageHealthMaxBackoff = "storage_health_max_backoff"
+
 	// single
 	Token         = "token"
 	IndexProgress = "index_progress"
+	Provisioned   = "provisioned"
 )
 
 const (
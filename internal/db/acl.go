@@ -0,0 +1,157 @@
+package db
+
+import (
+	"sync"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// the groups and acl rules are read on every permission check, so they are all cached
+var (
+	aclMu     sync.RWMutex
+	groupsMap map[uint]model.Group
+	aclRules  []model.ACLRule
+)
+
+func loadACL() error {
+	aclMu.RLock()
+	loaded := groupsMap != nil
+	aclMu.RUnlock()
+	if loaded {
+		return nil
+	}
+	aclMu.Lock()
+	defer aclMu.Unlock()
+	var groups []model.Group
+	if err := db.Find(&groups).Error; err != nil {
+		return errors.Wrapf(err, "failed find groups")
+	}
+	var rules []model.ACLRule
+	if err := db.Find(&rules).Error; err != nil {
+		return errors.Wrapf(err, "failed find acl rules")
+	}
+	groupsMap = make(map[uint]model.Group, len(groups))
+	for _, g := range groups {
+		groupsMap[g.ID] = g
+	}
+	aclRules = rules
+	return nil
+}
+
+func resetACL() {
+	aclMu.Lock()
+	groupsMap, aclRules = nil, nil
+	aclMu.Unlock()
+}
+
+// GetCachedGroup returns the group by id from cache
+func GetCachedGroup(id uint) (*model.Group, bool) {
+	if err := loadACL(); err != nil {
+		return nil, false
+	}
+	aclMu.RLock()
+	defer aclMu.RUnlock()
+	g, ok := groupsMap[id]
+	return &g, ok
+}
+
+// GetUserACLRules returns the cached rules of the user and its group
+func GetUserACLRules(user *model.User) ([]model.ACLRule, error) {
+	if err := loadACL(); err != nil {
+		return nil, err
+	}
+	aclMu.RLock()
+	defer aclMu.RUnlock()
+	var rules []model.ACLRule
+	for _, r := range aclRules {
+		if (r.UserID != 0 && r.UserID == user.ID) || (r.GroupID != 0 && r.GroupID == user.GroupID) {
+			rules = append(rules, r)
+		}
+	}
+	return rules, nil
+}
+
+func GetGroups(pageIndex, pageSize int) ([]model.Group, int64, error) {
+	groupDB := db.Model(&model.Group{})
+	var count int64
+	if err := groupDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get groups count")
+	}
+	var groups []model.Group
+	if err := groupDB.Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&groups).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find groups")
+	}
+	return groups, count, nil
+}
+
+func GetGroupById(id uint) (*model.Group, error) {
+	var g model.Group
+	if err := db.First(&g, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get group")
+	}
+	return &g, nil
+}
+
+func CreateGroup(g *model.Group) error {
+	defer resetACL()
+	return errors.WithStack(db.Create(g).Error)
+}
+
+func UpdateGroup(g *model.Group) error {
+	defer resetACL()
+	return errors.WithStack(db.Save(g).Error)
+}
+
+// DeleteGroupById deletes the group with its rules, the users in it are moved out
+func DeleteGroupById(id uint) error {
+	defer resetACL()
+	if err := db.Model(&model.User{}).Where("group_id = ?", id).Update("group_id", 0).Error; err != nil {
+		return errors.Wrapf(err, "failed remove users from group")
+	}
+	userCache.Clear()
+	guest, admin = nil, nil
+	if err := db.Where("group_id = ?", id).Delete(&model.ACLRule{}).Error; err != nil {
+		return errors.Wrapf(err, "failed delete rules of group")
+	}
+	return errors.WithStack(db.Delete(&model.Group{}, id).Error)
+}
+
+func GetACLRules(pageIndex, pageSize int) ([]model.ACLRule, int64, error) {
+	ruleDB := db.Model(&model.ACLRule{})
+	var count int64
+	if err := ruleDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get acl rules count")
+	}
+	var rules []model.ACLRule
+	if err := ruleDB.Order("path").Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&rules).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find acl rules")
+	}
+	return rules, count, nil
+}
+
+func GetACLRuleById(id uint) (*model.ACLRule, error) {
+	var r model.ACLRule
+	if err := db.First(&r, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get acl rule")
+	}
+	return &r, nil
+}
+
+func CreateACLRule(r *model.ACLRule) error {
+	defer resetACL()
+	r.Path = utils.StandardizePath(r.Path)
+	return errors.WithStack(db.Create(r).Error)
+}
+
+func UpdateACLRule(r *model.ACLRule) error {
+	defer resetACL()
+	r.Path = utils.StandardizePath(r.Path)
+	return errors.WithStack(db.Save(r).Error)
+}
+
+func DeleteACLRuleById(id uint) error {
+	defer resetACL()
+	return errors.WithStack(db.Delete(&model.ACLRule{}, id).Error)
+}
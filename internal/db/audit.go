@@ -0,0 +1,89 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+func CreateAuditLog(l *model.AuditLog) error {
+	return errors.WithStack(db.Create(l).Error)
+}
+
+func auditFilters(req model.AuditReq) *gorm.DB {
+	tx := db.Model(&model.AuditLog{})
+	if req.Username != "" {
+		tx = tx.Where(fmt.Sprintf("%s = ?", columnName("username")), req.Username)
+	}
+	if req.IP != "" {
+		tx = tx.Where(fmt.Sprintf("%s = ?", columnName("ip")), req.IP)
+	}
+	if req.Action != "" {
+		tx = tx.Where(fmt.Sprintf("%s LIKE ? ESCAPE '!'", columnName("action")), globToLike(req.Action)+"%")
+	}
+	if req.Path != "" {
+		like := globToLike("*" + req.Path + "*")
+		tx = tx.Where(fmt.Sprintf("(%s LIKE ? ESCAPE '!' OR %s LIKE ? ESCAPE '!')", columnName("src"), columnName("dst")), like, like)
+	}
+	if req.Success != nil {
+		tx = tx.Where(fmt.Sprintf("%s = ?", columnName("success")), *req.Success)
+	}
+	if !req.From.IsZero() {
+		tx = tx.Where(fmt.Sprintf("%s >= ?", columnName("time")), req.From)
+	}
+	if !req.To.IsZero() {
+		tx = tx.Where(fmt.Sprintf("%s <= ?", columnName("time")), req.To)
+	}
+	return tx
+}
+
+// GetAuditLogs returns the audit logs matching the filters, the latest first
+func GetAuditLogs(req model.AuditReq) ([]model.AuditLog, int64, error) {
+	var count int64
+	if err := auditFilters(req).Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get audit logs count")
+	}
+	var logs []model.AuditLog
+	if err := auditFilters(req).Order(columnName("id") + " DESC").
+		Offset((req.Page - 1) * req.PerPage).Limit(req.PerPage).Find(&logs).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find audit logs")
+	}
+	return logs, count, nil
+}
+
+// auditBatchSize is the number of audit logs read at a time by WalkAuditLogs
+var auditBatchSize = 1000
+
+// WalkAuditLogs calls fn on the audit logs matching the filters, the latest first,
+// they are read by batches so that the whole table isn't loaded into memory
+func WalkAuditLogs(req model.AuditReq, fn func(l *model.AuditLog) error) error {
+	var lastID uint
+	for {
+		tx := auditFilters(req)
+		if lastID != 0 {
+			tx = tx.Where(fmt.Sprintf("%s < ?", columnName("id")), lastID)
+		}
+		var logs []model.AuditLog
+		if err := tx.Order(columnName("id") + " DESC").Limit(auditBatchSize).Find(&logs).Error; err != nil {
+			return errors.Wrapf(err, "failed find audit logs")
+		}
+		for i := range logs {
+			if err := fn(&logs[i]); err != nil {
+				return err
+			}
+		}
+		if len(logs) < auditBatchSize {
+			return nil
+		}
+		lastID = logs[len(logs)-1].ID
+	}
+}
+
+// DeleteAuditLogsBefore deletes the audit logs older than t
+func DeleteAuditLogsBefore(t time.Time) (int64, error) {
+	res := db.Where(fmt.Sprintf("%s < ?", columnName("time")), t).Delete(&model.AuditLog{})
+	return res.RowsAffected, errors.WithStack(res.Error)
+}
@@ -0,0 +1,65 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+func TestGetAuditLogs(t *testing.T) {
+	now := time.Now()
+	logs := []model.AuditLog{
+		{Time: now.AddDate(0, 0, -10), Username: "alice", Action: "fs.remove", Src: "/a/old.txt", Success: true},
+		{Time: now, Username: "alice", Action: "fs.move", Src: "/a/b.txt", Dst: "/c", Success: true},
+		{Time: now, Username: "bob", Action: "fs.put", Dst: "/c/100%.txt", Success: false, Error: "failed"},
+		{Time: now, Username: "bob", Action: "admin.user.create", Src: "carol", Success: true},
+	}
+	for i := range logs {
+		if err := CreateAuditLog(&logs[i]); err != nil {
+			t.Fatalf("failed create audit log: %+v", err)
+		}
+	}
+	failed := false
+	cases := []struct {
+		req    model.AuditReq
+		expect int64
+	}{
+		{model.AuditReq{Username: "alice"}, 2},
+		{model.AuditReq{Action: "fs"}, 3},
+		{model.AuditReq{Action: "fs.put"}, 1},
+		{model.AuditReq{Path: "/c"}, 2},
+		{model.AuditReq{Path: "100%"}, 1},
+		{model.AuditReq{Success: &failed}, 1},
+		{model.AuditReq{From: now.AddDate(0, 0, -1)}, 3},
+	}
+	for _, c := range cases {
+		c.req.Validate()
+		_, total, err := GetAuditLogs(c.req)
+		if err != nil {
+			t.Fatalf("failed get audit logs: %+v", err)
+		}
+		if total != c.expect {
+			t.Errorf("%+v: expect %d logs, got %d", c.req, c.expect, total)
+		}
+	}
+	auditBatchSize = 3
+	defer func() { auditBatchSize = 1000 }()
+	var ids []uint
+	if err := WalkAuditLogs(model.AuditReq{Action: "fs"}, func(l *model.AuditLog) error {
+		ids = append(ids, l.ID)
+		return nil
+	}); err != nil {
+		t.Fatalf("failed walk audit logs: %+v", err)
+	}
+	if len(ids) != 3 || ids[0] != logs[2].ID || ids[2] != logs[0].ID {
+		t.Errorf("expect the fs logs the latest first, got %v", ids)
+	}
+	n, err := DeleteAuditLogsBefore(now.AddDate(0, 0, -1))
+	if err != nil {
+		t.Fatalf("failed delete audit logs: %+v", err)
+	}
+	if n != 1 {
+		t.Errorf("expect 1 log deleted, got %d", n)
+	}
+}
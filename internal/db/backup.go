@@ -0,0 +1,132 @@
+package db
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// GetBackup reads all the users, groups, acl rules, metas, storages and setting items
+func GetBackup() (*model.Backup, error) {
+	b := &model.Backup{}
+	var users []model.User
+	if err := db.Find(&users).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find users")
+	}
+	for _, u := range users {
+		b.Users = append(b.Users, model.BackupUser{User: u, OtpSecret: u.OtpSecret})
+	}
+	for _, dst := range []interface{}{&b.Groups, &b.ACLRules, &b.Metas, &b.Storages, &b.Settings} {
+		if err := db.Find(dst).Error; err != nil {
+			return nil, errors.Wrapf(err, "failed find %T", dst)
+		}
+	}
+	return b, nil
+}
+
+// RestoreBackup writes the backup in a transaction. If replace, the users, groups, acl rules,
+// metas and storages are deleted first, with the tokens and shares of the deleted users.
+// Otherwise they are merged by username, group name, path, mount path and key.
+// The ids are always allocated by the database, and the references between them are kept.
+func RestoreBackup(b *model.Backup, replace bool) error {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if replace {
+			for _, m := range []interface{}{&model.User{}, &model.Group{}, &model.ACLRule{}, &model.Meta{}, &model.Storage{}, &model.Token{}, &model.Share{}} {
+				if err := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(m).Error; err != nil {
+					return errors.Wrapf(err, "failed delete %T", m)
+				}
+			}
+		}
+		// the ids in backup to the ids in database
+		groupIds := map[uint]uint{0: 0}
+		for _, g := range b.Groups {
+			id := g.ID
+			if err := restore(tx, &g, &g.ID, !replace, "name = ?", g.Name); err != nil {
+				return errors.WithMessagef(err, "failed restore group [%s]", g.Name)
+			}
+			groupIds[id] = g.ID
+		}
+		userIds := map[uint]uint{0: 0}
+		for _, bu := range b.Users {
+			id, u := bu.ID, bu.User
+			u.OtpSecret = bu.OtpSecret
+			u.GroupID = groupIds[u.GroupID]
+			if err := hashPassword(&u.Password); err != nil {
+				return err
+			}
+			// there is only one guest whatever its name is
+			query, arg := "username = ?", interface{}(u.Username)
+			if u.IsGuest() {
+				query, arg = "role = ?", model.GUEST
+			}
+			if err := restore(tx, &u, &u.ID, !replace, query, arg); err != nil {
+				return errors.WithMessagef(err, "failed restore user [%s]", u.Username)
+			}
+			userIds[id] = u.ID
+		}
+		for _, r := range b.ACLRules {
+			r.UserID, r.GroupID = userIds[r.UserID], groupIds[r.GroupID]
+			if err := restore(tx, &r, &r.ID, !replace, "user_id = ? AND group_id = ? AND path = ?", r.UserID, r.GroupID, r.Path); err != nil {
+				return errors.WithMessagef(err, "failed restore acl rule of [%s]", r.Path)
+			}
+		}
+		for _, m := range b.Metas {
+			if err := hashPassword(&m.Password); err != nil {
+				return err
+			}
+			if err := restore(tx, &m, &m.ID, !replace, "path = ?", m.Path); err != nil {
+				return errors.WithMessagef(err, "failed restore meta [%s]", m.Path)
+			}
+		}
+		for _, s := range b.Storages {
+			if err := restore(tx, &s, &s.ID, !replace, "mount_path = ?", s.MountPath); err != nil {
+				return errors.WithMessagef(err, "failed restore storage [%s]", s.MountPath)
+			}
+		}
+		for _, item := range b.Settings {
+			if err := tx.Save(&item).Error; err != nil {
+				return errors.Wrapf(err, "failed save setting [%s]", item.Key)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		resetCaches()
+		return err
+	}
+	// the hooks change the runtime config, so they run only if the settings are committed
+	for i := range b.Settings {
+		if _, err := HandleSettingItem(&b.Settings[i]); err != nil {
+			log.Errorf("failed handle setting [%s]: %+v", b.Settings[i].Key, err)
+		}
+	}
+	resetCaches()
+	return nil
+}
+
+// restore creates the record with a new id, or updates the existing one found by query if merge
+func restore(tx *gorm.DB, record interface{}, id *uint, merge bool, query string, args ...interface{}) error {
+	*id = 0
+	if merge {
+		var existing struct{ ID uint }
+		err := tx.Model(record).Select("id").Where(query, args...).Take(&existing).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.WithStack(err)
+		}
+		if err == nil {
+			*id = existing.ID
+			return errors.WithStack(tx.Save(record).Error)
+		}
+	}
+	return errors.WithStack(tx.Create(record).Error)
+}
+
+// resetCaches clears the caches of the restored data
+func resetCaches() {
+	userCache.Clear()
+	metaCache.Clear()
+	admin, guest = nil, nil
+	resetACL()
+	settingsUpdate()
+}
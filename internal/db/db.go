@@ -12,7 +12,7 @@ var db *gorm.DB
 
 func Init(d *gorm.DB) {
 	db = d
-	err := AutoMigrate(new(model.Storage), new(model.User), new(model.Meta), new(model.SettingItem), new(model.SearchNode))
+	err := AutoMigrate(new(model.Storage), new(model.User), new(model.Meta), new(model.SettingItem), new(model.SearchNode), new(model.TaskItem), new(model.Share), new(model.Group), new(model.ACLRule), new(model.Token), new(model.AuditLog), new(model.Webhook), new(model.WebhookDelivery), new(model.WebdavLock), new(model.WebdavProp))
 	if err != nil {
 		log.Fatalf("failed migrate database: %s", err.Error())
 	}
@@ -0,0 +1,77 @@
+package db
+
+import (
+	"fmt"
+	stdpath "path"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// CreateWebdavLock creates the lock if check allows the locks around its root,
+// they are read and the lock is created in the same transaction
+func CreateWebdavLock(l *model.WebdavLock, check func(locks []model.WebdavLock) error) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		locks, err := getWebdavLocksAround(tx, l.Root)
+		if err != nil {
+			return err
+		}
+		if err := check(locks); err != nil {
+			return err
+		}
+		return tx.Create(l).Error
+	}))
+}
+
+func GetWebdavLock(token string) (*model.WebdavLock, error) {
+	var l model.WebdavLock
+	if err := db.Where(fmt.Sprintf("%s = ?", columnName("token")), token).Find(&l).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get webdav lock")
+	}
+	if l.Token == "" {
+		return nil, errors.WithStack(errs.LockNotFound)
+	}
+	return &l, nil
+}
+
+func UpdateWebdavLock(l *model.WebdavLock) error {
+	return errors.WithStack(db.Save(l).Error)
+}
+
+func DeleteWebdavLock(token string) error {
+	return errors.WithStack(db.Where(fmt.Sprintf("%s = ?", columnName("token")), token).Delete(&model.WebdavLock{}).Error)
+}
+
+// DeleteExpiredWebdavLocks deletes the locks expired at now except the excluded ones
+func DeleteExpiredWebdavLocks(now int64, excluded []string) error {
+	tx := db.Where(fmt.Sprintf("%s >= 0 AND %s <= ?", columnName("duration"), columnName("expiry")), now)
+	if len(excluded) > 0 {
+		tx = tx.Where(fmt.Sprintf("%s NOT IN ?", columnName("token")), excluded)
+	}
+	return errors.WithStack(tx.Delete(&model.WebdavLock{}).Error)
+}
+
+// GetWebdavLocksAround returns the locks on the path, its ancestors and its descendants
+func GetWebdavLocksAround(path string) ([]model.WebdavLock, error) {
+	return getWebdavLocksAround(db, path)
+}
+
+func getWebdavLocksAround(tx *gorm.DB, path string) ([]model.WebdavLock, error) {
+	ancestors := []string{path}
+	for p := path; p != "/"; {
+		p = stdpath.Dir(p)
+		ancestors = append(ancestors, p)
+	}
+	prefix := path + "/"
+	if path == "/" {
+		prefix = "/"
+	}
+	var locks []model.WebdavLock
+	if err := tx.Where(fmt.Sprintf("%s IN ? OR %s LIKE ? ESCAPE '!'", columnName("root"), columnName("root")),
+		ancestors, escapeLike(prefix)+"%").Find(&locks).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find webdav locks")
+	}
+	return locks, nil
+}
@@ -58,6 +58,9 @@ func GetMetaById(id uint) (*model.Meta, error) {
 }
 
 func CreateMeta(u *model.Meta) error {
+	if err := hashPassword(&u.Password); err != nil {
+		return err
+	}
 	return errors.WithStack(db.Create(u).Error)
 }
 
@@ -67,6 +70,9 @@ func UpdateMeta(u *model.Meta) error {
 		return err
 	}
 	metaCache.Del(old.Path)
+	if err := hashPassword(&u.Password); err != nil {
+		return err
+	}
 	return errors.WithStack(db.Save(u).Error)
 }
 
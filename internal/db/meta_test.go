@@ -3,6 +3,7 @@ package db
 import (
 	"testing"
 
+	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/pkg/errors"
@@ -11,6 +12,7 @@ import (
 )
 
 func init() {
+	conf.Conf = conf.DefaultConfig()
 	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
 	if err != nil {
 		panic("failed to connect database")
@@ -0,0 +1,50 @@
+package db
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// hashPassword replaces the plaintext password with its hash,
+// an empty or already hashed password is kept as is.
+func hashPassword(password *string) error {
+	if *password == "" || utils.IsHashedPassword(*password) {
+		return nil
+	}
+	hash, err := utils.HashPassword(*password)
+	if err != nil {
+		return errors.Wrapf(err, "failed hash password")
+	}
+	*password = hash
+	return nil
+}
+
+// MigratePasswords hashes the plaintext passwords of users and metas stored by old versions
+func MigratePasswords() error {
+	var users []model.User
+	if err := db.Find(&users).Error; err != nil {
+		return errors.Wrapf(err, "failed get users")
+	}
+	for i := range users {
+		if users[i].Password == "" || utils.IsHashedPassword(users[i].Password) {
+			continue
+		}
+		if err := UpdateUser(&users[i]); err != nil {
+			return errors.WithMessagef(err, "failed migrate password of user [%s]", users[i].Username)
+		}
+	}
+	var metas []model.Meta
+	if err := db.Find(&metas).Error; err != nil {
+		return errors.Wrapf(err, "failed get metas")
+	}
+	for i := range metas {
+		if metas[i].Password == "" || utils.IsHashedPassword(metas[i].Password) {
+			continue
+		}
+		if err := UpdateMeta(&metas[i]); err != nil {
+			return errors.WithMessagef(err, "failed migrate password of meta [%s]", metas[i].Path)
+		}
+	}
+	return nil
+}
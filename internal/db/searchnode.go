@@ -8,6 +8,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
@@ -52,17 +53,81 @@ func GetSearchNodesByParent(parent string) ([]model.SearchNode, error) {
 	return nodes, nil
 }
 
+// globToLike converts the glob to the pattern of LIKE with the escape char !,
+// which has no special meaning in the string literal of all databases
+func globToLike(glob string) string {
+	var b strings.Builder
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteRune('%')
+		case '?':
+			b.WriteRune('_')
+		case '%', '_', '!':
+			b.WriteRune('!')
+			b.WriteRune(r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// escapeLike escapes the special chars of LIKE with the escape char !
+func escapeLike(s string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(s)
+}
+
+// whereFilters applies the filters of req except the regex of name
+func whereFilters(req model.SearchReq) *gorm.DB {
+	tx := db.Where("1 = 1")
+	switch req.Mode {
+	case model.MatchKeywords:
+		for _, keyword := range strings.Split(req.Keywords, " ") {
+			tx = tx.Where(fmt.Sprintf("%s LIKE ?", columnName("name")), fmt.Sprintf("%%%s%%", keyword))
+		}
+	case model.MatchGlob:
+		tx = tx.Where(fmt.Sprintf("%s LIKE ? ESCAPE '!'", columnName("name")), globToLike(req.Keywords))
+	}
+	switch req.Scope {
+	case model.ScopeFolders:
+		tx = tx.Where(fmt.Sprintf("%s = ?", columnName("is_dir")), true)
+	case model.ScopeFiles:
+		tx = tx.Where(fmt.Sprintf("%s = ?", columnName("is_dir")), false)
+	}
+	if len(req.Types) > 0 {
+		tx = tx.Where(fmt.Sprintf("%s IN ?", columnName("file_type")), req.Types)
+	}
+	if req.MinSize > 0 {
+		tx = tx.Where(fmt.Sprintf("%s >= ?", columnName("size")), req.MinSize)
+	}
+	if req.MaxSize > 0 {
+		tx = tx.Where(fmt.Sprintf("%s <= ?", columnName("size")), req.MaxSize)
+	}
+	if !req.ModifiedAfter.IsZero() {
+		tx = tx.Where(fmt.Sprintf("%s >= ?", columnName("modified")), req.ModifiedAfter)
+	}
+	if !req.ModifiedBefore.IsZero() {
+		tx = tx.Where(fmt.Sprintf("%s <= ?", columnName("modified")), req.ModifiedBefore)
+	}
+	return tx
+}
+
 func SearchNode(req model.SearchReq) ([]model.SearchNode, int64, error) {
-	keywordsClause := db.Where("1 = 1")
-	for _, keyword := range strings.Split(req.Keywords, " ") {
-		keywordsClause = keywordsClause.Where(
-			fmt.Sprintf("%s LIKE ?", columnName("name")),
-			fmt.Sprintf("%%%s%%", keyword))
+	searchDB := db.Model(&model.SearchNode{}).Where(whereInParent(req.Parent)).Where(whereFilters(req))
+	if req.OrderBy != "" {
+		order := columnName(req.OrderBy)
+		if req.OrderDirection == "desc" {
+			order += " DESC"
+		}
+		searchDB = searchDB.Order(order)
+	}
+	if req.Mode == model.MatchRegex {
+		return searchNodeByRegex(searchDB, req)
 	}
-	searchDB := db.Model(&model.SearchNode{}).Where(whereInParent(req.Parent)).Where(keywordsClause)
 	var count int64
 	if err := searchDB.Count(&count).Error; err != nil {
-		return nil, 0, errors.Wrapf(err, "failed get users count")
+		return nil, 0, errors.Wrapf(err, "failed get search nodes count")
 	}
 	var files []model.SearchNode
 	if err := searchDB.Offset((req.Page - 1) * req.PerPage).Limit(req.PerPage).Find(&files).Error; err != nil {
@@ -70,3 +135,40 @@ func SearchNode(req model.SearchReq) ([]model.SearchNode, int64, error) {
 	}
 	return files, count, nil
 }
+
+const (
+	regexBatchSize = 1000
+	// maxRegexScan limits the rows scanned by regex, the total is counted in them only
+	maxRegexScan = 100000
+)
+
+// searchNodeByRegex filters the names by regex here because it's not supported by all databases,
+// the rows are read in batches and only the ones of the page are kept
+func searchNodeByRegex(searchDB *gorm.DB, req model.SearchReq) ([]model.SearchNode, int64, error) {
+	re := req.NameRegexp()
+	// the order must be stable between batches, the path of node is unique
+	searchDB = searchDB.Order(columnName("parent")).Order(columnName("name")).Session(&gorm.Session{})
+	start := (req.Page - 1) * req.PerPage
+	var files []model.SearchNode
+	total := 0
+	for offset := 0; offset < maxRegexScan; offset += regexBatchSize {
+		var nodes []model.SearchNode
+		if err := searchDB.Offset(offset).Limit(regexBatchSize).Find(&nodes).Error; err != nil {
+			return nil, 0, errors.Wrapf(err, "failed get search nodes")
+		}
+		for _, node := range nodes {
+			if !re.MatchString(node.Name) {
+				continue
+			}
+			if total >= start && len(files) < req.PerPage {
+				files = append(files, node)
+			}
+			total++
+		}
+		if len(nodes) < regexBatchSize {
+			return files, int64(total), nil
+		}
+	}
+	log.Warnf("search by regex %s scanned the max %d nodes, the rest are skipped", req.Keywords, maxRegexScan)
+	return files, int64(total), nil
+}
@@ -0,0 +1,88 @@
+package db
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+func TestSearchNode(t *testing.T) {
+	now := time.Now()
+	nodes := []model.SearchNode{
+		{Parent: "/s", Name: "a_b.txt", Size: 3, Modified: now, FileType: conf.TEXT},
+		{Parent: "/s", Name: "axb.txt", Size: 5, Modified: now.Add(-time.Hour), FileType: conf.TEXT},
+		{Parent: "/s", Name: "dir", IsDir: true, Modified: now, FileType: conf.FOLDER},
+		{Parent: "/s/dir", Name: "movie.mp4", Size: 1000, Modified: now, FileType: conf.VIDEO},
+	}
+	if err := BatchCreateSearchNodes(&nodes); err != nil {
+		t.Fatalf("failed create search nodes: %+v", err)
+	}
+	tests := []struct {
+		name string
+		req  model.SearchReq
+		want []string
+	}{
+		{"keywords", model.SearchReq{Keywords: "txt"}, []string{"a_b.txt", "axb.txt"}},
+		{"glob escape", model.SearchReq{Mode: model.MatchGlob, Keywords: "a_b.*"}, []string{"a_b.txt"}},
+		{"regex", model.SearchReq{Mode: model.MatchRegex, Keywords: `a.b\.txt`}, []string{"a_b.txt", "axb.txt"}},
+		{"folders", model.SearchReq{Scope: model.ScopeFolders}, []string{"dir"}},
+		{"types", model.SearchReq{Types: []int{conf.VIDEO}}, []string{"movie.mp4"}},
+		{"size", model.SearchReq{MinSize: 4, MaxSize: 10}, []string{"axb.txt"}},
+		{"modified", model.SearchReq{Scope: model.ScopeFiles, ModifiedBefore: now.Add(-time.Minute)}, []string{"axb.txt"}},
+		{"order", model.SearchReq{Scope: model.ScopeFiles, OrderBy: "size", OrderDirection: "desc"}, []string{"movie.mp4", "axb.txt", "a_b.txt"}},
+	}
+	for _, tt := range tests {
+		tt.req.Parent = "/s"
+		tt.req.Page, tt.req.PerPage = 1, 10
+		if err := tt.req.Validate(); err != nil {
+			t.Fatalf("%s: invalid req: %+v", tt.name, err)
+		}
+		res, total, err := SearchNode(tt.req)
+		if err != nil {
+			t.Fatalf("%s: failed search: %+v", tt.name, err)
+		}
+		var names []string
+		for _, node := range res {
+			names = append(names, node.Name)
+		}
+		if int(total) != len(tt.want) || len(names) != len(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, names, tt.want)
+			continue
+		}
+		if tt.req.OrderBy == "" {
+			continue
+		}
+		for i := range names {
+			if names[i] != tt.want[i] {
+				t.Errorf("%s: got %v, want %v", tt.name, names, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestSearchNodeByRegexPages(t *testing.T) {
+	nodes := make([]model.SearchNode, 0, regexBatchSize+10)
+	for i := 0; i < regexBatchSize+10; i++ {
+		nodes = append(nodes, model.SearchNode{Parent: "/r", Name: fmt.Sprintf("%05d.txt", i)})
+	}
+	if err := BatchCreateSearchNodes(&nodes); err != nil {
+		t.Fatalf("failed create search nodes: %+v", err)
+	}
+	req := model.SearchReq{Parent: "/r", Mode: model.MatchRegex, Keywords: `^\d+[05]\.txt$`}
+	req.Page, req.PerPage = 101, 2
+	if err := req.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	// the matches are in both batches
+	res, total, err := SearchNode(req)
+	if err != nil {
+		t.Fatalf("failed search: %+v", err)
+	}
+	if total != int64((regexBatchSize+10)/5) || len(res) != 2 || res[0].Name != "01000.txt" || res[1].Name != "01005.txt" {
+		t.Errorf("unexpected result: %d %+v", total, res)
+	}
+}
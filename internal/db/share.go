@@ -0,0 +1,78 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils/random"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+func GetShareById(id string) (*model.Share, error) {
+	var s model.Share
+	if err := db.Where("id = ?", id).First(&s).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get share")
+	}
+	return &s, nil
+}
+
+func CreateShare(s *model.Share) error {
+	s.ID = random.SecureString(16)
+	s.Downloads = 0
+	if err := hashPassword(&s.Password); err != nil {
+		return err
+	}
+	return errors.WithStack(db.Create(s).Error)
+}
+
+// UpdateShare updates the share except its creator and downloads
+func UpdateShare(s *model.Share) error {
+	old, err := GetShareById(s.ID)
+	if err != nil {
+		return err
+	}
+	s.CreatorID, s.Creator, s.Downloads, s.CreatedAt = old.CreatorID, old.Creator, old.Downloads, old.CreatedAt
+	if err := hashPassword(&s.Password); err != nil {
+		return err
+	}
+	return errors.WithStack(db.Save(s).Error)
+}
+
+// GetShares returns the shares of creator, or all shares if creatorID is 0
+func GetShares(creatorID uint, pageIndex, pageSize int) ([]model.Share, int64, error) {
+	shareDB := db.Model(&model.Share{})
+	if creatorID != 0 {
+		shareDB = shareDB.Where(fmt.Sprintf("%s = ?", columnName("creator_id")), creatorID)
+	}
+	var count int64
+	if err := shareDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get shares count")
+	}
+	var shares []model.Share
+	if err := shareDB.Order(columnName("created_at") + " DESC").
+		Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&shares).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find shares")
+	}
+	return shares, count, nil
+}
+
+func DeleteShareById(id string) error {
+	return errors.WithStack(db.Where("id = ?", id).Delete(&model.Share{}).Error)
+}
+
+// IncreaseShareDownloads counts a download of share, it fails if the max downloads is reached
+func IncreaseShareDownloads(id string) error {
+	res := db.Model(&model.Share{}).
+		Where(fmt.Sprintf("id = ? AND (%s = 0 OR %s < %s)",
+			columnName("max_downloads"), columnName("downloads"), columnName("max_downloads")), id).
+		UpdateColumn("downloads", gorm.Expr(columnName("downloads")+" + 1"))
+	if res.Error != nil {
+		return errors.Wrapf(res.Error, "failed increase downloads of share")
+	}
+	if res.RowsAffected == 0 {
+		return errors.WithStack(errs.ShareDownloadsReached)
+	}
+	return nil
+}
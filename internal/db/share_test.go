@@ -0,0 +1,35 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func TestIncreaseShareDownloads(t *testing.T) {
+	share := model.Share{Path: "/a", MaxDownloads: 2, Password: "pwd"}
+	if err := CreateShare(&share); err != nil {
+		t.Fatalf("failed create share: %+v", err)
+	}
+	if !share.ValidatePassword("pwd") || share.ValidatePassword("wrong") {
+		t.Errorf("the password of share is not hashed correctly")
+	}
+	for i := 0; i < 2; i++ {
+		if err := IncreaseShareDownloads(share.ID); err != nil {
+			t.Fatalf("failed increase downloads: %+v", err)
+		}
+	}
+	err := IncreaseShareDownloads(share.ID)
+	if !errors.Is(errors.Cause(err), errs.ShareDownloadsReached) {
+		t.Errorf("expect ShareDownloadsReached, got %+v", err)
+	}
+	s, err := GetShareById(share.ID)
+	if err != nil {
+		t.Fatalf("failed get share: %+v", err)
+	}
+	if s.Downloads != 2 || !s.ReachedMaxDownloads() {
+		t.Errorf("expect 2 downloads, got %d", s.Downloads)
+	}
+}
@@ -58,3 +58,12 @@ func GetEnabledStorages() ([]model.Storage, error) {
 	}
 	return storages, nil
 }
+
+// GetStorageByMountPath Get Storage by mount path
+func GetStorageByMountPath(mountPath string) (*model.Storage, error) {
+	var storage model.Storage
+	if err := db.Where(fmt.Sprintf("%s = ?", columnName("mount_path")), mountPath).First(&storage).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &storage, nil
+}
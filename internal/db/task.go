@@ -0,0 +1,30 @@
+package db
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// SaveTaskItem insert or update the task item by kind and tid
+func SaveTaskItem(item *model.TaskItem) error {
+	var old model.TaskItem
+	err := db.Where(model.TaskItem{Kind: item.Kind, Tid: item.Tid}).First(&old).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.Wrapf(err, "failed get old task item")
+	}
+	item.ID = old.ID
+	return errors.WithStack(db.Save(item).Error)
+}
+
+func DeleteTaskItem(kind, tid string) error {
+	return errors.WithStack(db.Where(model.TaskItem{Kind: kind, Tid: tid}).Delete(&model.TaskItem{}).Error)
+}
+
+func GetTaskItemsByKind(kind string) ([]model.TaskItem, error) {
+	var items []model.TaskItem
+	if err := db.Where(model.TaskItem{Kind: kind}).Order(columnName("id")).Find(&items).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return items, nil
+}
@@ -0,0 +1,80 @@
+package db
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils/random"
+	"github.com/pkg/errors"
+)
+
+// the last used time of token is saved at most once in this interval
+const tokenTouchInterval = time.Minute
+
+func hashToken(token string) string {
+	h := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(h[:])
+}
+
+// CreateToken generates a new token for t.UserID and returns it, only its hash is saved
+func CreateToken(t *model.Token) (string, error) {
+	token := model.TokenPrefix + random.SecureString(40)
+	t.ID = 0
+	t.Hash = hashToken(token)
+	t.Hint = token[:len(model.TokenPrefix)+4]
+	t.LastUsedAt = nil
+	if err := db.Create(t).Error; err != nil {
+		return "", errors.Wrapf(err, "failed create token")
+	}
+	return token, nil
+}
+
+// GetTokens returns the tokens of user
+func GetTokens(userID uint) ([]model.Token, error) {
+	var tokens []model.Token
+	if err := db.Where(fmt.Sprintf("%s = ?", columnName("user_id")), userID).
+		Order(columnName("created_at") + " DESC").Find(&tokens).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find tokens")
+	}
+	return tokens, nil
+}
+
+// DeleteToken revokes the token of user
+func DeleteToken(userID, id uint) error {
+	res := db.Where(fmt.Sprintf("%s = ?", columnName("user_id")), userID).Delete(&model.Token{}, id)
+	if res.Error != nil {
+		return errors.WithStack(res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return errors.WithStack(errs.TokenNotFound)
+	}
+	return nil
+}
+
+// GetUserByToken returns a copy of the user of token with the token attached
+func GetUserByToken(token string) (*model.User, error) {
+	var t model.Token
+	if err := db.Where(fmt.Sprintf("%s = ?", columnName("hash")), hashToken(token)).First(&t).Error; err != nil {
+		return nil, errors.WithStack(errs.TokenNotFound)
+	}
+	if t.IsExpired() {
+		return nil, errors.WithStack(errs.TokenExpired)
+	}
+	u, err := GetUserById(t.UserID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if t.LastUsedAt == nil || now.Sub(*t.LastUsedAt) > tokenTouchInterval {
+		if err := db.Model(&t).Update("last_used_at", now).Error; err != nil {
+			return nil, errors.Wrapf(err, "failed update last used time of token")
+		}
+		t.LastUsedAt = &now
+	}
+	u.Token = &t
+	return u, nil
+}
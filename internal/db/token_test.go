@@ -0,0 +1,55 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func TestToken(t *testing.T) {
+	user := model.User{Username: "token_user", Password: "password"}
+	if err := CreateUser(&user); err != nil {
+		t.Fatalf("failed create user: %+v", err)
+	}
+	tk := model.Token{UserID: user.ID, Name: "ci", Scopes: model.ScopeRead}
+	token, err := CreateToken(&tk)
+	if err != nil {
+		t.Fatalf("failed create token: %+v", err)
+	}
+	if tk.Hash == token {
+		t.Errorf("the token is saved without hash")
+	}
+	u, err := GetUserByToken(token)
+	if err != nil {
+		t.Fatalf("failed get user by token: %+v", err)
+	}
+	if u.ID != user.ID || u.Token == nil || u.Token.LastUsedAt == nil {
+		t.Errorf("unexpected user of token: %+v", u)
+	}
+	if !u.Token.Allows(model.ActionRead, "/a") || u.Token.Allows(model.ActionWrite, "/a") {
+		t.Errorf("the read token should only allow reading")
+	}
+
+	expired := time.Now().Add(-time.Hour)
+	tk2 := model.Token{UserID: user.ID, Name: "old", Scopes: model.ScopeRead, ExpiresAt: &expired}
+	token2, err := CreateToken(&tk2)
+	if err != nil {
+		t.Fatalf("failed create token: %+v", err)
+	}
+	if _, err := GetUserByToken(token2); !errors.Is(errors.Cause(err), errs.TokenExpired) {
+		t.Errorf("expect TokenExpired, got %+v", err)
+	}
+
+	if err := DeleteToken(user.ID+1, tk.ID); err == nil {
+		t.Errorf("the token of another user is deleted")
+	}
+	if err := DeleteToken(user.ID, tk.ID); err != nil {
+		t.Fatalf("failed delete token: %+v", err)
+	}
+	if _, err := GetUserByToken(token); !errors.Is(errors.Cause(err), errs.TokenNotFound) {
+		t.Errorf("expect TokenNotFound, got %+v", err)
+	}
+}
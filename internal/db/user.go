@@ -67,6 +67,9 @@ func GetUserById(id uint) (*model.User, error) {
 }
 
 func CreateUser(u *model.User) error {
+	if err := hashPassword(&u.Password); err != nil {
+		return err
+	}
 	return errors.WithStack(db.Create(u).Error)
 }
 
@@ -82,6 +85,9 @@ func UpdateUser(u *model.User) error {
 	if u.IsAdmin() {
 		admin = nil
 	}
+	if err := hashPassword(&u.Password); err != nil {
+		return err
+	}
 	return errors.WithStack(db.Save(u).Error)
 }
 
@@ -120,5 +126,12 @@ func DeleteUserById(id uint) error {
 		return errors.WithStack(errs.DeleteAdminOrGuest)
 	}
 	userCache.Del(old.Username)
+	if err := db.Where("user_id = ?", id).Delete(&model.ACLRule{}).Error; err != nil {
+		return errors.Wrapf(err, "failed delete rules of user")
+	}
+	resetACL()
+	if err := db.Where("user_id = ?", id).Delete(&model.Token{}).Error; err != nil {
+		return errors.Wrapf(err, "failed delete tokens of user")
+	}
 	return errors.WithStack(db.Delete(&model.User{}, id).Error)
 }
@@ -0,0 +1,108 @@
+package db
+
+import (
+	"fmt"
+	stdpath "path"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// whereTree matches the path and its descendants
+func whereTree(tx *gorm.DB, path string) *gorm.DB {
+	return tx.Where(fmt.Sprintf("(%s = ? OR %s LIKE ? ESCAPE '!')", columnName("path"), columnName("path")),
+		path, escapeLike(strings.TrimSuffix(path, "/")+"/")+"%")
+}
+
+// GetWebdavPropsByParent returns the props of the objects in the dir
+func GetWebdavPropsByParent(parent string) ([]model.WebdavProp, error) {
+	var props []model.WebdavProp
+	if err := db.Where(fmt.Sprintf("%s = ?", columnName("parent")), parent).Find(&props).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find webdav props")
+	}
+	return props, nil
+}
+
+// PatchWebdavProps sets and removes the props of path, all or none of them are applied
+func PatchWebdavProps(path string, set, remove []model.WebdavProp) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		for _, p := range append(set, remove...) {
+			if err := tx.Where(fmt.Sprintf("%s = ? AND %s = ? AND %s = ?", columnName("path"), columnName("space"), columnName("local")),
+				path, p.Space, p.Local).Delete(&model.WebdavProp{}).Error; err != nil {
+				return err
+			}
+		}
+		for _, p := range set {
+			p.ID, p.Path, p.Parent = 0, path, stdpath.Dir(path)
+			if err := tx.Create(&p).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
+// MoveWebdavProps moves the props of src and its descendants to dst, the props at dst are replaced
+func MoveWebdavProps(src, dst string) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		var props []model.WebdavProp
+		if err := whereTree(tx, src).Find(&props).Error; err != nil {
+			return err
+		}
+		if len(props) == 0 {
+			return nil
+		}
+		if err := whereTree(tx, dst).Delete(&model.WebdavProp{}).Error; err != nil {
+			return err
+		}
+		for _, p := range props {
+			p.Path = dst + strings.TrimPrefix(p.Path, src)
+			p.Parent = stdpath.Dir(p.Path)
+			if err := tx.Save(&p).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
+// CopyWebdavProps copies the props of src to dst, with the props of its descendants if recursive.
+// The props at dst are replaced.
+func CopyWebdavProps(src, dst string, recursive bool) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		var props []model.WebdavProp
+		var err error
+		if recursive {
+			err = whereTree(tx, src).Find(&props).Error
+		} else {
+			err = tx.Where(fmt.Sprintf("%s = ?", columnName("path")), src).Find(&props).Error
+		}
+		if err != nil || len(props) == 0 {
+			return err
+		}
+		if recursive {
+			err = whereTree(tx, dst).Delete(&model.WebdavProp{}).Error
+		} else {
+			err = tx.Where(fmt.Sprintf("%s = ?", columnName("path")), dst).Delete(&model.WebdavProp{}).Error
+		}
+		if err != nil {
+			return err
+		}
+		for _, p := range props {
+			p.ID = 0
+			p.Path = dst + strings.TrimPrefix(p.Path, src)
+			p.Parent = stdpath.Dir(p.Path)
+			if err := tx.Create(&p).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
+// DeleteWebdavProps deletes the props of path and its descendants
+func DeleteWebdavProps(path string) error {
+	return errors.WithStack(whereTree(db, path).Delete(&model.WebdavProp{}).Error)
+}
@@ -0,0 +1,67 @@
+package db
+
+import (
+	stdpath "path"
+	"sort"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+// propPaths returns the sorted paths with props under root
+func propPaths(t *testing.T, root string) []string {
+	var props []model.WebdavProp
+	if err := whereTree(db, root).Find(&props).Error; err != nil {
+		t.Fatalf("failed find props: %+v", err)
+	}
+	var paths []string
+	for _, p := range props {
+		if p.Parent != stdpath.Dir(p.Path) {
+			t.Errorf("wrong parent %s of %s", p.Parent, p.Path)
+		}
+		paths = append(paths, p.Path+":"+p.Local+"="+p.InnerXML)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func TestWebdavProps(t *testing.T) {
+	tag := func(v string) []model.WebdavProp {
+		return []model.WebdavProp{{Space: "urn:test", Local: "tag", InnerXML: v}}
+	}
+	for path, v := range map[string]string{"/props/a": "a", "/props/a/b": "b", "/props/a_c": "c"} {
+		if err := PatchWebdavProps(path, tag(v), nil); err != nil {
+			t.Fatalf("failed patch props: %+v", err)
+		}
+	}
+	if err := PatchWebdavProps("/props/a", tag("a2"), nil); err != nil {
+		t.Fatalf("failed patch props: %+v", err)
+	}
+	if err := CopyWebdavProps("/props/a", "/props/d", true); err != nil {
+		t.Fatalf("failed copy props: %+v", err)
+	}
+	if err := MoveWebdavProps("/props/a", "/props/e"); err != nil {
+		t.Fatalf("failed move props: %+v", err)
+	}
+	if err := DeleteWebdavProps("/props/d/b"); err != nil {
+		t.Fatalf("failed delete props: %+v", err)
+	}
+	if err := PatchWebdavProps("/props/a_c", nil, tag("")); err != nil {
+		t.Fatalf("failed remove props: %+v", err)
+	}
+	got := propPaths(t, "/props")
+	want := []string{"/props/d:tag=a2", "/props/e/b:tag=b", "/props/e:tag=a2"}
+	sort.Strings(want)
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+	children, err := GetWebdavPropsByParent("/props/e")
+	if err != nil || len(children) != 1 || children[0].Path != "/props/e/b" {
+		t.Errorf("unexpected children props: %+v %+v", children, err)
+	}
+}
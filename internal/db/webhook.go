@@ -0,0 +1,109 @@
+package db
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+// the webhooks are read on every event, so they are cached
+var (
+	webhooksMu sync.RWMutex
+	webhooks   []model.Webhook
+)
+
+func resetWebhooks() {
+	webhooksMu.Lock()
+	webhooks = nil
+	webhooksMu.Unlock()
+}
+
+// GetEnabledWebhooks returns the cached enabled webhooks
+func GetEnabledWebhooks() ([]model.Webhook, error) {
+	webhooksMu.RLock()
+	cached := webhooks
+	webhooksMu.RUnlock()
+	if cached != nil {
+		return cached, nil
+	}
+	webhooksMu.Lock()
+	defer webhooksMu.Unlock()
+	res := make([]model.Webhook, 0)
+	if err := db.Where(fmt.Sprintf("%s = ?", columnName("disabled")), false).Find(&res).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find webhooks")
+	}
+	webhooks = res
+	return res, nil
+}
+
+func GetWebhooks(pageIndex, pageSize int) ([]model.Webhook, int64, error) {
+	webhookDB := db.Model(&model.Webhook{})
+	var count int64
+	if err := webhookDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get webhooks count")
+	}
+	var res []model.Webhook
+	if err := webhookDB.Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&res).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find webhooks")
+	}
+	return res, count, nil
+}
+
+func GetWebhookById(id uint) (*model.Webhook, error) {
+	var w model.Webhook
+	if err := db.First(&w, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get webhook")
+	}
+	return &w, nil
+}
+
+func CreateWebhook(w *model.Webhook) error {
+	defer resetWebhooks()
+	return errors.WithStack(db.Create(w).Error)
+}
+
+func UpdateWebhook(w *model.Webhook) error {
+	defer resetWebhooks()
+	return errors.WithStack(db.Save(w).Error)
+}
+
+// DeleteWebhookById deletes the webhook with its deliveries
+func DeleteWebhookById(id uint) error {
+	defer resetWebhooks()
+	if err := db.Where(fmt.Sprintf("%s = ?", columnName("webhook_id")), id).Delete(&model.WebhookDelivery{}).Error; err != nil {
+		return errors.Wrapf(err, "failed delete deliveries of webhook")
+	}
+	return errors.WithStack(db.Delete(&model.Webhook{}, id).Error)
+}
+
+func CreateWebhookDelivery(d *model.WebhookDelivery) error {
+	return errors.WithStack(db.Create(d).Error)
+}
+
+func UpdateWebhookDelivery(d *model.WebhookDelivery) error {
+	return errors.WithStack(db.Save(d).Error)
+}
+
+// GetWebhookDeliveries returns the deliveries of the webhook, the latest first
+func GetWebhookDeliveries(webhookID uint, pageIndex, pageSize int) ([]model.WebhookDelivery, int64, error) {
+	deliveryDB := db.Model(&model.WebhookDelivery{}).Where(fmt.Sprintf("%s = ?", columnName("webhook_id")), webhookID)
+	var count int64
+	if err := deliveryDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get deliveries count")
+	}
+	var res []model.WebhookDelivery
+	if err := deliveryDB.Order(columnName("id") + " DESC").
+		Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&res).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find deliveries")
+	}
+	return res, count, nil
+}
+
+// DeleteWebhookDeliveriesBefore deletes the deliveries created before t
+func DeleteWebhookDeliveriesBefore(t time.Time) (int64, error) {
+	res := db.Where(fmt.Sprintf("%s < ?", columnName("created_at")), t).Delete(&model.WebhookDelivery{})
+	return res.RowsAffected, errors.WithStack(res.Error)
+}
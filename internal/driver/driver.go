@@ -29,6 +29,11 @@ type Other interface {
 	Other(ctx context.Context, args model.OtherArgs) (interface{}, error)
 }
 
+type Quota interface {
+	// GetSpace get the total and used bytes of the storage
+	GetSpace(ctx context.Context) (total, used int64, err error)
+}
+
 type Reader interface {
 	// List files in the path
 	// if identify files by path, need to set ID with path,like path.Join(dir.GetID(), obj.GetName())
@@ -9,8 +9,8 @@ var (
 	NotSupport   = errors.New("not support")
 	RelativePath = errors.New("access using relative path is not allowed")
 
-	MoveBetweenTwoStorages = errors.New("can't move files between two storages, try to copy")
-	UploadNotSupported     = errors.New("upload not supported")
+	RenameBetweenTwoStorages = errors.New("can't rename files while moving between two storages")
+	UploadNotSupported       = errors.New("upload not supported")
 
 	MetaNotFound = errors.New("meta not found")
 )
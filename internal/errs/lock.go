@@ -0,0 +1,8 @@
+package errs
+
+import "errors"
+
+var (
+	LockNotFound = errors.New("lock not found")
+	ObjectLocked = errors.New("object is locked by webdav")
+)
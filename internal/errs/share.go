@@ -0,0 +1,8 @@
+package errs
+
+import "errors"
+
+var (
+	ShareExpired          = errors.New("share is expired")
+	ShareDownloadsReached = errors.New("share has reached the max downloads")
+)
@@ -0,0 +1,8 @@
+package errs
+
+import "errors"
+
+var (
+	TokenNotFound = errors.New("token not found")
+	TokenExpired  = errors.New("token is expired")
+)
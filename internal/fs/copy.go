@@ -5,14 +5,15 @@ import (
 	"fmt"
 	stdpath "path"
 	"sync/atomic"
+	"time"
 
+	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/pkg/task"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/pkg/errors"
-	log "github.com/sirupsen/logrus"
 )
 
 var CopyTaskManager = task.NewTaskManager(3, func(tid *uint64) {
@@ -35,59 +36,158 @@ func _copy(ctx context.Context, srcObjPath, dstDirPath string) (bool, error) {
 		return false, op.Copy(ctx, srcStorage, srcObjActualPath, dstDirActualPath)
 	}
 	// not in the same storage
-	CopyTaskManager.Submit(task.WithCancelCtx(&task.Task[uint64]{
-		Name: fmt.Sprintf("copy [%s](%s) to [%s](%s)", srcStorage.GetStorage().MountPath, srcObjActualPath, dstStorage.GetStorage().MountPath, dstDirActualPath),
-		Func: func(task *task.Task[uint64]) error {
-			return copyBetween2Storages(task, srcStorage, dstStorage, srcObjActualPath, dstDirActualPath)
-		},
-	}))
+	CopyTaskManager.Submit(newCopyTask(copyArgs{
+		SrcObjPath: stdpath.Join(srcStorage.GetStorage().MountPath, srcObjActualPath),
+		DstDirPath: stdpath.Join(dstStorage.GetStorage().MountPath, dstDirActualPath),
+	}, srcStorage, dstStorage, srcObjActualPath, dstDirActualPath))
 	return true, nil
 }
 
+// copyArgs is persisted with the copy task, the paths are full paths with mount path
+type copyArgs struct {
+	SrcObjPath string `json:"src_obj_path"`
+	DstDirPath string `json:"dst_dir_path"`
+	File       bool   `json:"file"` // the src object is known to be a file
+}
+
+func newCopyTask(args copyArgs, srcStorage, dstStorage driver.Driver, srcObjPath, dstDirPath string) *task.Task[uint64] {
+	t := task.WithCancelCtx(&task.Task[uint64]{
+		Name: fmt.Sprintf("copy [%s](%s) to [%s](%s)", srcStorage.GetStorage().MountPath, srcObjPath, dstStorage.GetStorage().MountPath, dstDirPath),
+		Func: func(t *task.Task[uint64]) error {
+			if args.File {
+				return copyFileBetween2Storages(t, srcStorage, dstStorage, srcObjPath, dstDirPath)
+			}
+			return copyBetween2Storages(t, srcStorage, dstStorage, srcObjPath, dstDirPath)
+		},
+	})
+	return t.SetArgs(args)
+}
+
+// ResumeCopyTask rebuild the func of a copy task restored from store
+func ResumeCopyTask(info *task.Info) task.Func[uint64] {
+	var args copyArgs
+	if err := utils.Json.UnmarshalFromString(info.Args, &args); err != nil || args.SrcObjPath == "" {
+		return nil
+	}
+	return func(t *task.Task[uint64]) error {
+		// storages are loaded asynchronously while booting
+		for !conf.StoragesLoaded {
+			if utils.IsCanceled(t.Ctx) {
+				return nil
+			}
+			time.Sleep(time.Second)
+		}
+		srcStorage, srcObjActualPath, err := op.GetStorageAndActualPath(args.SrcObjPath)
+		if err != nil {
+			return errors.WithMessage(err, "failed get src storage")
+		}
+		dstStorage, dstDirActualPath, err := op.GetStorageAndActualPath(args.DstDirPath)
+		if err != nil {
+			return errors.WithMessage(err, "failed get dst storage")
+		}
+		if args.File {
+			return copyFileBetween2Storages(t, srcStorage, dstStorage, srcObjActualPath, dstDirActualPath)
+		}
+		return copyBetween2Storages(t, srcStorage, dstStorage, srcObjActualPath, dstDirActualPath)
+	}
+}
+
 func copyBetween2Storages(t *task.Task[uint64], srcStorage, dstStorage driver.Driver, srcObjPath, dstDirPath string) error {
 	t.SetStatus("getting src object")
 	srcObj, err := op.Get(t.Ctx, srcStorage, srcObjPath)
 	if err != nil {
 		return errors.WithMessagef(err, "failed get src [%s] file", srcObjPath)
 	}
-	if srcObj.IsDir() {
-		t.SetStatus("src object is dir, listing objs")
-		objs, err := op.List(t.Ctx, srcStorage, srcObjPath, model.ListArgs{})
-		if err != nil {
-			return errors.WithMessagef(err, "failed list src [%s] objs", srcObjPath)
+	if !srcObj.IsDir() {
+		t.SetSize(srcObj.GetSize())
+		return copyFileBetween2Storages(t, srcStorage, dstStorage, srcObjPath, dstDirPath)
+	}
+	copyProps(op.FullPath(srcStorage, srcObjPath), op.FullPath(dstStorage, stdpath.Join(dstDirPath, srcObj.GetName())), false)
+	t.SetStatus("src object is dir, listing objs")
+	objs, err := op.List(t.Ctx, srcStorage, srcObjPath, model.ListArgs{})
+	if err != nil {
+		return errors.WithMessagef(err, "failed list src [%s] objs", srcObjPath)
+	}
+	// the objs are copied by the children of the task,
+	// so they are canceled with the task and their progress is aggregated to it.
+	srcMountPath, dstMountPath := srcStorage.GetStorage().MountPath, dstStorage.GetStorage().MountPath
+	for _, obj := range objs {
+		if utils.IsCanceled(t.Ctx) {
+			return nil
 		}
-		for _, obj := range objs {
-			if utils.IsCanceled(t.Ctx) {
-				return nil
-			}
-			srcObjPath := stdpath.Join(srcObjPath, obj.GetName())
-			dstObjPath := stdpath.Join(dstDirPath, srcObj.GetName())
-			CopyTaskManager.Submit(task.WithCancelCtx(&task.Task[uint64]{
-				Name: fmt.Sprintf("copy [%s](%s) to [%s](%s)", srcStorage.GetStorage().MountPath, srcObjPath, dstStorage.GetStorage().MountPath, dstObjPath),
-				Func: func(t *task.Task[uint64]) error {
-					return copyBetween2Storages(t, srcStorage, dstStorage, srcObjPath, dstObjPath)
-				},
-			}))
+		srcObjPath := stdpath.Join(srcObjPath, obj.GetName())
+		dstObjPath := stdpath.Join(dstDirPath, srcObj.GetName())
+		child := newCopyTask(copyArgs{
+			SrcObjPath: stdpath.Join(srcMountPath, srcObjPath),
+			DstDirPath: stdpath.Join(dstMountPath, dstObjPath),
+			File:       !obj.IsDir(),
+		}, srcStorage, dstStorage, srcObjPath, dstObjPath)
+		if !obj.IsDir() {
+			child.SetSize(obj.GetSize())
 		}
-	} else {
-		CopyTaskManager.Submit(task.WithCancelCtx(&task.Task[uint64]{
-			Name: fmt.Sprintf("copy [%s](%s) to [%s](%s)", srcStorage.GetStorage().MountPath, srcObjPath, dstStorage.GetStorage().MountPath, dstDirPath),
-			Func: func(t *task.Task[uint64]) error {
-				err := copyFileBetween2Storages(t, srcStorage, dstStorage, srcObjPath, dstDirPath)
-				log.Debugf("copy file between storages: %+v", err)
-				return err
-			},
-		}))
+		CopyTaskManager.SubmitChild(t, child)
 	}
+	t.SetStatus(fmt.Sprintf("%d objs submitted", len(objs)))
 	return nil
 }
 
+// copyFileBetween2Storages copies the file and verifies it at dst,
+// the copy is skipped if the identical file already exists at dst.
 func copyFileBetween2Storages(tsk *task.Task[uint64], srcStorage, dstStorage driver.Driver, srcFilePath, dstDirPath string) error {
 	srcFile, err := op.Get(tsk.Ctx, srcStorage, srcFilePath)
 	if err != nil {
 		return errors.WithMessagef(err, "failed get src [%s] file", srcFilePath)
 	}
-	link, _, err := op.Link(tsk.Ctx, srcStorage, srcFilePath, model.LinkArgs{})
+	if identicalFileExists(tsk.Ctx, dstStorage, dstDirPath, srcFile) {
+		tsk.SetStatus("identical file exists at dst, skipped")
+		return nil
+	}
+	err = putFileBetween2Storages(tsk.Ctx, srcStorage, dstStorage, srcFilePath, dstDirPath, tsk.SetProgress)
+	if err != nil {
+		return err
+	}
+	tsk.SetStatus("verifying dst file")
+	if err := verifyDstFile(tsk.Ctx, dstStorage, dstDirPath, srcFile); err != nil {
+		return err
+	}
+	copyProps(op.FullPath(srcStorage, srcFilePath), op.FullPath(dstStorage, stdpath.Join(dstDirPath, srcFile.GetName())), false)
+	return nil
+}
+
+// identicalFileExists checks whether a file with the same size and hashes of src file exists in dst dir
+func identicalFileExists(ctx context.Context, dstStorage driver.Driver, dstDirPath string, srcFile model.Obj) bool {
+	dstFile, err := op.Get(ctx, dstStorage, stdpath.Join(dstDirPath, srcFile.GetName()))
+	if err != nil || dstFile.IsDir() || dstFile.GetSize() != srcFile.GetSize() {
+		return false
+	}
+	ok, err := utils.CompareHash(model.GetHash(srcFile), model.GetHash(dstFile))
+	return ok && err == nil
+}
+
+// verifyDstFile checks the file is written at dst with the same size,
+// and the same hashes if both storages provide a hash of the same type.
+func verifyDstFile(ctx context.Context, dstStorage driver.Driver, dstDirPath string, srcFile model.Obj) error {
+	op.ClearCache(dstStorage, dstDirPath)
+	dstFile, err := op.Get(ctx, dstStorage, stdpath.Join(dstDirPath, srcFile.GetName()))
+	if err != nil {
+		return errors.WithMessage(err, "failed get dst file to verify")
+	}
+	if dstFile.GetSize() != srcFile.GetSize() {
+		return errors.Errorf("size of dst file is %d, expected %d", dstFile.GetSize(), srcFile.GetSize())
+	}
+	if _, err := utils.CompareHash(model.GetHash(srcFile), model.GetHash(dstFile)); err != nil {
+		return errors.WithMessage(err, "failed verify dst file")
+	}
+	return nil
+}
+
+// putFileBetween2Storages put the src file to dst dir with a stream from its link
+func putFileBetween2Storages(ctx context.Context, srcStorage, dstStorage driver.Driver, srcFilePath, dstDirPath string, up driver.UpdateProgress) error {
+	srcFile, err := op.Get(ctx, srcStorage, srcFilePath)
+	if err != nil {
+		return errors.WithMessagef(err, "failed get src [%s] file", srcFilePath)
+	}
+	link, _, err := op.Link(ctx, srcStorage, srcFilePath, model.LinkArgs{})
 	if err != nil {
 		return errors.WithMessagef(err, "failed get [%s] link", srcFilePath)
 	}
@@ -95,5 +195,5 @@ func copyFileBetween2Storages(tsk *task.Task[uint64], srcStorage, dstStorage dri
 	if err != nil {
 		return errors.WithMessagef(err, "failed get [%s] stream", srcFilePath)
 	}
-	return op.Put(tsk.Ctx, dstStorage, dstDirPath, stream, tsk.SetProgress)
+	return op.Put(ctx, dstStorage, dstDirPath, stream, up)
 }
@@ -2,10 +2,14 @@ package fs
 
 import (
 	"context"
+	stdpath "path"
 
+	"github.com/alist-org/alist/v3/internal/audit"
 	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -40,27 +44,60 @@ func Link(ctx context.Context, path string, args model.LinkArgs) (*model.Link, m
 	return res, file, nil
 }
 
+// GetStream opens the stream of the file, the stream should be closed after read
+func GetStream(ctx context.Context, path string) (model.FileStreamer, error) {
+	res, file, err := link(ctx, path, model.LinkArgs{})
+	if err == nil {
+		var stream model.FileStreamer
+		stream, err = getFileStreamFromLink(file, res)
+		if err == nil {
+			return stream, nil
+		}
+	}
+	log.Errorf("failed get stream %s: %+v", path, err)
+	return nil, err
+}
+
+// GetRangeReader returns the function to read the file by range whatever the link is,
+// it's used by the drivers which read the files of other storages
+func GetRangeReader(ctx context.Context, path string) (model.RangeReaderFunc, model.Obj, error) {
+	res, file, err := link(ctx, path, model.LinkArgs{})
+	if err != nil {
+		log.Errorf("failed get range reader %s: %+v", path, err)
+		return nil, nil, err
+	}
+	return getRangeReaderFromLink(path, res), file, nil
+}
+
 func MakeDir(ctx context.Context, path string) error {
 	err := makeDir(ctx, path)
 	if err != nil {
 		log.Errorf("failed make dir %s: %+v", path, err)
 	}
+	audit.Log(ctx, audit.FsMkdir, path, "", err)
 	return err
 }
 
-func Move(ctx context.Context, srcPath, dstDirPath string) error {
-	err := move(ctx, srcPath, dstDirPath)
+func Move(ctx context.Context, srcPath, dstDirPath string) (bool, error) {
+	res, err := move(ctx, srcPath, dstDirPath)
 	if err != nil {
 		log.Errorf("failed move %s to %s: %+v", srcPath, dstDirPath, err)
+	} else if !res {
+		// the props are moved by the task if it's added
+		moveProps(srcPath, stdpath.Join(dstDirPath, stdpath.Base(srcPath)))
 	}
-	return err
+	audit.Log(ctx, audit.FsMove, srcPath, dstDirPath, err)
+	return res, err
 }
 
 func Copy(ctx context.Context, srcObjPath, dstDirPath string) (bool, error) {
 	res, err := _copy(ctx, srcObjPath, dstDirPath)
 	if err != nil {
 		log.Errorf("failed copy %s to %s: %+v", srcObjPath, dstDirPath, err)
+	} else if !res {
+		copyProps(srcObjPath, stdpath.Join(dstDirPath, stdpath.Base(srcObjPath)), true)
 	}
+	audit.Log(ctx, audit.FsCopy, srcObjPath, dstDirPath, err)
 	return res, err
 }
 
@@ -68,7 +105,10 @@ func Rename(ctx context.Context, srcPath, dstName string) error {
 	err := rename(ctx, srcPath, dstName)
 	if err != nil {
 		log.Errorf("failed rename %s to %s: %+v", srcPath, dstName, err)
+	} else {
+		moveProps(srcPath, stdpath.Join(stdpath.Dir(srcPath), dstName))
 	}
+	audit.Log(ctx, audit.FsRename, srcPath, stdpath.Join(stdpath.Dir(srcPath), dstName), err)
 	return err
 }
 
@@ -76,7 +116,10 @@ func Remove(ctx context.Context, path string) error {
 	err := remove(ctx, path)
 	if err != nil {
 		log.Errorf("failed remove %s: %+v", path, err)
+	} else {
+		removeProps(path)
 	}
+	audit.Log(ctx, audit.FsRemove, path, "", err)
 	return err
 }
 
@@ -85,14 +128,17 @@ func PutDirectly(ctx context.Context, dstDirPath string, file model.FileStreamer
 	if err != nil {
 		log.Errorf("failed put %s: %+v", dstDirPath, err)
 	}
+	audit.Log(ctx, audit.FsPut, "", stdpath.Join(dstDirPath, file.GetName()), err)
 	return err
 }
 
-func PutAsTask(dstDirPath string, file model.FileStreamer) error {
+// PutAsTask adds a put task, the ctx is only used to record who uploads the file
+func PutAsTask(ctx context.Context, dstDirPath string, file model.FileStreamer) error {
 	err := putAsTask(dstDirPath, file)
 	if err != nil {
 		log.Errorf("failed put %s: %+v", dstDirPath, err)
 	}
+	audit.Log(ctx, audit.FsPut, "", stdpath.Join(dstDirPath, file.GetName()), err)
 	return err
 }
 
@@ -104,6 +150,15 @@ func GetStorage(path string) (driver.Driver, error) {
 	return storageDriver, nil
 }
 
+// GetSpace get the capacity of the storage that the path is in
+func GetSpace(ctx context.Context, path string) (*model.Space, error) {
+	res, err := getSpace(ctx, path)
+	if err != nil && !errors.Is(err, errs.NotImplement) {
+		log.Errorf("failed get space %s: %+v", path, err)
+	}
+	return res, err
+}
+
 func Other(ctx context.Context, args model.FsOtherArgs) (interface{}, error) {
 	res, err := other(ctx, args)
 	if err != nil {
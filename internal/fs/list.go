@@ -2,9 +2,11 @@ package fs
 
 import (
 	"context"
+	stdpath "path"
 	"regexp"
 	"strings"
 
+	"github.com/alist-org/alist/v3/internal/authz"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/pkg/utils"
@@ -48,6 +50,7 @@ func list(ctx context.Context, path string, refresh ...bool) ([]model.Obj, error
 	if whetherHide(user, meta, path) {
 		objs = hide(objs, meta)
 	}
+	objs = filterByACL(user, path, objs)
 	// sort objs
 	if storage != nil {
 		if storage.Config().LocalSort {
@@ -60,7 +63,7 @@ func list(ctx context.Context, path string, refresh ...bool) ([]model.Obj, error
 
 func whetherHide(user *model.User, meta *model.Meta, path string) bool {
 	// if is admin, don't hide
-	if user.CanSeeHides() {
+	if authz.CanSeeHides(user) {
 		return false
 	}
 	// if meta is nil, don't hide
@@ -79,6 +82,17 @@ func whetherHide(user *model.User, meta *model.Meta, path string) bool {
 	return true
 }
 
+// filterByACL removes the objs which the user can't list by the acl rules
+func filterByACL(user *model.User, path string, objs []model.Obj) []model.Obj {
+	res := make([]model.Obj, 0, len(objs))
+	for _, obj := range objs {
+		if authz.Can(user, model.ActionList, stdpath.Join(path, obj.GetName())) {
+			res = append(res, obj)
+		}
+	}
+	return res
+}
+
 func hide(objs []model.Obj, meta *model.Meta) []model.Obj {
 	var res []model.Obj
 	deleted := make([]bool, len(objs))
@@ -0,0 +1,173 @@
+package fs
+
+import (
+	"context"
+	"fmt"
+	stdpath "path"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/task"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+var MoveTaskManager = task.NewTaskManager(3, func(tid *uint64) {
+	atomic.AddUint64(tid, 1)
+})
+
+// moveArgs is persisted with the move task, the paths are full paths with mount path
+type moveArgs struct {
+	SrcObjPath string `json:"src_obj_path"`
+	DstDirPath string `json:"dst_dir_path"`
+}
+
+func newMoveTask(srcStorage, dstStorage driver.Driver, srcObjPath, dstDirPath string) *task.Task[uint64] {
+	t := task.WithCancelCtx(&task.Task[uint64]{
+		Name: fmt.Sprintf("move [%s](%s) to [%s](%s)", srcStorage.GetStorage().MountPath, srcObjPath, dstStorage.GetStorage().MountPath, dstDirPath),
+		Func: func(t *task.Task[uint64]) error {
+			return moveBetween2Storages(t, srcStorage, dstStorage, srcObjPath, dstDirPath)
+		},
+	})
+	return t.SetArgs(moveArgs{
+		SrcObjPath: stdpath.Join(srcStorage.GetStorage().MountPath, srcObjPath),
+		DstDirPath: stdpath.Join(dstStorage.GetStorage().MountPath, dstDirPath),
+	})
+}
+
+// ResumeMoveTask rebuild the func of a move task restored from store,
+// the files moved before restart are copied again, because the src is removed only at the end.
+func ResumeMoveTask(info *task.Info) task.Func[uint64] {
+	var args moveArgs
+	if err := utils.Json.UnmarshalFromString(info.Args, &args); err != nil || args.SrcObjPath == "" {
+		return nil
+	}
+	return func(t *task.Task[uint64]) error {
+		// storages are loaded asynchronously while booting
+		for !conf.StoragesLoaded {
+			if utils.IsCanceled(t.Ctx) {
+				return nil
+			}
+			time.Sleep(time.Second)
+		}
+		srcStorage, srcObjActualPath, err := op.GetStorageAndActualPath(args.SrcObjPath)
+		if err != nil {
+			return errors.WithMessage(err, "failed get src storage")
+		}
+		dstStorage, dstDirActualPath, err := op.GetStorageAndActualPath(args.DstDirPath)
+		if err != nil {
+			return errors.WithMessage(err, "failed get dst storage")
+		}
+		return moveBetween2Storages(t, srcStorage, dstStorage, srcObjActualPath, dstDirActualPath)
+	}
+}
+
+// movingObj is an object to be moved, dstDirPath is the dir it will be put in
+type movingObj struct {
+	model.Obj
+	srcPath    string
+	dstDirPath string
+}
+
+// listMovingObjs lists the src object recursively, dirs are listed before their children
+func listMovingObjs(ctx context.Context, storage driver.Driver, obj model.Obj, srcPath, dstDirPath string) ([]movingObj, error) {
+	objs := []movingObj{{Obj: obj, srcPath: srcPath, dstDirPath: dstDirPath}}
+	if !obj.IsDir() {
+		return objs, nil
+	}
+	children, err := op.List(ctx, storage, srcPath, model.ListArgs{})
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed list src [%s] objs", srcPath)
+	}
+	for _, child := range children {
+		if utils.IsCanceled(ctx) {
+			return nil, ctx.Err()
+		}
+		childObjs, err := listMovingObjs(ctx, storage, child, stdpath.Join(srcPath, child.GetName()), stdpath.Join(dstDirPath, obj.GetName()))
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, childObjs...)
+	}
+	return objs, nil
+}
+
+// moveBetween2Storages copies every file of the src object to dst dir and verifies it,
+// the src object is removed only if all the files are moved successfully.
+func moveBetween2Storages(t *task.Task[uint64], srcStorage, dstStorage driver.Driver, srcObjPath, dstDirPath string) error {
+	t.SetStatus("getting src object")
+	srcObj, err := op.Get(t.Ctx, srcStorage, srcObjPath)
+	if err != nil {
+		return errors.WithMessagef(err, "failed get src [%s] object", srcObjPath)
+	}
+	t.SetStatus("listing src objects")
+	objs, err := listMovingObjs(t.Ctx, srcStorage, srcObj, srcObjPath, dstDirPath)
+	if err != nil {
+		return err
+	}
+	var total, done int64
+	files := 0
+	for _, obj := range objs {
+		if !obj.IsDir() {
+			total += obj.GetSize()
+			files++
+		}
+	}
+	setProgress := func(size int64) {
+		if total > 0 {
+			t.SetProgress(int((done + size) * 100 / total))
+		}
+	}
+	var failed []string
+	moved := 0
+	for _, obj := range objs {
+		if utils.IsCanceled(t.Ctx) {
+			return nil
+		}
+		if obj.IsDir() {
+			err := op.MakeDir(t.Ctx, dstStorage, stdpath.Join(obj.dstDirPath, obj.GetName()))
+			if err != nil {
+				failed = append(failed, fmt.Sprintf("%s: %s", obj.srcPath, err.Error()))
+			}
+			continue
+		}
+		t.SetStatus(fmt.Sprintf("moving [%d/%d] %s", moved+len(failed)+1, files, obj.srcPath))
+		size := obj.GetSize()
+		var err error
+		if !identicalFileExists(t.Ctx, dstStorage, obj.dstDirPath, obj.Obj) {
+			err = putFileBetween2Storages(t.Ctx, srcStorage, dstStorage, obj.srcPath, obj.dstDirPath, func(percentage int) {
+				setProgress(size * int64(percentage) / 100)
+			})
+			if err == nil {
+				err = verifyDstFile(t.Ctx, dstStorage, obj.dstDirPath, obj.Obj)
+			}
+		}
+		if err != nil {
+			log.Errorf("failed move [%s]: %+v", obj.srcPath, err)
+			failed = append(failed, fmt.Sprintf("%s: %s", obj.srcPath, err.Error()))
+		} else {
+			moved++
+		}
+		done += size
+		setProgress(0)
+	}
+	op.ClearCache(dstStorage, dstDirPath)
+	if len(failed) > 0 {
+		t.SetStatus(fmt.Sprintf("%d moved, %d failed, src is kept", moved, len(failed)))
+		return errors.Errorf("failed move %d items, src is kept:\n%s", len(failed), strings.Join(failed, "\n"))
+	}
+	t.SetStatus("all files moved, removing src object")
+	if err := op.Remove(t.Ctx, srcStorage, srcObjPath); err != nil {
+		return errors.WithMessagef(err, "failed remove src [%s] after moved", srcObjPath)
+	}
+	op.ClearCache(srcStorage, stdpath.Dir(srcObjPath))
+	moveProps(op.FullPath(srcStorage, srcObjPath), op.FullPath(dstStorage, stdpath.Join(dstDirPath, srcObj.GetName())))
+	t.SetStatus(fmt.Sprintf("%d files moved", moved))
+	return nil
+}
@@ -3,7 +3,6 @@ package fs
 import (
 	"context"
 
-	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/pkg/errors"
@@ -17,19 +16,23 @@ func makeDir(ctx context.Context, path string) error {
 	return op.MakeDir(ctx, storage, actualPath)
 }
 
-func move(ctx context.Context, srcPath, dstDirPath string) error {
+// move if in the same storage, call driver.Move
+// if not, add move task
+func move(ctx context.Context, srcPath, dstDirPath string) (bool, error) {
 	srcStorage, srcActualPath, err := op.GetStorageAndActualPath(srcPath)
 	if err != nil {
-		return errors.WithMessage(err, "failed get src storage")
+		return false, errors.WithMessage(err, "failed get src storage")
 	}
 	dstStorage, dstDirActualPath, err := op.GetStorageAndActualPath(dstDirPath)
 	if err != nil {
-		return errors.WithMessage(err, "failed get dst storage")
+		return false, errors.WithMessage(err, "failed get dst storage")
 	}
-	if srcStorage.GetStorage() != dstStorage.GetStorage() {
-		return errors.WithStack(errs.MoveBetweenTwoStorages)
+	if srcStorage.GetStorage() == dstStorage.GetStorage() {
+		return false, op.Move(ctx, srcStorage, srcActualPath, dstDirActualPath)
 	}
-	return op.Move(ctx, srcStorage, srcActualPath, dstDirActualPath)
+	// not in the same storage, copy then delete
+	MoveTaskManager.Submit(newMoveTask(srcStorage, dstStorage, srcActualPath, dstDirActualPath))
+	return true, nil
 }
 
 func rename(ctx context.Context, srcPath, dstName string) error {
@@ -48,6 +51,14 @@ func remove(ctx context.Context, path string) error {
 	return op.Remove(ctx, storage, actualPath)
 }
 
+func getSpace(ctx context.Context, path string) (*model.Space, error) {
+	storage, _, err := op.GetStorageAndActualPath(path)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get storage")
+	}
+	return op.GetSpace(ctx, storage)
+}
+
 func other(ctx context.Context, args model.FsOtherArgs) (interface{}, error) {
 	storage, actualPath, err := op.GetStorageAndActualPath(args.Path)
 	if err != nil {
@@ -0,0 +1,27 @@
+package fs
+
+import (
+	"github.com/alist-org/alist/v3/internal/db"
+	log "github.com/sirupsen/logrus"
+)
+
+// the dead properties of webdav follow the objects, the paths are full paths with mount path.
+// The failures of them don't fail the operations on objects, so they are only logged.
+
+func moveProps(src, dst string) {
+	if err := db.MoveWebdavProps(src, dst); err != nil {
+		log.Errorf("failed move webdav props of %s to %s: %+v", src, dst, err)
+	}
+}
+
+func copyProps(src, dst string, recursive bool) {
+	if err := db.CopyWebdavProps(src, dst, recursive); err != nil {
+		log.Errorf("failed copy webdav props of %s to %s: %+v", src, dst, err)
+	}
+}
+
+func removeProps(path string) {
+	if err := db.DeleteWebdavProps(path); err != nil {
+		log.Errorf("failed delete webdav props of %s: %+v", path, err)
+	}
+}
@@ -1,12 +1,14 @@
 package fs
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	stdpath "path"
 	"strings"
+	"sync"
 
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/model"
@@ -38,7 +40,16 @@ var httpClient = &http.Client{}
 func getFileStreamFromLink(file model.Obj, link *model.Link) (model.FileStreamer, error) {
 	var rc io.ReadCloser
 	mimetype := utils.GetMimeType(file.GetName())
-	if link.Data != nil {
+	if link.RangeReader != nil {
+		if link.Data != nil {
+			_ = link.Data.Close()
+		}
+		var err error
+		rc, err = link.RangeReader(context.Background(), 0, -1)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "failed to read %s", file.GetName())
+		}
+	} else if link.Data != nil {
 		rc = link.Data
 	} else if link.FilePath != nil {
 		// copy a new temp, because will be deleted after upload
@@ -81,3 +92,89 @@ func getFileStreamFromLink(file model.Obj, link *model.Link) (model.FileStreamer
 	}
 	return stream, nil
 }
+
+func getRangeReaderFromLink(path string, l *model.Link) model.RangeReaderFunc {
+	if l.RangeReader != nil {
+		if l.Data != nil {
+			_ = l.Data.Close()
+		}
+		return l.RangeReader
+	}
+	if l.FilePath != nil && *l.FilePath != "" {
+		return func(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+			f, err := os.Open(*l.FilePath)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			if _, err := f.Seek(offset, io.SeekStart); err != nil {
+				_ = f.Close()
+				return nil, errors.WithStack(err)
+			}
+			return limitReadCloser(f, length), nil
+		}
+	}
+	if l.Data != nil {
+		var mu sync.Mutex
+		data := l.Data
+		return func(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+			// the data can be read only once, get a new link for the next read
+			mu.Lock()
+			rc := data
+			data = nil
+			mu.Unlock()
+			if rc == nil {
+				res, _, err := link(ctx, path, model.LinkArgs{})
+				if err != nil {
+					return nil, err
+				}
+				if res.Data == nil {
+					return nil, errors.Errorf("failed to read %s: no data in link", path)
+				}
+				rc = res.Data
+			}
+			if _, err := io.CopyN(io.Discard, rc, offset); err != nil {
+				_ = rc.Close()
+				return nil, errors.WithStack(err)
+			}
+			return limitReadCloser(rc, length), nil
+		}
+	}
+	return func(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, l.URL, nil)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create request for %s", l.URL)
+		}
+		for h, val := range l.Header {
+			req.Header[h] = val
+		}
+		if length < 0 {
+			req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		} else {
+			req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+		}
+		res, err := httpClient.Do(req)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get response for %s", l.URL)
+		}
+		switch res.StatusCode {
+		case http.StatusPartialContent:
+		case http.StatusOK:
+			// range is not supported, skip the bytes before offset
+			if _, err := io.CopyN(io.Discard, res.Body, offset); err != nil {
+				_ = res.Body.Close()
+				return nil, errors.WithStack(err)
+			}
+		default:
+			_ = res.Body.Close()
+			return nil, errors.Errorf("failed to read %s, status: %d", path, res.StatusCode)
+		}
+		return limitReadCloser(res.Body, length), nil
+	}
+}
+
+func limitReadCloser(rc io.ReadCloser, length int64) io.ReadCloser {
+	if length < 0 {
+		return rc
+	}
+	return utils.ReadCloser{Reader: io.LimitReader(rc, length), Closer: rc}
+}
@@ -1,170 +1,533 @@
 package fuse
 
-import "github.com/winfsp/cgofuse/fuse"
-
+import (
+	"context"
+	"fmt"
+	"io"
+	stdpath "path"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	fs2 "github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/winfsp/cgofuse/fuse"
+)
+
+// Fs implements fuse.FileSystemInterface on top of the storages,
+// RootFolder is the path in alist that mounted as the root.
 type Fs struct {
 	RootFolder string
 	fuse.FileSystemBase
+
+	mu      sync.Mutex
+	nextFh  uint64
+	handles map[uint64]*handle
+	spools  map[string]*spool // spools of the files opened for writing, by path
+	refs    map[string]int    // reference count of the spools
+}
+
+type handle struct {
+	path   string
+	reader *reader
+	spool  *spool
+}
+
+func (fs *Fs) fullPath(path string) string {
+	return utils.StandardizePath(stdpath.Join(fs.RootFolder, path))
+}
+
+// errno converts err to a negative fuse error code
+func errno(err error) int {
+	if err == nil {
+		return 0
+	}
+	if errs.IsObjectNotFound(err) {
+		return -fuse.ENOENT
+	}
+	switch {
+	case errors.Is(err, errs.NotFolder):
+		return -fuse.ENOTDIR
+	case errors.Is(err, errs.NotFile):
+		return -fuse.EISDIR
+	case errors.Is(err, errs.PermissionDenied):
+		return -fuse.EACCES
+	case errors.Is(err, errs.NotImplement), errors.Is(err, errs.NotSupport), errors.Is(err, errs.UploadNotSupported):
+		return -fuse.ENOSYS
+	}
+	return -fuse.EIO
+}
+
+// listCtx returns the context to list by fs, the mount is used by the admin and metas don't apply
+func listCtx() (context.Context, error) {
+	admin, err := db.GetAdmin()
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.WithValue(context.Background(), "user", admin)
+	return context.WithValue(ctx, "meta", (*model.Meta)(nil)), nil
+}
+
+func fillStat(stat *fuse.Stat_t, obj model.Obj) {
+	ts := fuse.NewTimespec(obj.ModTime())
+	if obj.IsDir() {
+		stat.Mode = fuse.S_IFDIR | 0755
+		stat.Nlink = 2
+	} else {
+		stat.Mode = fuse.S_IFREG | 0644
+		stat.Nlink = 1
+		stat.Size = obj.GetSize()
+	}
+	stat.Mtim = ts
+	stat.Ctim = ts
+	stat.Atim = ts
+	stat.Birthtim = ts
 }
 
 func (fs *Fs) Init() {
-	//TODO implement me
-	panic("implement me")
+	fs.handles = make(map[uint64]*handle)
+	fs.spools = make(map[string]*spool)
+	fs.refs = make(map[string]int)
 }
 
 func (fs *Fs) Destroy() {
-	//TODO implement me
-	panic("implement me")
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	for path, s := range fs.spools {
+		if err := s.Flush(context.Background()); err != nil {
+			log.Errorf("failed flush [%s] while unmounting: %+v", path, err)
+		}
+		_ = s.Close()
+	}
+	fs.handles = make(map[uint64]*handle)
+	fs.spools = make(map[string]*spool)
+	fs.refs = make(map[string]int)
 }
 
 func (fs *Fs) Statfs(path string, stat *fuse.Statfs_t) int {
-	//TODO implement me
-	panic("implement me")
+	const bsize = 4096
+	// the virtual folders and the storages that don't report their capacity get a big enough one
+	total, free := int64(1<<40)*bsize, int64(1<<40)*bsize
+	if storage, err := fs2.GetStorage(fs.fullPath(path)); err == nil {
+		space, err := op.GetSpace(context.Background(), storage)
+		if err != nil && !errors.Is(err, errs.NotImplement) {
+			log.Errorf("failed get space of [%s]: %+v", path, err)
+			return errno(err)
+		}
+		// the total is 0 if it's unknown or unlimited
+		if err == nil && space.Total > 0 {
+			total, free = space.Total, space.Free()
+		}
+	}
+	stat.Bsize = bsize
+	stat.Frsize = bsize
+	stat.Blocks = uint64(total / bsize)
+	stat.Bfree = uint64(free / bsize)
+	stat.Bavail = uint64(free / bsize)
+	stat.Files = 1 << 40
+	stat.Ffree = 1 << 40
+	stat.Favail = 1 << 40
+	stat.Namemax = 255
+	return 0
 }
 
 func (fs *Fs) Mknod(path string, mode uint32, dev uint64) int {
-	//TODO implement me
-	panic("implement me")
+	return -fuse.ENOSYS
 }
 
 func (fs *Fs) Mkdir(path string, mode uint32) int {
-	//TODO implement me
-	panic("implement me")
+	return errno(fs.makeDir(fs.fullPath(path)))
+}
+
+func (fs *Fs) makeDir(path string) error {
+	err := fs2.MakeDir(context.Background(), path)
+	fs2.ClearCache(stdpath.Dir(path))
+	return err
 }
 
 func (fs *Fs) Unlink(path string) int {
-	//TODO implement me
-	panic("implement me")
+	return errno(fs.remove(fs.fullPath(path)))
 }
 
 func (fs *Fs) Rmdir(path string) int {
-	//TODO implement me
-	panic("implement me")
+	return errno(fs.remove(fs.fullPath(path)))
+}
+
+func (fs *Fs) remove(path string) error {
+	err := fs2.Remove(context.Background(), path)
+	fs2.ClearCache(stdpath.Dir(path))
+	return err
 }
 
 func (fs *Fs) Link(oldpath string, newpath string) int {
-	//TODO implement me
-	panic("implement me")
+	return -fuse.ENOSYS
 }
 
 func (fs *Fs) Symlink(target string, newpath string) int {
-	//TODO implement me
-	panic("implement me")
+	return -fuse.ENOSYS
 }
 
 func (fs *Fs) Readlink(path string) (int, string) {
-	//TODO implement me
-	panic("implement me")
+	return -fuse.ENOSYS, ""
 }
 
+// Rename moves oldpath to the dir of newpath, then renames it to the name of newpath.
+// If newpath is an existing file, oldpath is moved next to it with a temp name first,
+// and the file is replaced only after that succeeds.
 func (fs *Fs) Rename(oldpath string, newpath string) int {
-	//TODO implement me
-	panic("implement me")
+	ctx := context.Background()
+	src, dst := fs.fullPath(oldpath), fs.fullPath(newpath)
+	if src == dst {
+		return 0
+	}
+	srcDir, srcName := stdpath.Split(src)
+	dstDir, dstName := stdpath.Split(dst)
+	if srcDir != dstDir {
+		srcStorage, err := fs2.GetStorage(src)
+		if err != nil {
+			return errno(err)
+		}
+		dstStorage, err := fs2.GetStorage(dstDir)
+		if err != nil {
+			return errno(err)
+		}
+		// let the caller fall back to copy and unlink, instead of moving in background
+		if srcStorage.GetStorage() != dstStorage.GetStorage() {
+			return -fuse.EXDEV
+		}
+	}
+	name := srcName
+	obj, err := fs2.Get(ctx, dst)
+	replace := err == nil && !obj.IsDir()
+	if replace {
+		name = fmt.Sprintf(".%s.%s.tmp", srcName, uuid.NewString())
+		if err := fs2.Rename(ctx, src, name); err != nil {
+			return errno(err)
+		}
+		fs2.ClearCache(srcDir)
+	}
+	if srcDir != dstDir {
+		if _, err := fs2.Move(ctx, stdpath.Join(srcDir, name), dstDir); err != nil {
+			if replace {
+				// restore the name of src, dst is untouched
+				_ = fs2.Rename(ctx, stdpath.Join(srcDir, name), srcName)
+				fs2.ClearCache(srcDir)
+			}
+			return errno(err)
+		}
+		fs2.ClearCache(srcDir)
+		fs2.ClearCache(dstDir)
+	}
+	if replace {
+		if err := fs.remove(dst); err != nil {
+			return errno(err)
+		}
+	}
+	if name != dstName {
+		if err := fs2.Rename(ctx, stdpath.Join(dstDir, name), dstName); err != nil {
+			if replace {
+				log.Errorf("failed rename [%s] to replace [%s], it's kept as [%s]: %+v", src, dst, name, err)
+			}
+			return errno(err)
+		}
+		fs2.ClearCache(dstDir)
+	}
+	return 0
 }
 
 func (fs *Fs) Chmod(path string, mode uint32) int {
-	//TODO implement me
-	panic("implement me")
+	return 0
 }
 
 func (fs *Fs) Chown(path string, uid uint32, gid uint32) int {
-	//TODO implement me
-	panic("implement me")
+	return 0
 }
 
 func (fs *Fs) Utimens(path string, tmsp []fuse.Timespec) int {
-	//TODO implement me
-	panic("implement me")
+	return 0
 }
 
 func (fs *Fs) Access(path string, mask uint32) int {
-	//TODO implement me
-	panic("implement me")
+	return 0
 }
 
 func (fs *Fs) Create(path string, flags int, mode uint32) (int, uint64) {
-	//TODO implement me
-	panic("implement me")
+	path = fs.fullPath(path)
+	s, err := fs.openSpool(path, false, 0)
+	if err != nil {
+		return errno(err), ^uint64(0)
+	}
+	// a created file should be uploaded even if nothing written
+	s.dirty = true
+	return 0, fs.newHandle(&handle{path: path, spool: s})
 }
 
 func (fs *Fs) Open(path string, flags int) (int, uint64) {
-	//TODO implement me
-	panic("implement me")
+	path = fs.fullPath(path)
+	obj, err := fs2.Get(context.Background(), path)
+	if err != nil {
+		return errno(err), ^uint64(0)
+	}
+	if obj.IsDir() {
+		return -fuse.EISDIR, ^uint64(0)
+	}
+	if flags&fuse.O_ACCMODE == fuse.O_RDONLY {
+		fs.mu.Lock()
+		s, ok := fs.spools[path]
+		if ok {
+			fs.refs[path]++
+		}
+		fs.mu.Unlock()
+		if ok {
+			return 0, fs.newHandle(&handle{path: path, spool: s})
+		}
+		return 0, fs.newHandle(&handle{path: path, reader: newReader(path, obj.GetSize())})
+	}
+	s, err := fs.openSpool(path, flags&fuse.O_TRUNC == 0, obj.GetSize())
+	if err != nil {
+		return errno(err), ^uint64(0)
+	}
+	if flags&fuse.O_TRUNC != 0 {
+		if err := s.Truncate(0); err != nil {
+			return errno(err), ^uint64(0)
+		}
+	}
+	return 0, fs.newHandle(&handle{path: path, spool: s})
+}
+
+// openSpool gets the spool of path or creates a new one,
+// the content of the remote file is loaded into the new spool if load is true.
+func (fs *Fs) openSpool(path string, load bool, size int64) (*spool, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if s, ok := fs.spools[path]; ok {
+		fs.refs[path]++
+		return s, nil
+	}
+	s, err := newSpool(path)
+	if err != nil {
+		return nil, err
+	}
+	if load && size > 0 {
+		if err := s.load(context.Background(), size); err != nil {
+			_ = s.Close()
+			return nil, err
+		}
+	}
+	fs.spools[path] = s
+	fs.refs[path] = 1
+	return s, nil
+}
+
+func (fs *Fs) newHandle(h *handle) uint64 {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.nextFh++
+	fs.handles[fs.nextFh] = h
+	return fs.nextFh
+}
+
+func (fs *Fs) getHandle(fh uint64) (*handle, bool) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	h, ok := fs.handles[fh]
+	return h, ok
 }
 
 func (fs *Fs) Getattr(path string, stat *fuse.Stat_t, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
+	if path == "/" {
+		fillStat(stat, &model.Object{Name: "/", IsFolder: true, Modified: time.Now()})
+		return 0
+	}
+	path = fs.fullPath(path)
+	fs.mu.Lock()
+	s, ok := fs.spools[path]
+	fs.mu.Unlock()
+	if ok {
+		size, err := s.Size()
+		if err != nil {
+			return errno(err)
+		}
+		fillStat(stat, &model.Object{Name: stdpath.Base(path), Size: size, Modified: time.Now()})
+		return 0
+	}
+	obj, err := fs2.Get(context.Background(), path)
+	if err != nil {
+		return errno(err)
+	}
+	fillStat(stat, obj)
+	return 0
 }
 
 func (fs *Fs) Truncate(path string, size int64, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
+	if h, ok := fs.getHandle(fh); ok && h.spool != nil {
+		return errno(h.spool.Truncate(size))
+	}
+	// truncate without an opened handle
+	path = fs.fullPath(path)
+	obj, err := fs2.Get(context.Background(), path)
+	if err != nil {
+		return errno(err)
+	}
+	s, err := fs.openSpool(path, size > 0, obj.GetSize())
+	if err != nil {
+		return errno(err)
+	}
+	defer fs.releaseSpool(path)
+	if err := s.Truncate(size); err != nil {
+		return errno(err)
+	}
+	return errno(s.Flush(context.Background()))
 }
 
 func (fs *Fs) Read(path string, buff []byte, ofst int64, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
+	h, ok := fs.getHandle(fh)
+	if !ok {
+		return -fuse.EBADF
+	}
+	var (
+		n   int
+		err error
+	)
+	if h.spool != nil {
+		n, err = h.spool.ReadAt(buff, ofst)
+	} else {
+		n, err = h.reader.ReadAt(context.Background(), buff, ofst)
+	}
+	if err != nil && err != io.EOF {
+		log.Errorf("failed read [%s]: %+v", h.path, err)
+		return errno(err)
+	}
+	return n
 }
 
 func (fs *Fs) Write(path string, buff []byte, ofst int64, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
+	h, ok := fs.getHandle(fh)
+	if !ok || h.spool == nil {
+		return -fuse.EBADF
+	}
+	n, err := h.spool.WriteAt(buff, ofst)
+	if err != nil {
+		log.Errorf("failed write [%s]: %+v", h.path, err)
+		return errno(err)
+	}
+	return n
 }
 
 func (fs *Fs) Flush(path string, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
+	h, ok := fs.getHandle(fh)
+	if !ok {
+		return -fuse.EBADF
+	}
+	if h.spool == nil {
+		return 0
+	}
+	err := h.spool.Flush(context.Background())
+	if err != nil {
+		log.Errorf("failed upload [%s]: %+v", h.path, err)
+	}
+	return errno(err)
 }
 
 func (fs *Fs) Release(path string, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
+	fs.mu.Lock()
+	h, ok := fs.handles[fh]
+	delete(fs.handles, fh)
+	fs.mu.Unlock()
+	if !ok {
+		return -fuse.EBADF
+	}
+	if h.reader != nil {
+		h.reader.Close()
+		return 0
+	}
+	err := h.spool.Flush(context.Background())
+	if err != nil {
+		log.Errorf("failed upload [%s]: %+v", h.path, err)
+	}
+	fs.releaseSpool(h.path)
+	return errno(err)
+}
+
+// releaseSpool decreases the reference count of the spool, and removes it if not referenced
+func (fs *Fs) releaseSpool(path string) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.refs[path]--
+	if fs.refs[path] > 0 {
+		return
+	}
+	if s, ok := fs.spools[path]; ok {
+		if err := s.Close(); err != nil {
+			log.Warnf("failed remove spool of [%s]: %+v", path, err)
+		}
+	}
+	delete(fs.spools, path)
+	delete(fs.refs, path)
 }
 
 func (fs *Fs) Fsync(path string, datasync bool, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
+	return fs.Flush(path, fh)
 }
 
 func (fs *Fs) Opendir(path string) (int, uint64) {
-	//TODO implement me
-	panic("implement me")
+	obj, err := fs2.Get(context.Background(), fs.fullPath(path))
+	if err != nil && path != "/" {
+		return errno(err), ^uint64(0)
+	}
+	if obj != nil && !obj.IsDir() {
+		return -fuse.ENOTDIR, ^uint64(0)
+	}
+	return 0, 0
 }
 
 func (fs *Fs) Readdir(path string, fill func(name string, stat *fuse.Stat_t, ofst int64) bool, ofst int64, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
+	ctx, err := listCtx()
+	if err != nil {
+		return errno(err)
+	}
+	objs, err := fs2.List(ctx, fs.fullPath(path))
+	if err != nil {
+		return errno(err)
+	}
+	fill(".", nil, 0)
+	fill("..", nil, 0)
+	for _, obj := range objs {
+		stat := new(fuse.Stat_t)
+		fillStat(stat, obj)
+		if !fill(obj.GetName(), stat, 0) {
+			break
+		}
+	}
+	return 0
 }
 
 func (fs *Fs) Releasedir(path string, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
+	return 0
 }
 
 func (fs *Fs) Fsyncdir(path string, datasync bool, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
+	return 0
 }
 
 func (fs *Fs) Setxattr(path string, name string, value []byte, flags int) int {
-	//TODO implement me
-	panic("implement me")
+	return -fuse.ENOSYS
 }
 
 func (fs *Fs) Getxattr(path string, name string) (int, []byte) {
-	//TODO implement me
-	panic("implement me")
+	return -fuse.ENOSYS, nil
 }
 
 func (fs *Fs) Removexattr(path string, name string) int {
-	//TODO implement me
-	panic("implement me")
+	return -fuse.ENOSYS
 }
 
 func (fs *Fs) Listxattr(path string, fill func(name string) bool) int {
-	//TODO implement me
-	panic("implement me")
+	return -fuse.ENOSYS
 }
-
-var _ fuse.FileSystemInterface = (*Fs)(nil)
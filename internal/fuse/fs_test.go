@@ -0,0 +1,57 @@
+package fuse
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/alist-org/alist/v3/drivers/local"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	conf.Conf = conf.DefaultConfig()
+	d, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	db.Init(d)
+}
+
+// setupLocal mounts a temp dir at mountPath, and uses another one as the temp dir of spools
+func setupLocal(t *testing.T, mountPath string) string {
+	root := t.TempDir()
+	conf.Conf.TempDir = t.TempDir()
+	addition := `{"root_folder_path":"` + filepath.ToSlash(root) + `"}`
+	if _, err := op.CreateStorage(context.Background(), model.Storage{Driver: "Local", MountPath: mountPath, Addition: addition}); err != nil {
+		t.Fatalf("failed create local storage: %+v", err)
+	}
+	return root
+}
+
+func TestRename(t *testing.T) {
+	root := setupLocal(t, "/rename")
+	for name, content := range map[string]string{"a.txt": "a", "b.txt": "b"} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fs := &Fs{RootFolder: "/rename"}
+	fs.Init()
+	if code := fs.Rename("/a.txt", "/b.txt"); code != 0 {
+		t.Fatalf("failed rename: %d", code)
+	}
+	entries, _ := os.ReadDir(root)
+	if len(entries) != 1 || entries[0].Name() != "b.txt" {
+		t.Fatalf("unexpected files: %v", entries)
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, "b.txt")); string(data) != "a" {
+		t.Errorf("the dst should be replaced, got %s", data)
+	}
+}
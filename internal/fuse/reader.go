@@ -0,0 +1,216 @@
+package fuse
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+const (
+	chunkSize       = 1024 * 1024
+	readAheadChunks = 4  // chunks fetched in one request while missing the cache
+	maxCachedChunks = 16 // chunks kept in the cache of a reader
+)
+
+var httpClient = &http.Client{}
+
+// reader reads a remote file by range, the fetched chunks are cached
+// and the following chunks are read ahead to reduce the requests.
+type reader struct {
+	path string
+	size int64
+
+	mu     sync.Mutex
+	link   *model.Link
+	chunks map[int64][]byte
+	order  []int64 // chunk indexes ordered by fetch time, used to evict the oldest
+	// the stream of link.Data can only be read sequentially
+	data     io.ReadCloser
+	dataOfst int64
+}
+
+func newReader(path string, size int64) *reader {
+	return &reader{
+		path:   path,
+		size:   size,
+		chunks: make(map[int64][]byte),
+	}
+}
+
+func (r *reader) ReadAt(ctx context.Context, p []byte, off int64) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if off >= r.size {
+		return 0, io.EOF
+	}
+	n := 0
+	for n < len(p) && off < r.size {
+		idx := off / chunkSize
+		chunk, ok := r.chunks[idx]
+		if !ok {
+			if err := r.fetch(ctx, idx); err != nil {
+				return n, err
+			}
+			chunk = r.chunks[idx]
+		}
+		start := off - idx*chunkSize
+		if start >= int64(len(chunk)) {
+			break
+		}
+		m := copy(p[n:], chunk[start:])
+		n += m
+		off += int64(m)
+	}
+	return n, nil
+}
+
+// fetch the chunk idx and the following chunks of read ahead
+func (r *reader) fetch(ctx context.Context, idx int64) error {
+	start := idx * chunkSize
+	end := start + chunkSize*readAheadChunks
+	if end > r.size {
+		end = r.size
+	}
+	buf, err := r.readRange(ctx, start, end-start)
+	if err != nil {
+		// the link maybe expired, try again with a new link
+		r.closeData()
+		r.link = nil
+		buf, err = r.readRange(ctx, start, end-start)
+		if err != nil {
+			return err
+		}
+	}
+	for i := int64(0); i*chunkSize < int64(len(buf)); i++ {
+		chunkEnd := (i + 1) * chunkSize
+		if chunkEnd > int64(len(buf)) {
+			chunkEnd = int64(len(buf))
+		}
+		r.store(idx+i, buf[i*chunkSize:chunkEnd])
+	}
+	return nil
+}
+
+func (r *reader) store(idx int64, chunk []byte) {
+	if _, ok := r.chunks[idx]; !ok {
+		r.order = append(r.order, idx)
+	}
+	r.chunks[idx] = chunk
+	for len(r.order) > maxCachedChunks {
+		delete(r.chunks, r.order[0])
+		r.order = r.order[1:]
+	}
+}
+
+func (r *reader) readRange(ctx context.Context, off, length int64) ([]byte, error) {
+	if r.link == nil {
+		link, _, err := fs.Link(ctx, r.path, model.LinkArgs{})
+		if err != nil {
+			return nil, err
+		}
+		r.link = link
+		r.data = link.Data
+		r.dataOfst = 0
+		if link.RangeReader != nil && link.Data != nil {
+			r.closeData()
+		}
+	}
+	buf := make([]byte, length)
+	if r.link.FilePath != nil && *r.link.FilePath != "" {
+		f, err := os.Open(*r.link.FilePath)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		defer f.Close()
+		n, err := f.ReadAt(buf, off)
+		if err != nil && err != io.EOF {
+			return nil, errors.WithStack(err)
+		}
+		return buf[:n], nil
+	}
+	if r.link.RangeReader != nil {
+		rc, err := r.link.RangeReader(ctx, off, length)
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		n, err := io.ReadFull(rc, buf)
+		if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+			return nil, errors.WithStack(err)
+		}
+		return buf[:n], nil
+	}
+	if r.link.Data != nil {
+		return r.readData(buf, off)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.link.URL, nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for h, val := range r.link.Header {
+		req.Header[h] = val
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", off, off+length-1))
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer res.Body.Close()
+	switch res.StatusCode {
+	case http.StatusPartialContent:
+	case http.StatusOK:
+		// range is not supported, skip the bytes before off
+		if _, err := io.CopyN(io.Discard, res.Body, off); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	default:
+		return nil, errors.Errorf("failed read range of [%s], status: %d", r.path, res.StatusCode)
+	}
+	n, err := io.ReadFull(res.Body, buf)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, errors.WithStack(err)
+	}
+	return buf[:n], nil
+}
+
+func (r *reader) readData(buf []byte, off int64) ([]byte, error) {
+	if off < r.dataOfst {
+		// can't seek back, the stream will be reopened with a new link
+		return nil, errors.Errorf("can't seek back in the stream of [%s]", r.path)
+	}
+	if off > r.dataOfst {
+		n, err := io.CopyN(io.Discard, r.data, off-r.dataOfst)
+		r.dataOfst += n
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	n, err := io.ReadFull(r.data, buf)
+	r.dataOfst += int64(n)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, errors.WithStack(err)
+	}
+	return buf[:n], nil
+}
+
+func (r *reader) closeData() {
+	if r.data != nil {
+		_ = r.data.Close()
+		r.data = nil
+	}
+}
+
+func (r *reader) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closeData()
+	r.chunks = nil
+	r.order = nil
+}
@@ -0,0 +1,59 @@
+package fuse
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReader(t *testing.T) {
+	root := setupLocal(t, "/reader")
+	// more chunks than the cache can hold
+	size := int64(maxCachedChunks+readAheadChunks+1)*chunkSize + 100
+	content := make([]byte, size)
+	for i := range content {
+		content[i] = byte(i % 251)
+	}
+	if err := os.WriteFile(filepath.Join(root, "file"), content, 0o666); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	r := newReader("/reader/file", size)
+	defer r.Close()
+
+	read := func(off, length int64) {
+		t.Helper()
+		buf := make([]byte, length)
+		n, err := r.ReadAt(ctx, buf, off)
+		if err != nil {
+			t.Fatalf("failed read at %d: %+v", off, err)
+		}
+		end := off + length
+		if end > size {
+			end = size
+		}
+		if !bytes.Equal(buf[:n], content[off:end]) {
+			t.Fatalf("unexpected content at %d", off)
+		}
+	}
+	// across the boundary of chunks
+	read(chunkSize-10, 20)
+	if len(r.chunks) != readAheadChunks {
+		t.Errorf("the chunks should be read ahead, got %d chunks", len(r.chunks))
+	}
+	// the last chunk is not full
+	read(size-50, 100)
+	for off := int64(0); off < size; off += chunkSize {
+		read(off, 10)
+	}
+	if len(r.chunks) > maxCachedChunks || len(r.order) != len(r.chunks) {
+		t.Errorf("the cache should be limited, got %d chunks", len(r.chunks))
+	}
+	if _, ok := r.chunks[0]; ok {
+		t.Errorf("the oldest chunk should be evicted")
+	}
+	// the evicted chunk is fetched again
+	read(0, chunkSize+10)
+}
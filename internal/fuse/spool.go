@@ -0,0 +1,143 @@
+package fuse
+
+import (
+	"context"
+	"io"
+	"os"
+	stdpath "path"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// spool is a local temp file that holds the writes of a file,
+// it's uploaded to the storage while flushing.
+type spool struct {
+	path  string
+	mu    sync.Mutex
+	file  *os.File
+	dirty bool
+}
+
+func newSpool(path string) (*spool, error) {
+	dir := filepath.Join(conf.Conf.TempDir, "fuse")
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	f, err := os.Create(filepath.Join(dir, uuid.NewString()))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &spool{path: path, file: f}, nil
+}
+
+// load the content of the remote file into the spool
+func (s *spool) load(ctx context.Context, size int64) error {
+	r := newReader(s.path, size)
+	defer r.Close()
+	buf := make([]byte, chunkSize)
+	var off int64
+	for off < size {
+		n, err := r.ReadAt(ctx, buf, off)
+		if n > 0 {
+			if _, err := s.file.WriteAt(buf[:n], off); err != nil {
+				return errors.WithStack(err)
+			}
+			off += int64(n)
+		}
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return err
+		}
+		if n == 0 {
+			break
+		}
+	}
+	return nil
+}
+
+func (s *spool) ReadAt(p []byte, off int64) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.ReadAt(p, off)
+}
+
+func (s *spool) WriteAt(p []byte, off int64) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dirty = true
+	return s.file.WriteAt(p, off)
+}
+
+func (s *spool) Truncate(size int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dirty = true
+	return errors.WithStack(s.file.Truncate(size))
+}
+
+func (s *spool) Size() (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stat, err := s.file.Stat()
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	return stat.Size(), nil
+}
+
+// spoolStream wraps the file of spool, because op.Put removes the *os.File after upload
+type spoolStream struct {
+	io.Reader
+	io.Closer
+}
+
+// Flush uploads the spool to the storage if it's changed
+func (s *spool) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.dirty {
+		return nil
+	}
+	stat, err := s.file.Stat()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	f, err := os.Open(s.file.Name())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	name := stdpath.Base(s.path)
+	stream := &model.FileStream{
+		Obj: &model.Object{
+			Name:     name,
+			Size:     stat.Size(),
+			Modified: time.Now(),
+		},
+		ReadCloser: spoolStream{Reader: f, Closer: f},
+		Mimetype:   utils.GetMimeType(name),
+	}
+	dir := stdpath.Dir(s.path)
+	if err := fs.PutDirectly(ctx, dir, stream); err != nil {
+		return err
+	}
+	fs.ClearCache(dir)
+	s.dirty = false
+	return nil
+}
+
+func (s *spool) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_ = s.file.Close()
+	return errors.WithStack(os.Remove(s.file.Name()))
+}
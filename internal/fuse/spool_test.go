@@ -0,0 +1,58 @@
+package fuse
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSpool(t *testing.T) {
+	root := setupLocal(t, "/spool")
+	ctx := context.Background()
+	s, err := newSpool("/spool/file.txt")
+	if err != nil {
+		t.Fatalf("failed create spool: %+v", err)
+	}
+	defer s.Close()
+	// nothing is uploaded if the spool is not changed
+	if err := s.Flush(ctx); err != nil {
+		t.Fatalf("failed flush: %+v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "file.txt")); !os.IsNotExist(err) {
+		t.Fatalf("the spool shouldn't be uploaded without changes: %v", err)
+	}
+
+	if _, err := s.WriteAt([]byte("hello world"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Truncate(5); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Flush(ctx); err != nil {
+		t.Fatalf("failed flush: %+v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, "file.txt")); string(data) != "hello" {
+		t.Errorf("unexpected uploaded content: %q", data)
+	}
+	if s.dirty {
+		t.Errorf("the spool should be clean after flushing")
+	}
+
+	if err := s.Truncate(8); err != nil {
+		t.Fatal(err)
+	}
+	if size, err := s.Size(); err != nil || size != 8 {
+		t.Errorf("unexpected size: %d %+v", size, err)
+	}
+	if err := s.Flush(ctx); err != nil {
+		t.Fatalf("failed flush: %+v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, "file.txt")); string(data) != "hello\x00\x00\x00" {
+		t.Errorf("the extended part should be zeros: %q", data)
+	}
+	// the spool file is still usable after uploading
+	if _, err := os.Stat(s.file.Name()); err != nil {
+		t.Errorf("the spool file shouldn't be removed by flushing: %+v", err)
+	}
+}
@@ -0,0 +1,173 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/cron"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	tick         = 15 * time.Second
+	checkTimeout = time.Minute
+	minBackoff   = time.Minute
+	historySize  = 20
+)
+
+type state struct {
+	model.StorageHealth
+	checking bool
+}
+
+var (
+	mu sync.Mutex
+	// states of the loaded storages by id
+	states = make(map[uint]*state)
+)
+
+// Init starts to check the loaded storages periodically, a failed storage is
+// re-initialized with exponential backoff until it recovers.
+func Init() {
+	cron.NewCron(tick).Do(scan)
+}
+
+// Get returns the health of the loaded storage
+func Get(id uint) (*model.StorageHealth, bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	s, ok := states[id]
+	if !ok {
+		return nil, false
+	}
+	h := s.StorageHealth
+	h.History = append([]model.StorageStatusChange(nil), s.History...)
+	return &h, true
+}
+
+// scan starts the checks of the storages which are due
+func scan() {
+	interval := time.Duration(setting.GetInt(conf.StorageHealthInterval, 5)) * time.Minute
+	if interval <= 0 || !conf.StoragesLoaded {
+		return
+	}
+	now := time.Now()
+	storages := op.GetAllStorages()
+	mu.Lock()
+	defer mu.Unlock()
+	loaded := make(map[uint]bool, len(storages))
+	for _, storageDriver := range storages {
+		storage := storageDriver.GetStorage()
+		loaded[storage.ID] = true
+		s := getState(storage, now)
+		if s.checking {
+			continue
+		}
+		// the status is changed out of the monitor, such as the storage is updated with a wrong config
+		changed := s.Healthy && storage.Status != op.WORK
+		if !changed && now.Before(s.NextCheck) {
+			continue
+		}
+		s.checking = true
+		go check(storageDriver, interval)
+	}
+	for id := range states {
+		if !loaded[id] {
+			delete(states, id)
+		}
+	}
+}
+
+// getState returns the state of storage, a new one is created by its status with mu held
+func getState(storage *model.Storage, now time.Time) *state {
+	s, ok := states[storage.ID]
+	if ok {
+		return s
+	}
+	healthy := storage.Status == op.WORK
+	s = &state{StorageHealth: model.StorageHealth{
+		Healthy: healthy,
+		History: []model.StorageStatusChange{{Time: now, Healthy: healthy, Status: storage.Status}},
+	}}
+	if healthy {
+		s.NextCheck = now.Add(time.Duration(setting.GetInt(conf.StorageHealthInterval, 5)) * time.Minute)
+	} else {
+		s.LastError = storage.Status
+	}
+	states[storage.ID] = s
+	return s
+}
+
+// check lists the root of storage, the storage is re-initialized if it's failed
+func check(storageDriver driver.Driver, interval time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+	defer cancel()
+	storage := storageDriver.GetStorage()
+	err := op.CheckStorage(ctx, storageDriver)
+	if err != nil {
+		log.Warnf("storage %s is unavailable: %+v", storage.MountPath, err)
+		err = op.ReloadStorage(ctx, storageDriver)
+		if err == nil {
+			log.Infof("storage %s is reloaded", storage.MountPath)
+		}
+	}
+	now := time.Now()
+
+	mu.Lock()
+	s := getState(storage, now)
+	s.checking = false
+	s.LastCheck = now
+	var typ op.EventType
+	if err == nil {
+		s.Failures = 0
+		s.NextCheck = now.Add(interval)
+		if !s.Healthy {
+			typ = op.EventStorageUp
+		}
+	} else {
+		s.Failures++
+		s.LastError = err.Error()
+		s.NextCheck = now.Add(backoff(s.Failures))
+		if s.Healthy {
+			typ = op.EventStorageDown
+		}
+	}
+	if typ != "" {
+		s.Healthy = err == nil
+		s.History = append(s.History, model.StorageStatusChange{Time: now, Healthy: s.Healthy, Status: storage.Status})
+		if len(s.History) > historySize {
+			s.History = s.History[len(s.History)-historySize:]
+		}
+	}
+	mu.Unlock()
+
+	if typ != "" {
+		e := op.Event{Type: typ, Path: storage.MountPath, Status: storage.Status}
+		if err != nil {
+			e.Error = err.Error()
+		}
+		op.Emit(e)
+	}
+}
+
+// backoff returns the delay before the next re-initialization after failures
+func backoff(failures int) time.Duration {
+	max := time.Duration(setting.GetInt(conf.StorageHealthMaxBackoff, 60)) * time.Minute
+	if max < minBackoff {
+		max = minBackoff
+	}
+	d := minBackoff
+	for i := 1; i < failures && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	return d
+}
@@ -0,0 +1,84 @@
+package health
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/alist-org/alist/v3/drivers/local"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	conf.Conf = conf.DefaultConfig()
+	d, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	db.Init(d)
+}
+
+func TestCheck(t *testing.T) {
+	var events []op.Event
+	op.RegisterEventHandler(func(e op.Event) {
+		if e.Path == "/health" && (e.Type == op.EventStorageUp || e.Type == op.EventStorageDown) {
+			events = append(events, e)
+		}
+	})
+	root := filepath.Join(t.TempDir(), "root")
+	addition := `{"root_folder_path":"` + filepath.ToSlash(root) + `"}`
+	if _, err := op.CreateStorage(context.Background(), model.Storage{Driver: "Local", MountPath: "/health", Addition: addition}); err == nil {
+		t.Fatalf("expect failed to init storage")
+	}
+	storageDriver, err := op.GetStorageByVirtualPath("/health")
+	if err != nil {
+		t.Fatalf("failed get storage: %+v", err)
+	}
+	id := storageDriver.GetStorage().ID
+
+	check(storageDriver, time.Minute)
+	h, _ := Get(id)
+	if h.Healthy || h.Failures != 1 || h.LastError == "" || len(events) != 0 {
+		t.Fatalf("unexpected health of the failed storage: %+v %+v", h, events)
+	}
+
+	if err := os.Mkdir(root, 0o755); err != nil {
+		t.Fatalf("failed mkdir: %+v", err)
+	}
+	check(storageDriver, time.Minute)
+	h, _ = Get(id)
+	if !h.Healthy || h.Failures != 0 || storageDriver.GetStorage().Status != op.WORK {
+		t.Fatalf("the storage should be recovered: %+v", h)
+	}
+	if len(events) != 1 || events[0].Type != op.EventStorageUp {
+		t.Fatalf("expect a storage.up event, got %+v", events)
+	}
+
+	if err := os.Remove(root); err != nil {
+		t.Fatalf("failed remove: %+v", err)
+	}
+	check(storageDriver, time.Minute)
+	h, _ = Get(id)
+	if h.Healthy || len(h.History) != 3 {
+		t.Fatalf("the storage should be down: %+v", h)
+	}
+	if len(events) != 2 || events[1].Type != op.EventStorageDown || events[1].Error == "" {
+		t.Fatalf("expect a storage.down event, got %+v", events)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	cases := map[int]time.Duration{1: time.Minute, 2: 2 * time.Minute, 4: 8 * time.Minute, 7: time.Hour, 100: time.Hour}
+	for failures, want := range cases {
+		if got := backoff(failures); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", failures, got, want)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package metrics
+
+import "time"
+
+var (
+	HTTPRequests = NewCounterVec("alist_http_requests_total",
+		"Count of http requests by route and status.", "method", "route", "status")
+	HTTPDuration = NewHistogramVec("alist_http_request_duration_seconds",
+		"Latency of http requests by route.", DefBuckets, "method", "route")
+	ServedBytes = NewCounterVec("alist_served_bytes_total",
+		"Bytes served by /d, /p and webdav.", "via")
+
+	CacheRequests = NewCounterVec("alist_cache_requests_total",
+		"Lookups of the list and link caches by result.", "cache", "result")
+
+	DriverCalls = NewCounterVec("alist_driver_calls_total",
+		"Count of driver calls.", "driver", "method")
+	DriverErrors = NewCounterVec("alist_driver_errors_total",
+		"Count of failed driver calls.", "driver", "method")
+	DriverDuration = NewHistogramVec("alist_driver_call_duration_seconds",
+		"Latency of driver calls.", DefBuckets, "driver", "method")
+)
+
+// CacheLookup records a lookup of the cache
+func CacheLookup(cache string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	CacheRequests.Inc(cache, result)
+}
+
+// DriverCall records a call of driver started at start
+func DriverCall(driver, method string, start time.Time, err error) {
+	DriverCalls.Inc(driver, method)
+	if err != nil {
+		DriverErrors.Inc(driver, method)
+	}
+	DriverDuration.Observe(time.Since(start).Seconds(), driver, method)
+}
@@ -0,0 +1,227 @@
+// Package metrics collects the metrics of alist and writes them in the prometheus text format.
+// It's a minimal implementation of counters, histograms and the gauges collected on scraping.
+//
+// The prometheus client library isn't used since only these three types are needed,
+// and it would add its registry, protobuf and procfs dependencies to the single binary.
+// The output follows the text exposition format 0.0.4, which the scrapers all accept.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Sample is a value of a collected gauge
+type Sample struct {
+	Labels map[string]string
+	Value  float64
+}
+
+type metric interface {
+	write(w io.Writer)
+}
+
+var (
+	registryMu sync.Mutex
+	registry   []metric
+)
+
+func register(m metric) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry = append(registry, m)
+}
+
+// Write writes all the metrics in the prometheus text format
+func Write(w io.Writer) {
+	registryMu.Lock()
+	metrics := append([]metric(nil), registry...)
+	registryMu.Unlock()
+	for _, m := range metrics {
+		m.write(w)
+	}
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	// the quotes aren't escaped in the help text
+	helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escape(v string) string {
+	return labelEscaper.Replace(v)
+}
+
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	pairs := make([]string, len(names))
+	for i := range names {
+		pairs[i] = fmt.Sprintf(`%s="%s"`, names[i], escape(values[i]))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func writeHeader(w io.Writer, name, help, typ string) {
+	_, _ = fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, helpEscaper.Replace(help), name, typ)
+}
+
+// vec keeps the values of a metric by its label values
+type vec[V any] struct {
+	name   string
+	help   string
+	labels []string
+	mu     sync.Mutex
+	values map[string]*V
+	keys   map[string][]string
+	newV   func() *V
+}
+
+func (v *vec[V]) with(labelValues []string, f func(*V)) {
+	if len(labelValues) != len(v.labels) {
+		panic(fmt.Sprintf("metric %s expects %d labels, got %d", v.name, len(v.labels), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	val, ok := v.values[key]
+	if !ok {
+		val = v.newV()
+		v.values[key] = val
+		v.keys[key] = append([]string(nil), labelValues...)
+	}
+	f(val)
+}
+
+// each calls f with the values sorted by labels
+func (v *vec[V]) each(f func(labelValues []string, val *V)) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	keys := make([]string, 0, len(v.values))
+	for k := range v.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		f(v.keys[k], v.values[k])
+	}
+}
+
+type CounterVec struct {
+	vec[float64]
+}
+
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{vec[float64]{
+		name: name, help: help, labels: labels,
+		values: make(map[string]*float64), keys: make(map[string][]string),
+		newV: func() *float64 { return new(float64) },
+	}}
+	register(c)
+	return c
+}
+
+func (c *CounterVec) Add(value float64, labelValues ...string) {
+	c.with(labelValues, func(v *float64) { *v += value })
+}
+
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *CounterVec) write(w io.Writer) {
+	writeHeader(w, c.name, c.help, "counter")
+	c.each(func(labelValues []string, v *float64) {
+		_, _ = fmt.Fprintf(w, "%s%s %s\n", c.name, formatLabels(c.labels, labelValues), formatValue(*v))
+	})
+}
+
+// DefBuckets are the default buckets of histograms in seconds
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+type histogram struct {
+	counts []uint64 // not cumulative
+	count  uint64
+	sum    float64
+}
+
+type HistogramVec struct {
+	vec[histogram]
+	buckets []float64
+}
+
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	h := &HistogramVec{buckets: buckets}
+	h.vec = vec[histogram]{
+		name: name, help: help, labels: labels,
+		values: make(map[string]*histogram), keys: make(map[string][]string),
+		newV: func() *histogram { return &histogram{counts: make([]uint64, len(buckets))} },
+	}
+	register(h)
+	return h
+}
+
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	h.with(labelValues, func(v *histogram) {
+		for i, b := range h.buckets {
+			if value <= b {
+				v.counts[i]++
+				break
+			}
+		}
+		v.count++
+		v.sum += value
+	})
+}
+
+func (h *HistogramVec) write(w io.Writer) {
+	writeHeader(w, h.name, h.help, "histogram")
+	names := append(append([]string(nil), h.labels...), "le")
+	h.each(func(labelValues []string, v *histogram) {
+		var cumulative uint64
+		for i, b := range h.buckets {
+			cumulative += v.counts[i]
+			_, _ = fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(names, append(append([]string(nil), labelValues...), formatValue(b))), cumulative)
+		}
+		_, _ = fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(names, append(append([]string(nil), labelValues...), "+Inf")), v.count)
+		_, _ = fmt.Fprintf(w, "%s_sum%s %s\n", h.name, formatLabels(h.labels, labelValues), formatValue(v.sum))
+		_, _ = fmt.Fprintf(w, "%s_count%s %d\n", h.name, formatLabels(h.labels, labelValues), v.count)
+	})
+}
+
+// gaugeFunc is a gauge whose samples are collected on scraping
+type gaugeFunc struct {
+	name    string
+	help    string
+	collect func() []Sample
+}
+
+// RegisterGaugeFunc registers a gauge, collect is called on every scraping
+func RegisterGaugeFunc(name, help string, collect func() []Sample) {
+	register(&gaugeFunc{name: name, help: help, collect: collect})
+}
+
+func (g *gaugeFunc) write(w io.Writer) {
+	writeHeader(w, g.name, g.help, "gauge")
+	for _, s := range g.collect() {
+		names := make([]string, 0, len(s.Labels))
+		for k := range s.Labels {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		values := make([]string, len(names))
+		for i, k := range names {
+			values[i] = s.Labels[k]
+		}
+		_, _ = fmt.Fprintf(w, "%s%s %s\n", g.name, formatLabels(names, values), formatValue(s.Value))
+	}
+}
@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	c := NewCounterVec("test_requests_total", "Test counter.", "route")
+	c.Inc("/a")
+	c.Add(2, `/b"`)
+	h := NewHistogramVec("test_duration_seconds", "Test histogram.", []float64{0.1, 1}, "route")
+	h.Observe(0.05, "/a")
+	h.Observe(0.5, "/a")
+	h.Observe(5, "/a")
+	RegisterGaugeFunc("test_up", "Test gauge,\nwith a \\ and \"quotes\".", func() []Sample {
+		return []Sample{{Labels: map[string]string{"b": "2", "a": "1"}, Value: 1}}
+	})
+	var buf bytes.Buffer
+	Write(&buf)
+	out := buf.String()
+	for _, line := range []string{
+		"# TYPE test_requests_total counter",
+		`# HELP test_up Test gauge,\nwith a \\ and "quotes".`,
+		`test_requests_total{route="/a"} 1`,
+		`test_requests_total{route="/b\""} 2`,
+		"# TYPE test_duration_seconds histogram",
+		`test_duration_seconds_bucket{route="/a",le="0.1"} 1`,
+		`test_duration_seconds_bucket{route="/a",le="1"} 2`,
+		`test_duration_seconds_bucket{route="/a",le="+Inf"} 3`,
+		`test_duration_seconds_sum{route="/a"} 5.55`,
+		`test_duration_seconds_count{route="/a"} 3`,
+		`test_up{a="1",b="2"} 1`,
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("missing line %s in:\n%s", line, out)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package model
+
+import "strings"
+
+// Action is an operation on a path, the actions are combined by bits in the acl rules
+type Action int32
+
+const (
+	ActionList Action = 1 << iota
+	ActionRead
+	ActionWrite
+	ActionRename
+	ActionMove
+	ActionCopy
+	ActionRemove
+	ActionWebdav
+	ActionWebdavManage
+	ActionAria2
+)
+
+var actionNames = map[Action]string{
+	ActionList:         "list",
+	ActionRead:         "read",
+	ActionWrite:        "write",
+	ActionRename:       "rename",
+	ActionMove:         "move",
+	ActionCopy:         "copy",
+	ActionRemove:       "remove",
+	ActionWebdav:       "webdav",
+	ActionWebdavManage: "webdav_manage",
+	ActionAria2:        "aria2",
+}
+
+func (a Action) String() string {
+	var names []string
+	for action, name := range actionNames {
+		if a&action != 0 {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// Group is a role of users, the permission is combined with the permission of users in it
+type Group struct {
+	ID          uint   `json:"id" gorm:"primaryKey"`
+	Name        string `json:"name" gorm:"unique" binding:"required"`
+	Description string `json:"description"`
+	// the same bits as User.Permission
+	Permission int32 `json:"permission"`
+}
+
+// ACLRule allows or denies the actions on the path for a user or a group.
+// The rules of the longest path decide, the deny wins if a user rule and a group rule conflict.
+type ACLRule struct {
+	ID      uint   `json:"id" gorm:"primaryKey"`
+	UserID  uint   `json:"user_id" gorm:"index"`  // the rule is for a user if it's not 0
+	GroupID uint   `json:"group_id" gorm:"index"` // the rule is for a group if it's not 0
+	Path    string `json:"path" binding:"required"`
+	// Inherit applies the rule to the sub paths
+	Inherit bool   `json:"inherit"`
+	Allow   Action `json:"allow"`
+	Deny    Action `json:"deny"`
+}
+
+// Match checks if the rule applies to the path
+func (r ACLRule) Match(path string) bool {
+	if r.Path == path {
+		return true
+	}
+	if !r.Inherit {
+		return false
+	}
+	return r.Path == "/" || strings.HasPrefix(path, r.Path+"/")
+}
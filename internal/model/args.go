@@ -1,6 +1,7 @@
 package model
 
 import (
+	"context"
 	"io"
 	"net/http"
 	"time"
@@ -16,13 +17,17 @@ type LinkArgs struct {
 	Type   string
 }
 
+// RangeReaderFunc opens a reader of the file from offset, length -1 means reading to the end
+type RangeReaderFunc func(ctx context.Context, offset, length int64) (io.ReadCloser, error)
+
 type Link struct {
-	URL        string         `json:"url"`
-	Header     http.Header    `json:"header"` // needed header
-	Data       io.ReadCloser  // return file reader directly
-	Status     int            // status maybe 200 or 206, etc
-	FilePath   *string        // local file, return the filepath
-	Expiration *time.Duration // url expiration time
+	URL         string          `json:"url"`
+	Header      http.Header     `json:"header"` // needed header
+	Data        io.ReadCloser   // return file reader directly
+	RangeReader RangeReaderFunc // read file by range, preferred over Data if both are set
+	Status      int             // status maybe 200 or 206, etc
+	FilePath    *string         // local file, return the filepath
+	Expiration  *time.Duration  // url expiration time
 }
 
 type OtherArgs struct {
@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// AuditLog records who did what and its result
+type AuditLog struct {
+	ID       uint      `json:"id" gorm:"primaryKey"`
+	Time     time.Time `json:"time" gorm:"index"`
+	UserID   uint      `json:"user_id"`
+	Username string    `json:"username" gorm:"index"`
+	IP       string    `json:"ip"`
+	// Via is the entrance of the operation, such as api, webdav and s3, it's empty for the local ones
+	Via     string `json:"via"`
+	Action  string `json:"action" gorm:"index"`
+	Src     string `json:"src"`
+	Dst     string `json:"dst"`
+	Success bool   `json:"success"`
+	Error   string `json:"error"`
+}
+
+// AuditReq is the filters of audit logs, the empty ones are ignored
+type AuditReq struct {
+	PageReq
+	Username string `json:"username" form:"username"`
+	IP       string `json:"ip" form:"ip"`
+	// Action matches the actions with the prefix, such as fs or fs.remove
+	Action string `json:"action" form:"action"`
+	// Path matches the src or dst containing it
+	Path    string    `json:"path" form:"path"`
+	Success *bool     `json:"success" form:"success"`
+	From    time.Time `json:"from" form:"from"`
+	To      time.Time `json:"to" form:"to"`
+}
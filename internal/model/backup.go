@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// Backup is the portable data of an instance, which can be restored to any type of database
+type Backup struct {
+	Version int `json:"version"`
+	// AlistVersion is the version of alist which created the backup
+	AlistVersion string    `json:"alist_version"`
+	CreatedAt    time.Time `json:"created_at"`
+	// Encrypted is true if the secrets are encrypted by the key derived from a password and Salt,
+	// Check is the encrypted known text to verify the password
+	Encrypted bool   `json:"encrypted"`
+	Salt      string `json:"salt,omitempty"`
+	Check     string `json:"check,omitempty"`
+
+	Users    []BackupUser  `json:"users"`
+	Groups   []Group       `json:"groups"`
+	ACLRules []ACLRule     `json:"acl_rules"`
+	Metas    []Meta        `json:"metas"`
+	Storages []Storage     `json:"storages"`
+	Settings []SettingItem `json:"settings"`
+}
+
+// BackupUser is a user with its otp secret, which is hidden in the json of User
+type BackupUser struct {
+	User
+	OtpSecret string `json:"otp_secret"`
+}
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// StorageHealth is the health of a loaded storage tracked by the health monitor
+type StorageHealth struct {
+	Healthy   bool      `json:"healthy"`
+	LastCheck time.Time `json:"last_check"`
+	NextCheck time.Time `json:"next_check"`
+	LastError string    `json:"last_error"`
+	// Failures is the count of consecutive failed checks
+	Failures int                   `json:"failures"`
+	History  []StorageStatusChange `json:"history"`
+}
+
+// StorageStatusChange is a change of the health of storage
+type StorageStatusChange struct {
+	Time    time.Time `json:"time"`
+	Healthy bool      `json:"healthy"`
+	Status  string    `json:"status"`
+}
@@ -0,0 +1,30 @@
+package model
+
+import "strings"
+
+// WebdavLock is a lock of webdav, the root is a full path with mount path
+type WebdavLock struct {
+	Token string `json:"token" gorm:"primaryKey"`
+	Root  string `json:"root" gorm:"uniqueIndex"`
+	// Duration is the timeout in nanoseconds, a negative one means infinite
+	Duration  int64  `json:"duration"`
+	Expiry    int64  `json:"expiry"` // unix nanoseconds, ignored if the duration is infinite
+	OwnerXML  string `json:"owner_xml"`
+	ZeroDepth bool   `json:"zero_depth"`
+}
+
+// Expired checks if the lock expired at now, which is in unix nanoseconds
+func (l WebdavLock) Expired(now int64) bool {
+	return l.Duration >= 0 && l.Expiry <= now
+}
+
+// Covers checks if the lock locks the path
+func (l WebdavLock) Covers(path string) bool {
+	if l.Root == path {
+		return true
+	}
+	if l.ZeroDepth {
+		return false
+	}
+	return l.Root == "/" || strings.HasPrefix(path, l.Root+"/")
+}
@@ -1,9 +1,11 @@
 package model
 
+import "github.com/alist-org/alist/v3/pkg/utils"
+
 type Meta struct {
 	ID       uint   `json:"id" gorm:"primaryKey"`
 	Path     string `json:"path" gorm:"unique" binding:"required"`
-	Password string `json:"password"`
+	Password string `json:"password"` // bcrypt hash of password
 	PSub     bool   `json:"p_sub"`
 	Write    bool   `json:"write"`
 	WSub     bool   `json:"w_sub"`
@@ -12,3 +14,7 @@ type Meta struct {
 	Readme   string `json:"readme"`
 	RSub     bool   `json:"r_sub"`
 }
+
+func (m Meta) ValidatePassword(password string) bool {
+	return utils.ComparePassword(m.Password, password)
+}
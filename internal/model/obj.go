@@ -5,6 +5,7 @@ import (
 	"sort"
 	"time"
 
+	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/maruel/natural"
 )
 
@@ -38,6 +39,19 @@ type SetPath interface {
 	SetPath(path string)
 }
 
+// Hash is implemented by the objs whose content hashes are known
+type Hash interface {
+	GetHash() utils.HashInfo
+}
+
+// GetHash returns the content hashes of obj, it's empty if they are unknown
+func GetHash(obj Obj) utils.HashInfo {
+	if h, ok := obj.(Hash); ok {
+		return h.GetHash()
+	}
+	return utils.HashInfo{}
+}
+
 func SortFiles(objs []Obj, orderBy, orderDirection string) {
 	if orderBy == "" {
 		return
@@ -13,6 +13,7 @@ type Object struct {
 	Size     int64
 	Modified time.Time
 	IsFolder bool
+	HashInfo utils.HashInfo
 }
 
 func (o *Object) GetName() string {
@@ -43,6 +44,10 @@ func (o *Object) SetPath(id string) {
 	o.Path = id
 }
 
+func (o *Object) GetHash() utils.HashInfo {
+	return o.HashInfo
+}
+
 type Thumbnail struct {
 	Thumbnail string
 }
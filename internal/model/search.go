@@ -2,7 +2,24 @@ package model
 
 import (
 	"fmt"
+	"regexp"
 	"time"
+
+	"github.com/pkg/errors"
+)
+
+// the match modes of keywords, the glob and regex match the whole name
+const (
+	MatchKeywords = ""
+	MatchGlob     = "glob"
+	MatchRegex    = "regex"
+)
+
+// the scopes of search
+const (
+	ScopeAll = iota
+	ScopeFolders
+	ScopeFiles
 )
 
 type IndexProgress struct {
@@ -15,6 +32,17 @@ type IndexProgress struct {
 type SearchReq struct {
 	Parent   string `json:"parent"`
 	Keywords string `json:"keywords"`
+	Mode     string `json:"mode"`
+	Scope    int    `json:"scope"`
+	// Types are the types of utils.GetObjType, all types if empty
+	Types   []int `json:"types"`
+	MinSize int64 `json:"min_size"`
+	// MaxSize is not limited if it's 0
+	MaxSize        int64     `json:"max_size"`
+	ModifiedAfter  time.Time `json:"modified_after"`
+	ModifiedBefore time.Time `json:"modified_before"`
+	OrderBy        string    `json:"order_by"`
+	OrderDirection string    `json:"order_direction"`
 	PageReq
 }
 
@@ -23,6 +51,13 @@ type SearchNode struct {
 	Name   string `json:"name"`
 	IsDir  bool   `json:"is_dir"`
 	Size   int64  `json:"size"`
+	// Modified and FileType are empty in the index built by old versions
+	Modified time.Time `json:"modified"`
+	FileType int       `json:"-" gorm:"index"`
+	// Content is the extracted text of file, only stored by the searchers which support it
+	Content string `json:"-" gorm:"-"`
+	// Highlights are the matched snippets of content
+	Highlights []string `json:"highlights,omitempty" gorm:"-"`
 }
 
 func (p *SearchReq) Validate() error {
@@ -32,9 +67,39 @@ func (p *SearchReq) Validate() error {
 	if p.PerPage < 1 {
 		return fmt.Errorf("per_page can't < 1")
 	}
+	switch p.Mode {
+	case MatchKeywords, MatchGlob:
+	case MatchRegex:
+		if _, err := regexp.Compile(p.Keywords); err != nil {
+			return errors.Wrapf(err, "invalid regex")
+		}
+	default:
+		return fmt.Errorf("invalid mode: %s", p.Mode)
+	}
+	if p.Scope < ScopeAll || p.Scope > ScopeFiles {
+		return fmt.Errorf("invalid scope: %d", p.Scope)
+	}
+	if p.MaxSize > 0 && p.MinSize > p.MaxSize {
+		return fmt.Errorf("min_size can't > max_size")
+	}
+	switch p.OrderBy {
+	case "", "name", "size", "modified":
+	default:
+		return fmt.Errorf("invalid order_by: %s", p.OrderBy)
+	}
+	switch p.OrderDirection {
+	case "", "asc", "desc":
+	default:
+		return fmt.Errorf("invalid order_direction: %s", p.OrderDirection)
+	}
 	return nil
 }
 
+// NameRegexp returns the regexp which matches the whole name, only for the regex mode
+func (p *SearchReq) NameRegexp() *regexp.Regexp {
+	return regexp.MustCompile("^(?:" + p.Keywords + ")$")
+}
+
 func (s *SearchNode) Type() string {
 	return "SearchNode"
 }
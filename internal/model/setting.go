@@ -8,6 +8,9 @@ const (
 	GLOBAL
 	ARIA2
 	INDEX
+	S3
+	AUDIT
+	LIMIT
 )
 
 const (
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+// Share is a public link of a file or a folder, which can be accessed without an account
+type Share struct {
+	ID        string     `json:"id" gorm:"primaryKey"`
+	Path      string     `json:"path" binding:"required"` // the full path of shared obj
+	CreatorID uint       `json:"creator_id" gorm:"index"`
+	Creator   string     `json:"creator"`
+	Expires   *time.Time `json:"expires"` // never expires if nil
+	Password  string     `json:"-"`       // bcrypt hash of password, not in the responses
+	// MaxDownloads is not limited if it's 0
+	MaxDownloads int       `json:"max_downloads"`
+	Downloads    int       `json:"downloads"`
+	AllowList    bool      `json:"allow_list"` // allow listing the shared folder
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (s Share) IsExpired() bool {
+	return s.Expires != nil && time.Now().After(*s.Expires)
+}
+
+func (s Share) ReachedMaxDownloads() bool {
+	return s.MaxDownloads > 0 && s.Downloads >= s.MaxDownloads
+}
+
+func (s Share) ValidatePassword(password string) bool {
+	return s.Password == "" || utils.ComparePassword(s.Password, password)
+}
@@ -0,0 +1,18 @@
+package model
+
+// Space is the capacity of a storage in bytes, Total is 0 if it's unknown or unlimited
+type Space struct {
+	Total int64 `json:"total"`
+	Used  int64 `json:"used"`
+}
+
+// Free returns the available bytes, or -1 if the total is unknown
+func (s Space) Free() int64 {
+	if s.Total <= 0 {
+		return -1
+	}
+	if s.Used > s.Total {
+		return 0
+	}
+	return s.Total - s.Used
+}
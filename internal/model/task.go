@@ -0,0 +1,15 @@
+package model
+
+type TaskItem struct {
+	ID        uint   `json:"id" gorm:"primaryKey"`
+	Kind      string `json:"kind" gorm:"index"` // copy, move, upload, aria2_down, aria2_transfer
+	Tid       string `json:"tid"`               // id of the task in its manager
+	ParentTid string `json:"parent_tid"`        // tid of the parent task, empty if it's a root task
+	Name      string `json:"name" gorm:"type:text"`
+	Args      string `json:"args" gorm:"type:text"` // json encoded arguments, used to resume the task
+	State     string `json:"state"`
+	Status    string `json:"status" gorm:"type:text"`
+	Progress  int    `json:"progress"`
+	Size      int64  `json:"size"`
+	Error     string `json:"error" gorm:"type:text"`
+}
@@ -0,0 +1,84 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// TokenPrefix is the prefix of personal access tokens, which tells them from the jwt tokens
+const TokenPrefix = "alist-pat-"
+
+const (
+	ScopeRead   = "read"   // list and read files
+	ScopeUpload = "upload" // upload files, without listing or reading them
+	ScopeWrite  = "write"  // all the fs operations
+	ScopeAdmin  = "admin"  // the admin apis
+)
+
+var scopeActions = map[string]Action{
+	ScopeRead:   ActionList | ActionRead | ActionWebdav,
+	ScopeUpload: ActionWrite | ActionWebdav | ActionWebdavManage,
+	ScopeWrite: ActionList | ActionRead | ActionWrite | ActionRename | ActionMove | ActionCopy |
+		ActionRemove | ActionWebdav | ActionWebdavManage | ActionAria2,
+}
+
+// Token is a personal access token of user, only the sha256 of token is stored
+type Token struct {
+	ID     uint   `json:"id" gorm:"primaryKey"`
+	UserID uint   `json:"user_id" gorm:"index"`
+	Name   string `json:"name" binding:"required"`
+	Hash   string `json:"-" gorm:"unique"`
+	// Hint is the beginning of token to recognize it
+	Hint string `json:"hint"`
+	// Scopes is the scopes split by comma
+	Scopes string `json:"scopes"`
+	// Path limits the token to the path and its sub paths, which is the full path in alist
+	Path       string     `json:"path"`
+	ExpiresAt  *time.Time `json:"expires_at"` // never expires if nil
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func (t Token) IsExpired() bool {
+	return t.ExpiresAt != nil && time.Now().After(*t.ExpiresAt)
+}
+
+func (t Token) ScopeList() []string {
+	var scopes []string
+	for _, s := range strings.Split(t.Scopes, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			scopes = append(scopes, s)
+		}
+	}
+	return scopes
+}
+
+func (t Token) HasScope(scope string) bool {
+	for _, s := range t.ScopeList() {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidScope checks if the scope is known
+func ValidScope(scope string) bool {
+	_, ok := scopeActions[scope]
+	return ok || scope == ScopeAdmin
+}
+
+// Allows checks if the token allows the action on the path, which is the full path in alist
+func (t Token) Allows(action Action, path string) bool {
+	if t.Path != "" && t.Path != "/" && path != t.Path && !strings.HasPrefix(path, t.Path+"/") {
+		return false
+	}
+	if t.HasScope(ScopeAdmin) {
+		return true
+	}
+	var actions Action
+	for _, s := range t.ScopeList() {
+		actions |= scopeActions[s]
+	}
+	return actions&action == action
+}
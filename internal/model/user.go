@@ -15,7 +15,7 @@ const (
 type User struct {
 	ID       uint   `json:"id" gorm:"primaryKey"`                      // unique key
 	Username string `json:"username" gorm:"unique" binding:"required"` // username
-	Password string `json:"password"`                                  // password
+	Password string `json:"password"`                                  // bcrypt hash of password, blanked in the api responses
 	BasePath string `json:"base_path"`                                 // base path
 	Role     int    `json:"role"`                                      // user's role
 	// Determine permissions by bit
@@ -31,6 +31,10 @@ type User struct {
 	//  9: webdav write
 	Permission int32  `json:"permission"`
 	OtpSecret  string `json:"-"`
+	// GroupID is the group of user, the acl rules and the permission of group apply to the user
+	GroupID uint `json:"group_id"`
+	// Token is the personal access token which the user is authenticated by, it limits what the user can do
+	Token *Token `json:"-" gorm:"-"`
 }
 
 func (u User) IsGuest() bool {
@@ -45,52 +49,12 @@ func (u User) ValidatePassword(password string) error {
 	if password == "" {
 		return errors.WithStack(errs.EmptyPassword)
 	}
-	if u.Password != password {
+	if !utils.ComparePassword(u.Password, password) {
 		return errors.WithStack(errs.WrongPassword)
 	}
 	return nil
 }
 
-func (u User) CanSeeHides() bool {
-	return u.IsAdmin() || u.Permission&1 == 1
-}
-
-func (u User) CanAccessWithoutPassword() bool {
-	return u.IsAdmin() || (u.Permission>>1)&1 == 1
-}
-
-func (u User) CanAddAria2Tasks() bool {
-	return u.IsAdmin() || (u.Permission>>2)&1 == 1
-}
-
-func (u User) CanWrite() bool {
-	return u.IsAdmin() || (u.Permission>>3)&1 == 1
-}
-
-func (u User) CanRename() bool {
-	return u.IsAdmin() || (u.Permission>>4)&1 == 1
-}
-
-func (u User) CanMove() bool {
-	return u.IsAdmin() || (u.Permission>>5)&1 == 1
-}
-
-func (u User) CanCopy() bool {
-	return u.IsAdmin() || (u.Permission>>6)&1 == 1
-}
-
-func (u User) CanRemove() bool {
-	return u.IsAdmin() || (u.Permission>>7)&1 == 1
-}
-
-func (u User) CanWebdavRead() bool {
-	return u.IsAdmin() || (u.Permission>>8)&1 == 1
-}
-
-func (u User) CanWebdavManage() bool {
-	return u.IsAdmin() || (u.Permission>>9)&1 == 1
-}
-
 func (u User) JoinPath(reqPath string) (string, error) {
 	return utils.JoinBasePath(u.BasePath, reqPath)
 }
@@ -0,0 +1,14 @@
+package model
+
+// WebdavProp is a dead property of webdav set by PROPPATCH, the path is a full path with mount path
+type WebdavProp struct {
+	ID   uint   `json:"id" gorm:"primaryKey"`
+	Path string `json:"path" gorm:"index"`
+	// Parent is the dir of path, the props of the objects in a dir are loaded at once by it
+	Parent string `json:"parent" gorm:"index"`
+	// Space and Local are the namespace and the local name of the property
+	Space    string `json:"space"`
+	Local    string `json:"local"`
+	Lang     string `json:"lang"`
+	InnerXML string `json:"inner_xml" gorm:"type:text"`
+}
@@ -0,0 +1,78 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// Webhook posts the events matching its filters to the url
+type Webhook struct {
+	ID   uint   `json:"id" gorm:"primaryKey"`
+	Name string `json:"name" binding:"required"`
+	URL  string `json:"url" binding:"required"`
+	// Secret signs the payload, the signature is sent in the header X-Alist-Signature if it's not empty
+	Secret string `json:"secret"`
+	// Events are the comma separated event types, such as fs.upload,fs.remove, all events if empty
+	Events string `json:"events"`
+	// Paths are the comma separated paths, the events of objects under them are sent, all paths if empty
+	Paths    string `json:"paths"`
+	Disabled bool   `json:"disabled"`
+}
+
+func splitList(s string) []string {
+	var res []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// MatchEvent checks if the webhook subscribes the event type
+func (w Webhook) MatchEvent(typ string) bool {
+	events := splitList(w.Events)
+	if len(events) == 0 {
+		return true
+	}
+	for _, e := range events {
+		if e == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchPath checks if any of the paths is under the paths of webhook, the empty paths are ignored
+func (w Webhook) MatchPath(paths ...string) bool {
+	filters := splitList(w.Paths)
+	if len(filters) == 0 {
+		return true
+	}
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		for _, f := range filters {
+			f = strings.TrimSuffix(f, "/")
+			if f == "" || path == f || strings.HasPrefix(path, f+"/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is a delivery of an event to a webhook, it's updated after every attempt
+type WebhookDelivery struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	WebhookID  uint      `json:"webhook_id" gorm:"index"`
+	Event      string    `json:"event"`
+	Payload    string    `json:"payload"`
+	Attempts   int       `json:"attempts"`
+	StatusCode int       `json:"status_code"`
+	Success    bool      `json:"success"`
+	Error      string    `json:"error"`
+	CreatedAt  time.Time `json:"created_at" gorm:"index"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
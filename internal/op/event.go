@@ -0,0 +1,76 @@
+package op
+
+import (
+	"context"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+type EventType string
+
+const (
+	EventUpload EventType = "fs.upload"
+	EventMkdir  EventType = "fs.mkdir"
+	EventRename EventType = "fs.rename"
+	EventMove   EventType = "fs.move"
+	// EventCopy is a copy in a storage, the files copied between storages are uploaded by the copy task
+	EventCopy          EventType = "fs.copy"
+	EventRemove        EventType = "fs.remove"
+	EventStorageStatus EventType = "storage.status"
+	// EventStorageDown and EventStorageUp are emitted by the health monitor when a storage fails or recovers
+	EventStorageDown  EventType = "storage.down"
+	EventStorageUp    EventType = "storage.up"
+	EventTaskFinished EventType = "task.finished"
+)
+
+// Event is a change of files, storages or tasks, the paths are full paths with mount path
+type Event struct {
+	Type EventType `json:"type"`
+	Time time.Time `json:"time"`
+	// User is the username who made the change, empty if unknown
+	User string `json:"user,omitempty"`
+	// Path is the changed object, or the mount path of the storage
+	Path string `json:"path,omitempty"`
+	// Dst is the new path of a renamed, moved or copied object
+	Dst string `json:"dst,omitempty"`
+	// Status is the status of storage or the state of task
+	Status string `json:"status,omitempty"`
+	// Task is the name of task, and TaskKind is its kind such as upload and copy
+	Task     string `json:"task,omitempty"`
+	TaskKind string `json:"task_kind,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// EventHandler is called synchronously when an event is emitted, so it must not block
+type EventHandler = func(e Event)
+
+var eventHandlers = make([]EventHandler, 0)
+
+// RegisterEventHandler should be called before serving
+func RegisterEventHandler(handler EventHandler) {
+	eventHandlers = append(eventHandlers, handler)
+}
+
+// Emit sends the event to all handlers
+func Emit(e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	for _, handler := range eventHandlers {
+		handler(e)
+	}
+}
+
+// emitFs emits an event of the user in ctx
+func emitFs(ctx context.Context, typ EventType, path, dst string) {
+	e := Event{Type: typ, Path: path, Dst: dst}
+	if user, ok := ctx.Value("user").(*model.User); ok {
+		e.User = user.Username
+	}
+	Emit(e)
+}
+
+func emitStorageStatus(storage *model.Storage) {
+	Emit(Event{Type: EventStorageStatus, Path: storage.MountPath, Status: storage.Status})
+}
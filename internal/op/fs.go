@@ -10,6 +10,7 @@ import (
 	"github.com/Xhofe/go-cache"
 	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/metrics"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/pkg/singleflight"
 	"github.com/alist-org/alist/v3/pkg/utils"
@@ -40,7 +41,9 @@ func List(ctx context.Context, storage driver.Driver, path string, args model.Li
 	log.Debugf("op.List %s", path)
 	key := Key(storage, path)
 	if len(refresh) == 0 || !refresh[0] {
-		if files, ok := listCache.Get(key); ok {
+		files, ok := listCache.Get(key)
+		metrics.CacheLookup("list", ok)
+		if ok {
 			log.Debugf("use cache when list %s", path)
 			return files, nil
 		}
@@ -54,7 +57,9 @@ func List(ctx context.Context, storage driver.Driver, path string, args model.Li
 		return nil, errors.WithStack(errs.NotFolder)
 	}
 	objs, err, _ := listG.Do(key, func() ([]model.Obj, error) {
+		start := time.Now()
 		files, err := storage.List(ctx, dir, args)
+		metrics.DriverCall(storage.Config().Name, "list", start, err)
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to list objs")
 		}
@@ -159,11 +164,15 @@ func Link(ctx context.Context, storage driver.Driver, path string, args model.Li
 		return nil, nil, errors.WithStack(errs.NotFile)
 	}
 	key := stdpath.Join(storage.GetStorage().MountPath, path) + ":" + args.IP
-	if link, ok := linkCache.Get(key); ok {
+	link, ok := linkCache.Get(key)
+	metrics.CacheLookup("link", ok)
+	if ok {
 		return link, file, nil
 	}
 	fn := func() (*model.Link, error) {
+		start := time.Now()
 		link, err := storage.Link(ctx, file, args)
+		metrics.DriverCall(storage.Config().Name, "link", start, err)
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed get link")
 		}
@@ -172,7 +181,7 @@ func Link(ctx context.Context, storage driver.Driver, path string, args model.Li
 		}
 		return link, nil
 	}
-	link, err, _ := linkG.Do(key, fn)
+	link, err, _ = linkG.Do(key, fn)
 	return link, file, err
 }
 
@@ -193,6 +202,38 @@ func Other(ctx context.Context, storage driver.Driver, args model.FsOtherArgs) (
 	}
 }
 
+var spaceCache = cache.NewMemCache(cache.WithShards[*model.Space](16))
+var spaceG singleflight.Group[*model.Space]
+
+// GetSpace get the capacity of storage, which is cached for a minute
+func GetSpace(ctx context.Context, storage driver.Driver) (*model.Space, error) {
+	q, ok := storage.(driver.Quota)
+	if !ok {
+		return nil, errs.NotImplement
+	}
+	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
+		return nil, errors.Errorf("storage not init: %s", storage.GetStorage().Status)
+	}
+	key := storage.GetStorage().MountPath
+	space, ok := spaceCache.Get(key)
+	metrics.CacheLookup("space", ok)
+	if ok {
+		return space, nil
+	}
+	space, err, _ := spaceG.Do(key, func() (*model.Space, error) {
+		start := time.Now()
+		total, used, err := q.GetSpace(ctx)
+		metrics.DriverCall(storage.Config().Name, "space", start, err)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed get space")
+		}
+		space := &model.Space{Total: total, Used: used}
+		spaceCache.Set(key, space, cache.WithEx[*model.Space](time.Minute))
+		return space, nil
+	})
+	return space, err
+}
+
 var mkdirG singleflight.Group[interface{}]
 
 func MakeDir(ctx context.Context, storage driver.Driver, path string) error {
@@ -219,6 +260,7 @@ func MakeDir(ctx context.Context, storage driver.Driver, path string) error {
 				err = storage.MakeDir(ctx, parentDir, dirName)
 				if err == nil {
 					ClearCache(storage, parentPath)
+					emitFs(ctx, EventMkdir, FullPath(storage, path), "")
 				}
 				return nil, errors.WithStack(err)
 			} else {
@@ -250,7 +292,11 @@ func Move(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string
 	if err != nil {
 		return errors.WithMessage(err, "failed to get dst dir")
 	}
-	return errors.WithStack(storage.Move(ctx, srcObj, dstDir))
+	err = storage.Move(ctx, srcObj, dstDir)
+	if err == nil {
+		emitFs(ctx, EventMove, FullPath(storage, srcPath), FullPath(storage, stdpath.Join(dstDirPath, srcObj.GetName())))
+	}
+	return errors.WithStack(err)
 }
 
 func Rename(ctx context.Context, storage driver.Driver, srcPath, dstName string) error {
@@ -261,7 +307,11 @@ func Rename(ctx context.Context, storage driver.Driver, srcPath, dstName string)
 	if err != nil {
 		return errors.WithMessage(err, "failed to get src object")
 	}
-	return errors.WithStack(storage.Rename(ctx, srcObj, dstName))
+	err = storage.Rename(ctx, srcObj, dstName)
+	if err == nil {
+		emitFs(ctx, EventRename, FullPath(storage, srcPath), FullPath(storage, stdpath.Join(stdpath.Dir(srcPath), dstName)))
+	}
+	return errors.WithStack(err)
 }
 
 // Copy Just copy file[s] in a storage
@@ -274,7 +324,14 @@ func Copy(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string
 		return errors.WithMessage(err, "failed to get src object")
 	}
 	dstDir, err := Get(ctx, storage, dstDirPath)
-	return errors.WithStack(storage.Copy(ctx, srcObj, dstDir))
+	if err != nil {
+		return errors.WithMessage(err, "failed to get dst dir")
+	}
+	err = storage.Copy(ctx, srcObj, dstDir)
+	if err == nil {
+		emitFs(ctx, EventCopy, FullPath(storage, srcPath), FullPath(storage, stdpath.Join(dstDirPath, srcObj.GetName())))
+	}
+	return errors.WithStack(err)
 }
 
 func Remove(ctx context.Context, storage driver.Driver, path string) error {
@@ -291,6 +348,7 @@ func Remove(ctx context.Context, storage driver.Driver, path string) error {
 	}
 	err = storage.Remove(ctx, obj)
 	if err == nil {
+		emitFs(ctx, EventRemove, FullPath(storage, path), "")
 		key := Key(storage, stdpath.Dir(path))
 		if objs, ok := listCache.Get(key); ok {
 			j := -1
@@ -355,8 +413,13 @@ func Put(ctx context.Context, storage driver.Driver, dstDirPath string, file mod
 	if up == nil {
 		up = func(p int) {}
 	}
+	start := time.Now()
 	err = storage.Put(ctx, parentDir, file, up)
+	metrics.DriverCall(storage.Config().Name, "put", start, err)
 	log.Debugf("put file [%s] done", file.GetName())
+	if err == nil {
+		emitFs(ctx, EventUpload, FullPath(storage, dstPath), "")
+	}
 	//if err == nil {
 	//	//clear cache
 	//	key := stdpath.Join(storage.GetStorage().MountPath, dstDirPath)
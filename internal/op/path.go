@@ -37,3 +37,14 @@ func GetStorageAndActualPath(rawPath string) (driver.Driver, string, error) {
 	actualPath = ActualPath(storage.GetAddition(), actualPath)
 	return storage, actualPath, nil
 }
+
+// FullPath is the reverse of ActualPath, it returns the path with mount path of the actual path in storage
+func FullPath(storage driver.Driver, actualPath string) string {
+	if i, ok := storage.GetAddition().(driver.IRootPath); ok {
+		root := utils.StandardizePath(i.GetRootPath())
+		if root != "/" && (actualPath == root || strings.HasPrefix(actualPath, root+"/")) {
+			actualPath = strings.TrimPrefix(actualPath, root)
+		}
+	}
+	return stdpath.Join(storage.GetStorage().MountPath, actualPath)
+}
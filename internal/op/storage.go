@@ -8,6 +8,7 @@ import (
 
 	"github.com/alist-org/alist/v3/internal/db"
 	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/metrics"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/pkg/generic_sync"
 	"github.com/alist-org/alist/v3/pkg/utils"
@@ -97,9 +98,45 @@ func initStorage(ctx context.Context, storage model.Storage, storageDriver drive
 		driverStorage.SetStatus(WORK)
 	}
 	MustSaveDriverStorage(storageDriver)
+	emitStorageStatus(driverStorage)
 	return err
 }
 
+// CheckStorage lists the root of a working storage without cache to check if it's available
+func CheckStorage(ctx context.Context, storageDriver driver.Driver) error {
+	storage := storageDriver.GetStorage()
+	if storage.Status != WORK {
+		return errors.Errorf("storage not init: %s", storage.Status)
+	}
+	root, err := Get(ctx, storageDriver, ActualPath(storageDriver.GetAddition(), "/"))
+	if err != nil {
+		return errors.WithMessage(err, "failed get root")
+	}
+	start := time.Now()
+	_, err = storageDriver.List(ctx, root, model.ListArgs{ReqPath: storage.MountPath})
+	metrics.DriverCall(storageDriver.Config().Name, "list", start, err)
+	if err != nil {
+		return errors.Wrap(err, "failed list root")
+	}
+	return nil
+}
+
+// ReloadStorage drops the storage and initializes it again with the storage in database,
+// it's used to recover the storage which failed to init or stopped working
+func ReloadStorage(ctx context.Context, storageDriver driver.Driver) error {
+	storage, err := db.GetStorageById(storageDriver.GetStorage().ID)
+	if err != nil {
+		return errors.WithMessage(err, "failed get storage")
+	}
+	if storage.Disabled || storage.MountPath != storageDriver.GetStorage().MountPath {
+		return errors.Errorf("storage %s has been changed", storage.MountPath)
+	}
+	if err := storageDriver.Drop(ctx); err != nil {
+		log.Warnf("failed drop storage %s: %+v", storage.MountPath, err)
+	}
+	return initStorage(ctx, *storage, storageDriver)
+}
+
 func EnableStorage(ctx context.Context, id uint) error {
 	storage, err := db.GetStorageById(id)
 	if err != nil {
@@ -208,6 +245,16 @@ func DeleteStorageById(ctx context.Context, id uint) error {
 	return nil
 }
 
+// DropStorages drops all the loaded storages and removes them from memory
+func DropStorages(ctx context.Context) {
+	for _, storageDriver := range GetAllStorages() {
+		if err := storageDriver.Drop(ctx); err != nil {
+			log.Warnf("failed drop storage %s: %+v", storageDriver.GetStorage().MountPath, err)
+		}
+		storagesMap.Delete(storageDriver.GetStorage().MountPath)
+	}
+}
+
 // MustSaveDriverStorage call from specific driver
 func MustSaveDriverStorage(driver driver.Driver) {
 	err := saveDriverStorage(driver)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/db"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
@@ -13,6 +14,7 @@ import (
 )
 
 func init() {
+	conf.Conf = conf.DefaultConfig()
 	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
 	if err != nil {
 		panic("failed to connect database")
@@ -25,9 +27,9 @@ func TestCreateStorage(t *testing.T) {
 		storage model.Storage
 		isErr   bool
 	}{
-		{storage: model.Storage{Driver: "Local", MountPath: "/local", Addition: `{"root_folder":"."}`}, isErr: false},
-		{storage: model.Storage{Driver: "Local", MountPath: "/local", Addition: `{"root_folder":"."}`}, isErr: true},
-		{storage: model.Storage{Driver: "None", MountPath: "/none", Addition: `{"root_folder":"."}`}, isErr: true},
+		{storage: model.Storage{Driver: "Local", MountPath: "/local", Addition: `{"root_folder_path":"."}`}, isErr: false},
+		{storage: model.Storage{Driver: "Local", MountPath: "/local", Addition: `{"root_folder_path":"."}`}, isErr: true},
+		{storage: model.Storage{Driver: "None", MountPath: "/none", Addition: `{"root_folder_path":"."}`}, isErr: true},
 	}
 	for _, storage := range storages {
 		_, err := op.CreateStorage(context.Background(), storage.storage)
@@ -68,15 +70,36 @@ func TestGetBalancedStorage(t *testing.T) {
 	}
 }
 
+func TestGetSpace(t *testing.T) {
+	_, err := op.CreateStorage(context.Background(), model.Storage{Driver: "Local", MountPath: "/space", Addition: `{"root_folder_path":"."}`})
+	if err != nil {
+		t.Fatalf("failed to create storage: %+v", err)
+	}
+	storage, err := op.GetStorageByVirtualPath("/space")
+	if err != nil {
+		t.Fatalf("failed to get storage: %+v", err)
+	}
+	space, err := op.GetSpace(context.Background(), storage)
+	if err != nil {
+		t.Fatalf("failed to get space: %+v", err)
+	}
+	if space.Total <= 0 || space.Used < 0 || space.Free() < 0 || space.Used > space.Total {
+		t.Errorf("unexpected space: %+v", space)
+	}
+}
+
 func setupStorages(t *testing.T) {
 	var storages = []model.Storage{
-		{Driver: "Local", MountPath: "/a/b", Order: 0, Addition: `{"root_folder":"."}`},
-		{Driver: "Local", MountPath: "/a/c", Order: 1, Addition: `{"root_folder":"."}`},
-		{Driver: "Local", MountPath: "/a/d", Order: 2, Addition: `{"root_folder":"."}`},
-		{Driver: "Local", MountPath: "/a/d/e", Order: 3, Addition: `{"root_folder":"."}`},
-		{Driver: "Local", MountPath: "/a/d/e.balance", Order: 4, Addition: `{"root_folder":"."}`},
+		{Driver: "Local", MountPath: "/a/b", Order: 0, Addition: `{"root_folder_path":"."}`},
+		{Driver: "Local", MountPath: "/a/c", Order: 1, Addition: `{"root_folder_path":"."}`},
+		{Driver: "Local", MountPath: "/a/d", Order: 2, Addition: `{"root_folder_path":"."}`},
+		{Driver: "Local", MountPath: "/a/d/e", Order: 3, Addition: `{"root_folder_path":"."}`},
+		{Driver: "Local", MountPath: "/a/d/e.balance", Order: 4, Addition: `{"root_folder_path":"."}`},
 	}
 	for _, storage := range storages {
+		if op.HasStorage(storage.MountPath) {
+			continue
+		}
 		_, err := op.CreateStorage(context.Background(), storage)
 		if err != nil {
 			t.Fatalf("failed to create storage: %+v", err)
@@ -0,0 +1,133 @@
+package provision
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// File is the desired state of the storages, users, metas and settings.
+// The items are matched by mount_path, username and path, only the fields
+// in the file are enforced, the others are kept as they are.
+type File struct {
+	Storages []map[string]interface{} `json:"storages"`
+	Users    []map[string]interface{} `json:"users"`
+	Metas    []map[string]interface{} `json:"metas"`
+	Settings map[string]interface{}   `json:"settings"`
+	// Prune deletes the provisioned users and metas which are removed from the file,
+	// they are kept if it's false, so that a mistake in the file doesn't destroy them
+	Prune bool `json:"prune"`
+}
+
+const (
+	Create  = "create"
+	Update  = "update"
+	Disable = "disable"
+	Delete  = "delete"
+)
+
+// Change is a difference between the file and the instance
+type Change struct {
+	Kind   string   `json:"kind"` // storage, user, meta or setting
+	Key    string   `json:"key"`
+	Action string   `json:"action"`
+	Fields []string `json:"fields,omitempty"` // the changed fields of update, the values are not shown because of secrets
+}
+
+func (c Change) String() string {
+	sign := map[string]string{Create: "+", Update: "~", Disable: "-", Delete: "-"}[c.Action]
+	s := fmt.Sprintf("%s %s %s", sign, c.Kind, c.Key)
+	if c.Action == Update {
+		return s + ": " + strings.Join(c.Fields, ", ")
+	}
+	if c.Action == Disable || c.Action == Delete {
+		return s + " (" + c.Action + ")"
+	}
+	return s
+}
+
+// Load reads the provisioning file, json if its ext is .json otherwise yaml,
+// then replaces ${NAME} in the values with the environment variables
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed read provisioning file")
+	}
+	var v interface{}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = utils.Json.Unmarshal(data, &v)
+	} else {
+		err = yaml.Unmarshal(data, &v)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed parse provisioning file")
+	}
+	v, err = normalize(v)
+	if err != nil {
+		return nil, err
+	}
+	// convert to the struct by json, so the fields of items are in json names
+	data, err = utils.Json.Marshal(v)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var f File
+	if err := utils.Json.Unmarshal(data, &f); err != nil {
+		return nil, errors.Wrapf(err, "invalid provisioning file")
+	}
+	return &f, nil
+}
+
+var envRegexp = regexp.MustCompile(`\$\{(\w+)\}`)
+
+// normalize converts the yaml maps to json ones and interpolates the environment variables in strings
+func normalize(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			n, err := normalize(val)
+			if err != nil {
+				return nil, err
+			}
+			m[fmt.Sprint(k)] = n
+		}
+		return m, nil
+	case map[string]interface{}:
+		for k, val := range v {
+			n, err := normalize(val)
+			if err != nil {
+				return nil, err
+			}
+			v[k] = n
+		}
+		return v, nil
+	case []interface{}:
+		for i := range v {
+			n, err := normalize(v[i])
+			if err != nil {
+				return nil, err
+			}
+			v[i] = n
+		}
+		return v, nil
+	case string:
+		var err error
+		s := envRegexp.ReplaceAllStringFunc(v, func(s string) string {
+			name := envRegexp.FindStringSubmatch(s)[1]
+			val, ok := os.LookupEnv(name)
+			if !ok && err == nil {
+				err = errors.Errorf("environment variable %s is not set", name)
+			}
+			return val
+		})
+		return s, err
+	}
+	return v, nil
+}
@@ -0,0 +1,134 @@
+package provision
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/alist-org/alist/v3/drivers/local"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	conf.Conf = conf.DefaultConfig()
+	d, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	db.Init(d)
+}
+
+const provisioningFile = `
+storages:
+  - mount_path: /local/
+    driver: Local
+    order: 1
+    addition:
+      root_folder_path: /data
+users:
+  - username: alice
+    password: ${PROVISION_TEST_PASSWORD}
+    permission: 3
+metas:
+  - path: /local
+    password: meta
+`
+
+func load(t *testing.T, content string) *File {
+	path := filepath.Join(t.TempDir(), "provision.yml")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed write file: %+v", err)
+	}
+	f, err := Load(path)
+	if err != nil {
+		t.Fatalf("failed load file: %+v", err)
+	}
+	return f
+}
+
+func reconcile(t *testing.T, f *File, dryRun bool) []Change {
+	changes, err := Reconcile(context.Background(), f, Options{DryRun: dryRun})
+	if err != nil {
+		t.Fatalf("failed reconcile: %+v", err)
+	}
+	return changes
+}
+
+func TestReconcile(t *testing.T) {
+	t.Setenv("PROVISION_TEST_PASSWORD", "secret")
+	f := load(t, provisioningFile)
+
+	if changes := reconcile(t, f, true); len(changes) != 3 {
+		t.Fatalf("expect 3 changes, got %+v", changes)
+	}
+	if _, err := db.GetStorageByMountPath("/local"); err == nil {
+		t.Fatalf("the dry run shouldn't create storage")
+	}
+	reconcile(t, f, false)
+	user, err := db.GetUserByName("alice")
+	if err != nil || user.ValidatePassword("secret") != nil || user.Permission != 3 {
+		t.Fatalf("unexpected user: %+v %+v", user, err)
+	}
+	if changes := reconcile(t, f, false); len(changes) != 0 {
+		t.Fatalf("reconcile should be idempotent, got %+v", changes)
+	}
+
+	// the addition refreshed by driver is kept
+	storage, _ := db.GetStorageByMountPath("/local")
+	storage.Addition = `{"root_folder_path":"/data","token":"refreshed"}`
+	if err := db.UpdateStorage(storage); err != nil {
+		t.Fatalf("failed update storage: %+v", err)
+	}
+	t.Setenv("PROVISION_TEST_PASSWORD", "changed")
+	changes := reconcile(t, load(t, provisioningFile), false)
+	if len(changes) != 1 || changes[0].String() != "~ user alice: password" {
+		t.Fatalf("expect the password changed, got %+v", changes)
+	}
+
+	if err := db.SaveSettingItem(model.SettingItem{Key: conf.SiteTitle, Value: "AList", Type: conf.TypeString, Group: model.SITE}); err != nil {
+		t.Fatalf("failed save setting: %+v", err)
+	}
+	changes = reconcile(t, load(t, "settings:\n  site_title: Provisioned\n"), false)
+	want := []string{"~ setting site_title: value", "- storage /local (disable)"}
+	if len(changes) != len(want) {
+		t.Fatalf("unexpected changes: %+v", changes)
+	}
+	for i := range want {
+		if changes[i].String() != want[i] {
+			t.Errorf("changes[%d] = %s, want %s", i, changes[i], want[i])
+		}
+	}
+	storage, _ = db.GetStorageByMountPath("/local")
+	if !storage.Disabled || storage.Addition != `{"root_folder_path":"/data","token":"refreshed"}` {
+		t.Fatalf("unexpected storage: %+v", storage)
+	}
+	// the removed users and metas are kept without prune
+	if _, err := db.GetUserByName("alice"); err != nil {
+		t.Fatalf("the user should be kept without prune: %+v", err)
+	}
+	changes = reconcile(t, load(t, "prune: true\n"), false)
+	want = []string{"- user alice (delete)", "- meta /local (delete)"}
+	if len(changes) != len(want) {
+		t.Fatalf("unexpected changes: %+v", changes)
+	}
+	for i := range want {
+		if changes[i].String() != want[i] {
+			t.Errorf("changes[%d] = %s, want %s", i, changes[i], want[i])
+		}
+	}
+}
+
+func TestLoadUnsetEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "provision.json")
+	if err := os.WriteFile(path, []byte(`{"users":[{"username":"bob","password":"${PROVISION_TEST_UNSET}"}]}`), 0600); err != nil {
+		t.Fatalf("failed write file: %+v", err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Fatalf("expect error of unset environment variable")
+	}
+}
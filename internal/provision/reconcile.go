@@ -0,0 +1,472 @@
+package provision
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type Options struct {
+	// DryRun only returns the changes without applying them
+	DryRun bool
+	// Load applies the storage changes to the loaded storages,
+	// otherwise only the database is changed, such as before loading storages
+	Load bool
+}
+
+// state is the keys of the provisioned items. The storages which are removed from the file
+// are disabled, the users and metas are deleted only if File.Prune, otherwise they are kept
+// in the state. The items created by users are never touched.
+type state struct {
+	Storages []string `json:"storages"`
+	Users    []string `json:"users"`
+	Metas    []string `json:"metas"`
+}
+
+type reconciler struct {
+	ctx     context.Context
+	opts    Options
+	changes []Change
+	failed  int
+}
+
+// Reconcile creates, updates, disables or deletes the storages, users, metas and settings
+// to match the file, it's idempotent. The removed storages are disabled, and the removed
+// users and metas are deleted only if prune: true is in the file. The failed items are
+// logged and skipped.
+func Reconcile(ctx context.Context, f *File, opts Options) ([]Change, error) {
+	old, err := getState()
+	if err != nil {
+		return nil, err
+	}
+	r := &reconciler{ctx: ctx, opts: opts}
+	var current state
+	for _, item := range f.Storages {
+		key, err := r.storage(item)
+		current.Storages = appendKey(current.Storages, key)
+		r.check("storage", key, err)
+	}
+	for _, item := range f.Users {
+		key, err := r.user(item)
+		current.Users = appendKey(current.Users, key)
+		r.check("user", key, err)
+	}
+	for _, item := range f.Metas {
+		key, err := r.meta(item)
+		current.Metas = appendKey(current.Metas, key)
+		r.check("meta", key, err)
+	}
+	keys := make([]string, 0, len(f.Settings))
+	for key := range f.Settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		r.check("setting", key, r.setting(key, f.Settings[key]))
+	}
+	for _, key := range old.Storages {
+		if !utils.SliceContains(current.Storages, key) {
+			r.check("storage", key, r.removeStorage(key))
+		}
+	}
+	for _, key := range old.Users {
+		if utils.SliceContains(current.Users, key) {
+			continue
+		}
+		if !f.Prune {
+			log.Warnf("the user [%s] is removed from the provisioning file, it's kept since prune is not set", key)
+			current.Users = appendKey(current.Users, key)
+			continue
+		}
+		r.check("user", key, r.removeUser(key))
+	}
+	for _, key := range old.Metas {
+		if utils.SliceContains(current.Metas, key) {
+			continue
+		}
+		if !f.Prune {
+			log.Warnf("the meta [%s] is removed from the provisioning file, it's kept since prune is not set", key)
+			current.Metas = appendKey(current.Metas, key)
+			continue
+		}
+		r.check("meta", key, r.removeMeta(key))
+	}
+	if !opts.DryRun {
+		if err := saveState(old, current); err != nil {
+			return r.changes, err
+		}
+	}
+	if r.failed > 0 {
+		return r.changes, errors.Errorf("failed provision %d items", r.failed)
+	}
+	return r.changes, nil
+}
+
+func (r *reconciler) check(kind, key string, err error) {
+	if err != nil {
+		r.failed++
+		log.Errorf("failed provision %s [%s]: %+v", kind, key, err)
+	}
+}
+
+// add records the change, and reports whether it should be applied
+func (r *reconciler) add(kind, key, action string, fields ...string) bool {
+	r.changes = append(r.changes, Change{Kind: kind, Key: key, Action: action, Fields: fields})
+	return !r.opts.DryRun
+}
+
+func (r *reconciler) storage(item map[string]interface{}) (string, error) {
+	mountPath, _ := item["mount_path"].(string)
+	if mountPath == "" {
+		return "", errors.New("mount_path is required")
+	}
+	mountPath = utils.StandardizePath(mountPath)
+	old, err := db.GetStorageByMountPath(mountPath)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return mountPath, err
+	}
+	var s model.Storage
+	if old != nil {
+		s = *old
+	}
+	addition, err := getAddition(item)
+	if err != nil {
+		return mountPath, err
+	}
+	fields, err := overlay(item, &s, "mount_path", "addition")
+	if err != nil {
+		return mountPath, err
+	}
+	s.MountPath = mountPath
+	additionFields, err := overlayAddition(addition, &s)
+	if err != nil {
+		return mountPath, err
+	}
+	fields = append(fields, additionFields...)
+	if old == nil {
+		if _, err := op.GetDriverNew(s.Driver); err != nil {
+			return mountPath, err
+		}
+		if !r.add("storage", mountPath, Create) {
+			return mountPath, nil
+		}
+		if !r.opts.Load || s.Disabled {
+			return mountPath, db.CreateStorage(&s)
+		}
+		_, err := op.CreateStorage(r.ctx, s)
+		return mountPath, err
+	}
+	if s.Driver != old.Driver {
+		return mountPath, errors.Errorf("driver cannot be changed")
+	}
+	if len(fields) == 0 || !r.add("storage", mountPath, Update, fields...) {
+		return mountPath, nil
+	}
+	switch {
+	case !r.opts.Load || old.Disabled && s.Disabled:
+		return mountPath, db.UpdateStorage(&s)
+	case old.Disabled:
+		if err := db.UpdateStorage(&s); err != nil {
+			return mountPath, err
+		}
+		return mountPath, op.LoadStorage(r.ctx, s)
+	case s.Disabled:
+		if err := op.DisableStorage(r.ctx, s.ID); err != nil {
+			return mountPath, err
+		}
+		return mountPath, db.UpdateStorage(&s)
+	default:
+		return mountPath, op.UpdateStorage(r.ctx, s)
+	}
+}
+
+func (r *reconciler) removeStorage(mountPath string) error {
+	s, err := db.GetStorageByMountPath(mountPath)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if s.Disabled || !r.add("storage", mountPath, Disable) {
+		return nil
+	}
+	if r.opts.Load {
+		return op.DisableStorage(r.ctx, s.ID)
+	}
+	s.Disabled = true
+	return db.UpdateStorage(s)
+}
+
+func (r *reconciler) user(item map[string]interface{}) (string, error) {
+	username, _ := item["username"].(string)
+	if username == "" {
+		return "", errors.New("username is required")
+	}
+	old, err := db.GetUserByName(username)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return username, err
+	}
+	var u model.User
+	if old != nil {
+		// the user is cached, so it's copied
+		u = *old
+	}
+	fields, err := overlay(item, &u, "password")
+	if err != nil {
+		return username, err
+	}
+	if password, ok := item["password"]; ok && setPassword(&u.Password, fmt.Sprint(password), old != nil) {
+		fields = append(fields, "password")
+	}
+	if old == nil {
+		if !r.add("user", username, Create) {
+			return username, nil
+		}
+		return username, db.CreateUser(&u)
+	}
+	if len(fields) == 0 || !r.add("user", username, Update, fields...) {
+		return username, nil
+	}
+	return username, db.UpdateUser(&u)
+}
+
+func (r *reconciler) removeUser(username string) error {
+	u, err := db.GetUserByName(username)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if u.IsAdmin() || u.IsGuest() {
+		log.Warnf("the admin or guest [%s] is removed from the provisioning file, but it can't be deleted", username)
+		return nil
+	}
+	if !r.add("user", username, Delete) {
+		return nil
+	}
+	return db.DeleteUserById(u.ID)
+}
+
+func (r *reconciler) meta(item map[string]interface{}) (string, error) {
+	path, _ := item["path"].(string)
+	if path == "" {
+		return "", errors.New("path is required")
+	}
+	path = utils.StandardizePath(path)
+	old, err := db.GetMetaByPath(path)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return path, err
+	}
+	var m model.Meta
+	if old != nil {
+		// the meta is cached, so it's copied
+		m = *old
+	}
+	fields, err := overlay(item, &m, "path", "password")
+	if err != nil {
+		return path, err
+	}
+	m.Path = path
+	if password, ok := item["password"]; ok && setPassword(&m.Password, fmt.Sprint(password), old != nil) {
+		fields = append(fields, "password")
+	}
+	if old == nil {
+		if !r.add("meta", path, Create) {
+			return path, nil
+		}
+		return path, db.CreateMeta(&m)
+	}
+	if len(fields) == 0 || !r.add("meta", path, Update, fields...) {
+		return path, nil
+	}
+	return path, db.UpdateMeta(&m)
+}
+
+func (r *reconciler) removeMeta(path string) error {
+	m, err := db.GetMetaByPath(path)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !r.add("meta", path, Delete) {
+		return nil
+	}
+	return db.DeleteMetaById(m.ID)
+}
+
+func (r *reconciler) setting(key string, value interface{}) error {
+	item, err := db.GetSettingItemByKey(key)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.Errorf("unknown setting")
+	}
+	if err != nil {
+		return err
+	}
+	v, ok := value.(string)
+	if !ok {
+		if v, err = utils.Json.MarshalToString(value); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if item.Value == v || !r.add("setting", key, Update, "value") {
+		return nil
+	}
+	item.Value = v
+	return db.SaveSettingItem(*item)
+}
+
+// overlay sets the fields in item to v by json except the skipped ones,
+// returns the json names of the changed fields
+func overlay(item map[string]interface{}, v interface{}, skip ...string) ([]string, error) {
+	before, err := toMap(v)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]interface{}, len(item))
+	for k, val := range item {
+		if utils.SliceContains(skip, k) {
+			continue
+		}
+		if _, ok := before[k]; !ok || k == "id" {
+			return nil, errors.Errorf("unknown field: %s", k)
+		}
+		fields[k] = val
+	}
+	data, err := utils.Json.Marshal(fields)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := utils.Json.Unmarshal(data, v); err != nil {
+		return nil, errors.Wrapf(err, "invalid fields")
+	}
+	after, err := toMap(v)
+	if err != nil {
+		return nil, err
+	}
+	return changedKeys(fields, before, after), nil
+}
+
+// getAddition returns the addition in item, which is an object or a json string
+func getAddition(item map[string]interface{}) (map[string]interface{}, error) {
+	switch addition := item["addition"].(type) {
+	case nil:
+		return nil, nil
+	case map[string]interface{}:
+		return addition, nil
+	case string:
+		var m map[string]interface{}
+		if err := utils.Json.UnmarshalFromString(addition, &m); err != nil {
+			return nil, errors.Wrapf(err, "invalid addition")
+		}
+		return m, nil
+	}
+	return nil, errors.New("addition should be an object")
+}
+
+// overlayAddition sets the fields of addition in the addition of storage,
+// the others such as the refreshed tokens are kept
+func overlayAddition(addition map[string]interface{}, s *model.Storage) ([]string, error) {
+	before := make(map[string]interface{})
+	if s.Addition != "" {
+		if err := utils.Json.UnmarshalFromString(s.Addition, &before); err != nil {
+			return nil, errors.Wrapf(err, "invalid addition in database")
+		}
+	}
+	after := make(map[string]interface{}, len(before)+len(addition))
+	for k, v := range before {
+		after[k] = v
+	}
+	for k, v := range addition {
+		after[k] = v
+	}
+	var err error
+	if s.Addition, err = utils.Json.MarshalToString(after); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	fields := changedKeys(addition, before, after)
+	for i := range fields {
+		fields[i] = "addition." + fields[i]
+	}
+	return fields, nil
+}
+
+// setPassword sets the password if it doesn't match the hash, reports whether it's changed
+func setPassword(hash *string, password string, exists bool) bool {
+	if exists && utils.ComparePassword(*hash, password) {
+		return false
+	}
+	*hash = password
+	return exists
+}
+
+func toMap(v interface{}) (map[string]interface{}, error) {
+	data, err := utils.Json.Marshal(v)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var m map[string]interface{}
+	return m, errors.WithStack(utils.Json.Unmarshal(data, &m))
+}
+
+// changedKeys returns the sorted keys in item whose values are different in before and after
+func changedKeys(item, before, after map[string]interface{}) []string {
+	var keys []string
+	for k := range item {
+		b, _ := utils.Json.MarshalToString(before[k])
+		a, _ := utils.Json.MarshalToString(after[k])
+		if a != b {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func appendKey(keys []string, key string) []string {
+	if key == "" || utils.SliceContains(keys, key) {
+		return keys
+	}
+	return append(keys, key)
+}
+
+func getState() (*state, error) {
+	var s state
+	item, err := db.GetSettingItemByKey(conf.Provisioned)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if item.Value != "" {
+		if err := utils.Json.UnmarshalFromString(item.Value, &s); err != nil {
+			return nil, errors.Wrapf(err, "invalid provisioned state")
+		}
+	}
+	return &s, nil
+}
+
+func saveState(old *state, current state) error {
+	oldValue, _ := utils.Json.MarshalToString(old)
+	value, err := utils.Json.MarshalToString(current)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if value == oldValue {
+		return nil
+	}
+	return db.SaveSettingItem(model.SettingItem{Key: conf.Provisioned, Value: value, Type: conf.TypeText, Group: model.SINGLE, Flag: model.PRIVATE})
+}
@@ -0,0 +1,59 @@
+package ratelimit
+
+import (
+	"context"
+	"io"
+
+	"golang.org/x/time/rate"
+)
+
+// Reader limits the bandwidth of reading
+type Reader struct {
+	Ctx      context.Context
+	Reader   io.ReadCloser
+	Limiters []*rate.Limiter
+}
+
+func (r *Reader) Read(p []byte) (int, error) {
+	if len(p) > chunkSize {
+		p = p[:chunkSize]
+	}
+	n, err := r.Reader.Read(p)
+	if n > 0 {
+		if werr := WaitN(r.Ctx, r.Limiters, n); werr != nil && err == nil {
+			err = werr
+		}
+	}
+	return n, err
+}
+
+func (r *Reader) Close() error {
+	return r.Reader.Close()
+}
+
+// Writer limits the bandwidth of writing
+type Writer struct {
+	Ctx      context.Context
+	Writer   io.Writer
+	Limiters []*rate.Limiter
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		size := len(p)
+		if size > chunkSize {
+			size = chunkSize
+		}
+		if err := WaitN(w.Ctx, w.Limiters, size); err != nil {
+			return written, err
+		}
+		n, err := w.Writer.Write(p[:size])
+		written += n
+		if err != nil {
+			return written, err
+		}
+		p = p[size:]
+	}
+	return written, nil
+}
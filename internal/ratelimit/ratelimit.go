@@ -0,0 +1,257 @@
+// Package ratelimit limits the request rate and the bandwidth of clients by token buckets.
+// A client is a user, or an ip for the guest. The limits are read from settings
+// and take effect as soon as the settings are saved.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
+)
+
+// chunkSize is the max bytes waited at once, the burst of bandwidth limiters is at least it
+const chunkSize = 32 * 1024
+
+// the limiters of clients idle for it are dropped
+const idleTimeout = 10 * time.Minute
+
+// Limit overrides the default limits, the nil ones are not overridden and 0 means unlimited
+type Limit struct {
+	Requests *float64 `json:"requests"`
+	Download *int64   `json:"download"`
+	Upload   *int64   `json:"upload"`
+}
+
+// Rules is the value of setting rate_limit_rules
+type Rules struct {
+	Users    map[string]Limit `json:"users"`
+	Storages map[string]Limit `json:"storages"` // by mount path
+}
+
+type Direction int
+
+const (
+	Download Direction = iota
+	Upload
+)
+
+type limiters struct {
+	requests *rate.Limiter
+	download *rate.Limiter
+	upload   *rate.Limiter
+	lastUsed time.Time
+}
+
+var (
+	mu           sync.Mutex
+	requests     float64
+	download     int64
+	upload       int64
+	userDownload int64
+	userUpload   int64
+	rules        Rules
+
+	global    limiters
+	clients   = make(map[string]*limiters)
+	storages  = make(map[string]*limiters)
+	lastSweep time.Time
+)
+
+// newLimiter returns nil if it's unlimited
+func newLimiter(limit float64, bytes bool) *rate.Limiter {
+	if limit <= 0 {
+		return nil
+	}
+	burst := int(math.Ceil(limit))
+	if bytes && burst < chunkSize {
+		burst = chunkSize
+	}
+	return rate.NewLimiter(rate.Limit(limit), burst)
+}
+
+// reset drops all limiters to apply the new limits, it must be called with mu locked
+func reset() {
+	global = limiters{
+		download: newLimiter(float64(download), true),
+		upload:   newLimiter(float64(upload), true),
+	}
+	clients = make(map[string]*limiters)
+	storages = make(map[string]*limiters)
+}
+
+// sweep drops the idle limiters, it must be called with mu locked
+func sweep() {
+	now := time.Now()
+	if now.Sub(lastSweep) < time.Minute {
+		return
+	}
+	lastSweep = now
+	for _, m := range []map[string]*limiters{clients, storages} {
+		for k, l := range m {
+			if now.Sub(l.lastUsed) > idleTimeout {
+				delete(m, k)
+			}
+		}
+	}
+}
+
+func override[T int64 | float64](v *T, def T) T {
+	if v != nil {
+		return *v
+	}
+	return def
+}
+
+func clientLimiters(user *model.User, ip string) *limiters {
+	key := "ip:" + ip
+	var limit Limit
+	if user != nil && !user.IsGuest() {
+		key = "user:" + user.Username
+		limit = rules.Users[user.Username]
+	}
+	l, ok := clients[key]
+	if !ok {
+		sweep()
+		l = &limiters{
+			requests: newLimiter(override(limit.Requests, requests), false),
+			download: newLimiter(float64(override(limit.Download, userDownload)), true),
+			upload:   newLimiter(float64(override(limit.Upload, userUpload)), true),
+		}
+		clients[key] = l
+	}
+	l.lastUsed = time.Now()
+	return l
+}
+
+func storageLimiters(path string) *limiters {
+	storage, _, err := op.GetStorageAndActualPath(path)
+	if err != nil {
+		return nil
+	}
+	mountPath := storage.GetStorage().MountPath
+	limit, ok := rules.Storages[mountPath]
+	if !ok {
+		return nil
+	}
+	l, ok := storages[mountPath]
+	if !ok {
+		l = &limiters{
+			download: newLimiter(float64(override(limit.Download, 0)), true),
+			upload:   newLimiter(float64(override(limit.Upload, 0)), true),
+		}
+		storages[mountPath] = l
+	}
+	l.lastUsed = time.Now()
+	return l
+}
+
+// Allow takes a request of the client, it returns how long to wait if the request is not allowed
+func Allow(user *model.User, ip string) (time.Duration, bool) {
+	mu.Lock()
+	l := clientLimiters(user, ip).requests
+	mu.Unlock()
+	if l == nil {
+		return 0, true
+	}
+	r := l.Reserve()
+	if !r.OK() {
+		return time.Second, false
+	}
+	if d := r.Delay(); d > 0 {
+		r.Cancel()
+		return d, false
+	}
+	return 0, true
+}
+
+// Limiters returns the bandwidth limiters of the transfer of client on the path, path can be empty
+func Limiters(dir Direction, user *model.User, ip, path string) []*rate.Limiter {
+	mu.Lock()
+	defer mu.Unlock()
+	all := []*limiters{&global, clientLimiters(user, ip)}
+	if path != "" {
+		if l := storageLimiters(path); l != nil {
+			all = append(all, l)
+		}
+	}
+	var res []*rate.Limiter
+	for _, l := range all {
+		limiter := l.download
+		if dir == Upload {
+			limiter = l.upload
+		}
+		if limiter != nil {
+			res = append(res, limiter)
+		}
+	}
+	return res
+}
+
+// WaitN waits until all the limiters allow n bytes
+func WaitN(ctx context.Context, limiters []*rate.Limiter, n int) error {
+	for _, l := range limiters {
+		for left := n; left > 0; left -= chunkSize {
+			size := left
+			if size > chunkSize {
+				size = chunkSize
+			}
+			if err := l.WaitN(ctx, size); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func int64Hook(v *int64) db.SettingItemHook {
+	return func(item *model.SettingItem) error {
+		n, err := strconv.ParseInt(item.Value, 10, 64)
+		if err != nil {
+			return errors.Wrapf(err, "invalid value of [%s]", item.Key)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		*v = n
+		reset()
+		return nil
+	}
+}
+
+func init() {
+	db.RegisterSettingItemHook(conf.RateLimitRequests, func(item *model.SettingItem) error {
+		n, err := strconv.ParseFloat(item.Value, 64)
+		if err != nil {
+			return errors.Wrapf(err, "invalid value of [%s]", item.Key)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		requests = n
+		reset()
+		return nil
+	})
+	db.RegisterSettingItemHook(conf.RateLimitDownload, int64Hook(&download))
+	db.RegisterSettingItemHook(conf.RateLimitUpload, int64Hook(&upload))
+	db.RegisterSettingItemHook(conf.RateLimitUserDownload, int64Hook(&userDownload))
+	db.RegisterSettingItemHook(conf.RateLimitUserUpload, int64Hook(&userUpload))
+	db.RegisterSettingItemHook(conf.RateLimitRules, func(item *model.SettingItem) error {
+		var r Rules
+		if err := utils.Json.UnmarshalFromString(item.Value, &r); err != nil {
+			return errors.Wrapf(err, "invalid value of [%s]", item.Key)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		rules = r
+		reset()
+		return nil
+	})
+}
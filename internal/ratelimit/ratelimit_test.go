@@ -0,0 +1,66 @@
+package ratelimit
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+func setSetting(t *testing.T, key, value string) {
+	if _, err := db.HandleSettingItem(&model.SettingItem{Key: key, Value: value}); err != nil {
+		t.Fatalf("failed set %s: %+v", key, err)
+	}
+}
+
+func TestAllow(t *testing.T) {
+	setSetting(t, conf.RateLimitRequests, "1")
+	setSetting(t, conf.RateLimitRules, `{"users":{"alice":{"requests":0}}}`)
+	defer setSetting(t, conf.RateLimitRequests, "0")
+	defer setSetting(t, conf.RateLimitRules, "{}")
+
+	if _, ok := Allow(nil, "1.1.1.1"); !ok {
+		t.Fatalf("the first request should be allowed")
+	}
+	d, ok := Allow(nil, "1.1.1.1")
+	if ok || d <= 0 {
+		t.Errorf("the second request should wait, got %v %v", d, ok)
+	}
+	if _, ok := Allow(nil, "2.2.2.2"); !ok {
+		t.Errorf("the request of another ip should be allowed")
+	}
+	alice := &model.User{Username: "alice"}
+	for i := 0; i < 10; i++ {
+		if _, ok := Allow(alice, "1.1.1.1"); !ok {
+			t.Fatalf("the requests of alice are unlimited")
+		}
+	}
+}
+
+func TestWriter(t *testing.T) {
+	setSetting(t, conf.RateLimitUserDownload, "32768")
+	defer setSetting(t, conf.RateLimitUserDownload, "0")
+
+	limiters := Limiters(Download, nil, "3.3.3.3", "")
+	if len(limiters) != 1 {
+		t.Fatalf("expect 1 limiter, got %d", len(limiters))
+	}
+	var buf bytes.Buffer
+	w := &Writer{Ctx: context.Background(), Writer: &buf, Limiters: limiters}
+	start := time.Now()
+	// the burst is 32KB, the rest 16KB takes about 0.5s
+	n, err := w.Write(make([]byte, 48*1024))
+	if err != nil || n != 48*1024 {
+		t.Fatalf("failed write: %d %+v", n, err)
+	}
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond {
+		t.Errorf("the write is not limited, took %v", elapsed)
+	}
+	if len(Limiters(Upload, nil, "3.3.3.3", "")) != 0 {
+		t.Errorf("the upload should be unlimited")
+	}
+}
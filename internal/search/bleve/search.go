@@ -3,6 +3,7 @@ package bleve
 import (
 	"context"
 	"os"
+	"time"
 
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/errs"
@@ -11,6 +12,7 @@ import (
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/blevesearch/bleve/v2"
 	search2 "github.com/blevesearch/bleve/v2/search"
+	"github.com/blevesearch/bleve/v2/search/query"
 	"github.com/google/uuid"
 	log "github.com/sirupsen/logrus"
 )
@@ -19,40 +21,144 @@ type Bleve struct {
 	BIndex bleve.Index
 }
 
+// document is the indexed SearchNode with its content,
+// the content of SearchNode is not marshaled so it's indexed here
+type document struct {
+	Parent   string    `json:"parent"`
+	Name     string    `json:"name"`
+	IsDir    bool      `json:"is_dir"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+	FileType int       `json:"file_type"`
+	Content  string    `json:"content,omitempty"`
+}
+
+func (d *document) Type() string {
+	return "SearchNode"
+}
+
+func toDocument(node model.SearchNode) *document {
+	return &document{
+		Parent:   node.Parent,
+		Name:     node.Name,
+		IsDir:    node.IsDir,
+		Size:     node.Size,
+		Modified: node.Modified,
+		FileType: node.FileType,
+		Content:  node.Content,
+	}
+}
+
 func (b *Bleve) Config() searcher.Config {
 	return config
 }
 
+// buildQuery builds the query with the filters of req, which is the same as the database searcher
+func buildQuery(req model.SearchReq) query.Query {
+	var queries []query.Query
+	inclusive := true
+	switch req.Mode {
+	case model.MatchGlob:
+		q := bleve.NewWildcardQuery(req.Keywords)
+		q.SetField("name")
+		queries = append(queries, q)
+	case model.MatchRegex:
+		q := bleve.NewRegexpQuery(req.Keywords)
+		q.SetField("name")
+		queries = append(queries, q)
+	default:
+		if req.Keywords != "" {
+			nameQuery := bleve.NewMatchQuery(req.Keywords)
+			nameQuery.SetField("name")
+			contentQuery := bleve.NewMatchQuery(req.Keywords)
+			contentQuery.SetField("content")
+			queries = append(queries, bleve.NewDisjunctionQuery(nameQuery, contentQuery))
+		}
+	}
+	if req.Scope != model.ScopeAll {
+		q := bleve.NewBoolFieldQuery(req.Scope == model.ScopeFolders)
+		q.SetField("is_dir")
+		queries = append(queries, q)
+	}
+	if len(req.Types) > 0 {
+		typeQuery := bleve.NewDisjunctionQuery()
+		for i := range req.Types {
+			t := float64(req.Types[i])
+			q := bleve.NewNumericRangeInclusiveQuery(&t, &t, &inclusive, &inclusive)
+			q.SetField("file_type")
+			typeQuery.AddQuery(q)
+		}
+		queries = append(queries, typeQuery)
+	}
+	if req.MinSize > 0 || req.MaxSize > 0 {
+		var min, max *float64
+		if req.MinSize > 0 {
+			minSize := float64(req.MinSize)
+			min = &minSize
+		}
+		if req.MaxSize > 0 {
+			maxSize := float64(req.MaxSize)
+			max = &maxSize
+		}
+		q := bleve.NewNumericRangeInclusiveQuery(min, max, &inclusive, &inclusive)
+		q.SetField("size")
+		queries = append(queries, q)
+	}
+	if !req.ModifiedAfter.IsZero() || !req.ModifiedBefore.IsZero() {
+		q := bleve.NewDateRangeInclusiveQuery(req.ModifiedAfter, req.ModifiedBefore, &inclusive, &inclusive)
+		q.SetField("modified")
+		queries = append(queries, q)
+	}
+	if len(queries) == 0 {
+		return bleve.NewMatchAllQuery()
+	}
+	return bleve.NewConjunctionQuery(queries...)
+}
+
 func (b *Bleve) Search(ctx context.Context, req model.SearchReq) ([]model.SearchNode, int64, error) {
-	query := bleve.NewMatchQuery(req.Keywords)
-	query.SetField("name")
-	search := bleve.NewSearchRequest(query)
+	search := bleve.NewSearchRequest(buildQuery(req))
+	search.From = (req.Page - 1) * req.PerPage
 	search.Size = req.PerPage
-	search.Fields = []string{"*"}
+	search.Fields = []string{"parent", "name", "is_dir", "size", "modified"}
+	if req.OrderBy != "" {
+		order := req.OrderBy
+		if req.OrderDirection == "desc" {
+			order = "-" + order
+		}
+		search.SortBy([]string{order})
+	}
+	search.Highlight = bleve.NewHighlight()
+	search.Highlight.AddField("content")
 	searchResults, err := b.BIndex.Search(search)
 	if err != nil {
 		log.Errorf("search error: %+v", err)
 		return nil, 0, err
 	}
 	res, err := utils.SliceConvert(searchResults.Hits, func(src *search2.DocumentMatch) (model.SearchNode, error) {
-		return model.SearchNode{
-			Parent: src.Fields["parent"].(string),
-			Name:   src.Fields["name"].(string),
-			IsDir:  src.Fields["is_dir"].(bool),
-			Size:   int64(src.Fields["size"].(float64)),
-		}, nil
+		node := model.SearchNode{
+			Parent:     src.Fields["parent"].(string),
+			Name:       src.Fields["name"].(string),
+			IsDir:      src.Fields["is_dir"].(bool),
+			Size:       int64(src.Fields["size"].(float64)),
+			Highlights: src.Fragments["content"],
+		}
+		// the modified is missing in the index built by old versions
+		if modified, ok := src.Fields["modified"].(string); ok {
+			node.Modified, _ = time.Parse(time.RFC3339, modified)
+		}
+		return node, nil
 	})
-	return res, int64(len(res)), nil
+	return res, int64(searchResults.Total), err
 }
 
 func (b *Bleve) Index(ctx context.Context, node model.SearchNode) error {
-	return b.BIndex.Index(uuid.NewString(), node)
+	return b.BIndex.Index(uuid.NewString(), toDocument(node))
 }
 
 func (b *Bleve) BatchIndex(ctx context.Context, nodes []model.SearchNode) error {
 	batch := b.BIndex.NewBatch()
 	for _, node := range nodes {
-		batch.Index(uuid.NewString(), node)
+		batch.Index(uuid.NewString(), toDocument(node))
 	}
 	return b.BIndex.Batch(batch)
 }
@@ -0,0 +1,96 @@
+package search
+
+import (
+	"context"
+	"io"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// Extractor extracts the text from the content of file, the reader is limited by the max size
+type Extractor func(r io.Reader) (string, error)
+
+var extractors = map[string]Extractor{}
+
+// RegisterExtractor registers the extractor for the file extensions, e.g. pdf, docx
+func RegisterExtractor(extractor Extractor, exts ...string) {
+	for _, ext := range exts {
+		extractors[strings.ToLower(ext)] = extractor
+	}
+}
+
+// getExtractor returns the extractor of file, the types in text_types are extracted as plain text
+func getExtractor(name string) Extractor {
+	ext := strings.ToLower(utils.Ext(name))
+	if extractor, ok := extractors[ext]; ok {
+		return extractor
+	}
+	if utils.GetFileType(name) == conf.TEXT {
+		return extractText
+	}
+	return nil
+}
+
+func extractText(r io.Reader) (string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	if !utf8.Valid(data) {
+		// maybe cut in the middle of a rune by the limit
+		return strings.ToValidUTF8(string(data), ""), nil
+	}
+	return string(data), nil
+}
+
+// shouldIndexContent checks if the content of file should be indexed by the settings
+func shouldIndexContent(parent string, obj model.Obj) bool {
+	if instance == nil || !instance.Config().Content || obj.IsDir() || !setting.GetBool(conf.IndexContent) {
+		return false
+	}
+	if obj.GetSize() > int64(setting.GetInt(conf.IndexContentMaxSize, 1024*1024)) {
+		return false
+	}
+	if contentPaths := setting.GetStr(conf.IndexContentPaths); contentPaths != "" &&
+		!isIndexPath(parent, strings.Split(contentPaths, "\n")) {
+		return false
+	}
+	return getExtractor(obj.GetName()) != nil
+}
+
+// extractContent streams the file and returns the text of it,
+// the errors are logged only so that the file is still indexed by name
+func extractContent(ctx context.Context, parent string, obj model.Obj) string {
+	if !shouldIndexContent(parent, obj) {
+		return ""
+	}
+	filePath := path.Join(parent, obj.GetName())
+	content, err := func() (string, error) {
+		// the range reader reads the local files in place, the stream would copy them to the temp dir
+		rangeReader, _, err := fs.GetRangeReader(ctx, filePath)
+		if err != nil {
+			return "", err
+		}
+		rc, err := rangeReader(ctx, 0, -1)
+		if err != nil {
+			return "", err
+		}
+		defer rc.Close()
+		maxSize := int64(setting.GetInt(conf.IndexContentMaxSize, 1024*1024))
+		return getExtractor(obj.GetName())(io.LimitReader(rc, maxSize))
+	}()
+	if err != nil {
+		log.Warnf("failed extract content of %s: %+v", filePath, errors.WithStack(err))
+		return ""
+	}
+	return content
+}
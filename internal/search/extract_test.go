@@ -0,0 +1,133 @@
+package search
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/alist-org/alist/v3/drivers/local"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	conf.Conf = conf.DefaultConfig()
+	d, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	db.Init(d)
+	conf.TypesMap[conf.TextTypes] = []string{"txt", "md"}
+}
+
+// setupContent enables indexing the content under the paths with the bleve searcher
+func setupContent(t *testing.T, paths string) {
+	conf.Conf.TempDir = t.TempDir()
+	conf.Conf.BleveDir = filepath.Join(t.TempDir(), "bleve")
+	if err := Init("bleve"); err != nil {
+		t.Fatalf("failed init bleve: %+v", err)
+	}
+	t.Cleanup(func() {
+		_ = Init("none")
+	})
+	err := db.SaveSettingItems([]model.SettingItem{
+		{Key: conf.IndexContent, Value: "true", Type: conf.TypeBool, Group: model.INDEX},
+		{Key: conf.IndexContentMaxSize, Value: "100", Type: conf.TypeNumber, Group: model.INDEX},
+		{Key: conf.IndexContentPaths, Value: paths, Type: conf.TypeText, Group: model.INDEX},
+	})
+	if err != nil {
+		t.Fatalf("failed save settings: %+v", err)
+	}
+}
+
+func TestShouldIndexContent(t *testing.T) {
+	setupContent(t, "/docs")
+	RegisterExtractor(func(r io.Reader) (string, error) { return "", nil }, "pdf")
+	tests := []struct {
+		name   string
+		parent string
+		obj    model.Obj
+		want   bool
+	}{
+		{"text", "/docs", &model.Object{Name: "a.txt", Size: 10}, true},
+		{"sub path", "/docs/sub", &model.Object{Name: "a.md", Size: 10}, true},
+		{"extractor", "/docs", &model.Object{Name: "a.PDF", Size: 10}, true},
+		{"folder", "/docs", &model.Object{Name: "dir.txt", IsFolder: true}, false},
+		{"too large", "/docs", &model.Object{Name: "a.txt", Size: 101}, false},
+		{"out of paths", "/other", &model.Object{Name: "a.txt", Size: 10}, false},
+		{"not text", "/docs", &model.Object{Name: "a.mp4", Size: 10}, false},
+	}
+	for _, tt := range tests {
+		if got := shouldIndexContent(tt.parent, tt.obj); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if err := db.SaveSettingItem(model.SettingItem{Key: conf.IndexContent, Value: "false", Type: conf.TypeBool, Group: model.INDEX}); err != nil {
+		t.Fatal(err)
+	}
+	if shouldIndexContent("/docs", &model.Object{Name: "a.txt", Size: 10}) {
+		t.Errorf("the content shouldn't be indexed if it's disabled")
+	}
+}
+
+func TestExtractText(t *testing.T) {
+	// the limit cuts the last rune in the middle
+	text, err := extractText(io.LimitReader(strings.NewReader("hello 世界"), 10))
+	if err != nil || text != "hello 世" {
+		t.Errorf("unexpected text: %q %+v", text, err)
+	}
+}
+
+func TestContentHighlights(t *testing.T) {
+	setupContent(t, "")
+	root := t.TempDir()
+	files := map[string]string{
+		"note.txt":  "the quick brown fox jumps over the lazy dog",
+		"other.txt": "nothing to see here",
+		"large.txt": strings.Repeat("fox ", 30),
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx := context.Background()
+	addition := `{"root_folder_path":"` + filepath.ToSlash(root) + `"}`
+	if _, err := op.CreateStorage(ctx, model.Storage{Driver: "Local", MountPath: "/notes", Addition: addition}); err != nil {
+		t.Fatalf("failed create local storage: %+v", err)
+	}
+	var objs []ObjWithParent
+	for name, content := range files {
+		objs = append(objs, ObjWithParent{Parent: "/notes", Obj: &model.Object{Name: name, Size: int64(len(content)), Modified: time.Now()}})
+	}
+	note := &model.Object{Name: "note.txt", Size: int64(len(files["note.txt"]))}
+	if got := extractContent(ctx, "/notes", note); got != files["note.txt"] {
+		t.Errorf("unexpected content: %q", got)
+	}
+	if err := BatchIndex(ctx, objs); err != nil {
+		t.Fatalf("failed index: %+v", err)
+	}
+	// the indexed files aren't copied to the temp dir
+	if entries, _ := os.ReadDir(conf.Conf.TempDir); len(entries) > 0 {
+		t.Errorf("unexpected temp files: %v", entries)
+	}
+	nodes, _, err := Search(ctx, model.SearchReq{Keywords: "fox", PageReq: model.PageReq{Page: 1, PerPage: 10}})
+	if err != nil {
+		t.Fatalf("failed search: %+v", err)
+	}
+	// the large file is indexed by name only
+	if len(nodes) != 1 || nodes[0].Name != "note.txt" {
+		t.Fatalf("unexpected nodes: %+v", nodes)
+	}
+	if len(nodes[0].Highlights) == 0 || !strings.Contains(nodes[0].Highlights[0], "<mark>fox</mark>") {
+		t.Errorf("unexpected highlights: %v", nodes[0].Highlights)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/search/searcher"
+	"github.com/alist-org/alist/v3/pkg/utils"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -56,10 +57,13 @@ func Index(ctx context.Context, parent string, obj model.Obj) error {
 		return errs.SearchNotAvailable
 	}
 	return instance.Index(ctx, model.SearchNode{
-		Parent: parent,
-		Name:   obj.GetName(),
-		IsDir:  obj.IsDir(),
-		Size:   obj.GetSize(),
+		Parent:   parent,
+		Name:     obj.GetName(),
+		IsDir:    obj.IsDir(),
+		Size:     obj.GetSize(),
+		Modified: obj.ModTime(),
+		FileType: utils.GetObjType(obj.GetName(), obj.IsDir()),
+		Content:  extractContent(ctx, parent, obj),
 	})
 }
 
@@ -78,10 +82,13 @@ func BatchIndex(ctx context.Context, objs []ObjWithParent) error {
 	var searchNodes []model.SearchNode
 	for i := range objs {
 		searchNodes = append(searchNodes, model.SearchNode{
-			Parent: objs[i].Parent,
-			Name:   objs[i].GetName(),
-			IsDir:  objs[i].IsDir(),
-			Size:   objs[i].GetSize(),
+			Parent:   objs[i].Parent,
+			Name:     objs[i].GetName(),
+			IsDir:    objs[i].IsDir(),
+			Size:     objs[i].GetSize(),
+			Modified: objs[i].ModTime(),
+			FileType: utils.GetObjType(objs[i].GetName(), objs[i].IsDir()),
+			Content:  extractContent(ctx, objs[i].Parent, objs[i]),
 		})
 	}
 	return instance.BatchIndex(ctx, searchNodes)
@@ -9,6 +9,8 @@ import (
 type Config struct {
 	Name       string
 	AutoUpdate bool
+	// Content is true if the searcher can index the content of files
+	Content bool
 }
 
 type Searcher interface {
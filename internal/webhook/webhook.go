@@ -0,0 +1,174 @@
+// Package webhook posts the events of op to the webhooks matching them.
+// The deliveries are retried with backoff and recorded in database.
+// The payload is signed by pkg/sign with the secret of webhook, receivers can verify
+// the header X-Alist-Signature with sign.NewHMACSign(secret).Verify(body, signature).
+package webhook
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/sign"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// EventPing is sent by Ping to test the webhook
+const EventPing op.EventType = "ping"
+
+const (
+	maxAttempts = 5
+	// the first retry is after it, then doubled every time
+	retryInterval = 10 * time.Second
+	// the signature expires after it
+	signatureExpire = 5 * time.Minute
+	// the deliveries older than it are deleted by Clean
+	retention = 30 * 24 * time.Hour
+	workers   = 4
+)
+
+var (
+	httpClient = &http.Client{Timeout: 30 * time.Second}
+	queue      = make(chan *job, 1024)
+)
+
+type job struct {
+	hook     model.Webhook
+	delivery *model.WebhookDelivery
+}
+
+// Init subscribes the events and starts the workers
+func Init() {
+	op.RegisterEventHandler(handle)
+	for i := 0; i < workers; i++ {
+		go func() {
+			for j := range queue {
+				deliver(j)
+			}
+		}()
+	}
+}
+
+func handle(e op.Event) {
+	hooks, err := db.GetEnabledWebhooks()
+	if err != nil {
+		log.Errorf("failed get webhooks: %+v", err)
+		return
+	}
+	for _, hook := range hooks {
+		if hook.MatchEvent(string(e.Type)) && hook.MatchPath(e.Path, e.Dst) {
+			send(hook, e)
+		}
+	}
+}
+
+func send(hook model.Webhook, e op.Event) {
+	payload, err := utils.Json.MarshalToString(e)
+	if err != nil {
+		log.Errorf("failed marshal event: %+v", err)
+		return
+	}
+	enqueue(&job{hook: hook, delivery: &model.WebhookDelivery{
+		WebhookID: hook.ID,
+		Event:     string(e.Type),
+		Payload:   payload,
+	}})
+}
+
+// enqueue never blocks the emitter, the job is dropped if the queue is full
+func enqueue(j *job) {
+	select {
+	case queue <- j:
+	default:
+		log.Warnf("webhook queue is full, drop the [%s] event to [%s]", j.delivery.Event, j.hook.Name)
+	}
+}
+
+// Ping sends a ping event to the webhook regardless of its filters
+func Ping(hook model.Webhook) {
+	send(hook, op.Event{Type: EventPing, Time: time.Now()})
+}
+
+// enabled checks if the webhook is still enabled, the retries of the deleted or disabled webhooks are dropped
+func enabled(id uint) bool {
+	hooks, err := db.GetEnabledWebhooks()
+	if err != nil {
+		return false
+	}
+	for _, hook := range hooks {
+		if hook.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func deliver(j *job) {
+	d := j.delivery
+	if d.Attempts > 0 && !enabled(d.WebhookID) {
+		return
+	}
+	if d.ID == 0 {
+		if err := db.CreateWebhookDelivery(d); err != nil {
+			log.Errorf("failed save webhook delivery: %+v", err)
+		}
+	}
+	d.Attempts++
+	d.StatusCode, d.Error = 0, ""
+	code, err := post(j.hook, d)
+	d.StatusCode = code
+	d.Success = err == nil
+	if err != nil {
+		d.Error = err.Error()
+	}
+	if d.ID != 0 {
+		if err := db.UpdateWebhookDelivery(d); err != nil {
+			log.Errorf("failed update webhook delivery: %+v", err)
+		}
+	}
+	if err != nil && d.Attempts < maxAttempts {
+		time.AfterFunc(retryInterval<<(d.Attempts-1), func() { enqueue(j) })
+	}
+}
+
+func post(hook model.Webhook, d *model.WebhookDelivery) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewBufferString(d.Payload))
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "alist-webhook")
+	req.Header.Set("X-Alist-Event", d.Event)
+	req.Header.Set("X-Alist-Delivery", strconv.FormatUint(uint64(d.ID), 10))
+	if hook.Secret != "" {
+		signature := sign.NewHMACSign([]byte(hook.Secret)).Sign(d.Payload, time.Now().Add(signatureExpire).Unix())
+		req.Header.Set("X-Alist-Signature", signature)
+	}
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return res.StatusCode, errors.Errorf("unexpected status: %s", res.Status)
+	}
+	return res.StatusCode, nil
+}
+
+// Clean deletes the deliveries out of retention
+func Clean() {
+	n, err := db.DeleteWebhookDeliveriesBefore(time.Now().Add(-retention))
+	if err != nil {
+		log.Errorf("failed clean webhook deliveries: %+v", err)
+		return
+	}
+	if n > 0 {
+		log.Infof("deleted %d expired webhook deliveries", n)
+	}
+}
@@ -0,0 +1,73 @@
+package webhook
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/sign"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	conf.Conf = conf.DefaultConfig()
+	d, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	db.Init(d)
+}
+
+func TestDeliver(t *testing.T) {
+	ch := make(chan op.Event, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := sign.NewHMACSign([]byte("secret")).Verify(string(body), r.Header.Get("X-Alist-Signature")); err != nil {
+			t.Errorf("invalid signature: %+v", err)
+		}
+		var e op.Event
+		if err := utils.Json.Unmarshal(body, &e); err != nil {
+			t.Errorf("invalid payload: %+v", err)
+		}
+		ch <- e
+	}))
+	defer srv.Close()
+
+	hook := model.Webhook{Name: "ci", URL: srv.URL, Secret: "secret", Events: "fs.upload,fs.move", Paths: "/docs"}
+	if err := db.CreateWebhook(&hook); err != nil {
+		t.Fatalf("failed create webhook: %+v", err)
+	}
+	Init()
+	op.Emit(op.Event{Type: op.EventRemove, Path: "/docs/a.txt"})
+	op.Emit(op.Event{Type: op.EventUpload, Path: "/other/a.txt"})
+	op.Emit(op.Event{Type: op.EventMove, Path: "/other/b.txt", Dst: "/docs/b.txt"})
+
+	select {
+	case e := <-ch:
+		if e.Type != op.EventMove || e.Dst != "/docs/b.txt" {
+			t.Errorf("unexpected event: %+v", e)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the event is not delivered")
+	}
+	select {
+	case e := <-ch:
+		t.Errorf("the event should be filtered: %+v", e)
+	case <-time.After(200 * time.Millisecond):
+	}
+	deliveries, total, err := db.GetWebhookDeliveries(hook.ID, 1, 10)
+	if err != nil || total != 1 {
+		t.Fatalf("expect 1 delivery, got %d %+v", total, err)
+	}
+	if d := deliveries[0]; !d.Success || d.StatusCode != 200 || d.Attempts != 1 {
+		t.Errorf("unexpected delivery: %+v", d)
+	}
+}
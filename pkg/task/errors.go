@@ -5,4 +5,7 @@ import "errors"
 var (
 	ErrTaskNotFound = errors.New("task not found")
 	ErrTaskRunning  = errors.New("task is running")
+
+	ErrTaskNotResumable = errors.New("task can't be resumed")
+	ErrInterrupted      = errors.New("task interrupted by restart")
 )
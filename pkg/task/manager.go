@@ -12,14 +12,38 @@ type Manager[K comparable] struct {
 	workerC  chan struct{}
 	updateID func(*K)
 	tasks    generic_sync.MapOf[K, *Task[K]]
+
+	persistence *Persistence[K]
+	onDone      Callback[K]
+}
+
+// OnDone sets the callback called when a root task is done, whether it succeeded or not
+func (tm *Manager[K]) OnDone(callback Callback[K]) {
+	tm.onDone = callback
+}
+
+// done calls the callback of onDone if the task is a root
+func (tm *Manager[K]) done(task *Task[K]) {
+	if tm.onDone != nil && task.parent == nil && task.Done() {
+		tm.onDone(task)
+	}
+	// the parent waiting for children is notified instead of polling
+	if task.parent != nil && task.Done() {
+		tm.finishWaiting(task.parent)
+	}
 }
 
 func (tm *Manager[K]) Submit(task *Task[K]) K {
 	if tm.updateID != nil {
 		tm.updateID(&tm.curID)
+		// skip the ids used by restored tasks
+		for tm.tasks.Has(tm.curID) {
+			tm.updateID(&tm.curID)
+		}
 		task.ID = tm.curID
 	}
 	tm.tasks.Store(task.ID, task)
+	tm.save(task)
 	tm.do(task)
 	return task.ID
 }
@@ -30,14 +54,25 @@ func (tm *Manager[K]) do(task *Task[K]) {
 		select {
 		case <-tm.workerC:
 			log.Debugf("task [%s] starting", task.Name)
-			task.run()
+			task.state = RUNNING
+			tm.save(task)
+			waiting := task.run()
+			tm.save(task)
 			log.Debugf("task [%s] ended", task.Name)
+			// return worker
+			tm.workerC <- struct{}{}
+			// wait children without worker, otherwise the children maybe can't get a worker
+			if waiting {
+				tm.waitChildren(task)
+			} else {
+				tm.done(task)
+			}
 		case <-task.Ctx.Done():
 			log.Debugf("task [%s] canceled", task.Name)
-			return
+			task.state = CANCELED
+			tm.save(task)
+			tm.done(task)
 		}
-		// return worker
-		tm.workerC <- struct{}{}
 	}()
 }
 
@@ -54,13 +89,28 @@ func (tm *Manager[K]) MustGet(tid K) *Task[K] {
 	return task
 }
 
+// ChangeID change the id of a task, such as an aria2 task followed by a new gid
+func (tm *Manager[K]) ChangeID(oldID, newID K) {
+	t, ok := tm.Get(oldID)
+	if !ok {
+		return
+	}
+	tm.tasks.Delete(oldID)
+	tm.delete(oldID)
+	t.ID = newID
+	tm.tasks.Store(newID, t)
+	tm.save(t)
+}
+
 func (tm *Manager[K]) Retry(tid K) error {
 	t, ok := tm.Get(tid)
 	if !ok {
 		return errors.WithStack(ErrTaskNotFound)
 	}
-	tm.do(t)
-	return nil
+	if !t.Done() {
+		return errors.WithStack(ErrTaskRunning)
+	}
+	return tm.retry(t)
 }
 
 func (tm *Manager[K]) Cancel(tid K) error {
@@ -69,6 +119,7 @@ func (tm *Manager[K]) Cancel(tid K) error {
 		return errors.WithStack(ErrTaskNotFound)
 	}
 	t.Cancel()
+	tm.save(t)
 	return nil
 }
 
@@ -80,29 +131,32 @@ func (tm *Manager[K]) Remove(tid K) error {
 	if !t.Done() {
 		return errors.WithStack(ErrTaskRunning)
 	}
-	tm.tasks.Delete(tid)
+	tm.removeTree(t)
 	return nil
 }
 
 // RemoveAll removes all tasks from the manager, this maybe shouldn't be used
 // because the task maybe still running.
 func (tm *Manager[K]) RemoveAll() {
+	for _, task := range tm.GetAll() {
+		tm.delete(task.ID)
+	}
 	tm.tasks.Clear()
 }
 
 func (tm *Manager[K]) RemoveByStates(states ...string) {
-	tasks := tm.GetAll()
+	tasks := tm.GetByStates(states...)
 	for _, task := range tasks {
-		if utils.SliceContains(states, task.GetState()) {
-			_ = tm.Remove(task.ID)
-		}
+		_ = tm.Remove(task.ID)
 	}
 }
 
+// GetByStates returns the tasks without parent in the states,
+// the children can be got by GetChildren of their parents.
 func (tm *Manager[K]) GetByStates(states ...string) []*Task[K] {
 	var tasks []*Task[K]
 	tm.tasks.Range(func(key K, value *Task[K]) bool {
-		if utils.SliceContains(states, value.GetState()) {
+		if value.parent == nil && utils.SliceContains(states, value.GetState()) {
 			tasks = append(tasks, value)
 		}
 		return true
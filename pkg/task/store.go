@@ -0,0 +1,180 @@
+package task
+
+import (
+	"fmt"
+
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// Info is the persistent form of a task
+type Info struct {
+	ID       string
+	ParentID string // empty if the task has no parent
+	Kind     string
+	Name     string
+	Args     string // json encoded arguments, used to resume the task
+	State    string
+	Status   string
+	Progress int
+	Size     int64
+	Error    string
+}
+
+// Store is used by Manager to persist tasks, so that they can survive restarts
+type Store interface {
+	SaveTask(info *Info) error
+	DeleteTask(kind string, id string) error
+	GetTasks(kind string) ([]Info, error)
+}
+
+// Persistence describes how the tasks of a Manager are persisted and resumed
+type Persistence[K comparable] struct {
+	Kind    string
+	Store   Store
+	ParseID func(id string) (K, error)
+	// Resume rebuilds the Func of an unfinished task from its Info,
+	// the task will be marked as errored if it returns nil.
+	Resume func(info *Info) Func[K]
+}
+
+func (t *Task[K]) info(kind string) *Info {
+	info := &Info{
+		ID:       fmt.Sprint(t.ID),
+		Kind:     kind,
+		Name:     t.Name,
+		Args:     t.Args,
+		State:    t.state,
+		Status:   t.status,
+		Progress: t.progress,
+		Size:     t.size,
+		Error:    t.GetErrMsg(),
+	}
+	if t.parent != nil {
+		info.ParentID = fmt.Sprint(t.parent.ID)
+	}
+	return info
+}
+
+// SetArgs set the json encoded arguments of the task, which will be persisted
+func (t *Task[K]) SetArgs(args interface{}) *Task[K] {
+	s, err := utils.Json.MarshalToString(args)
+	if err != nil {
+		log.Errorf("failed marshal args of task [%s]: %+v", t.Name, err)
+		return t
+	}
+	t.Args = s
+	return t
+}
+
+func (tm *Manager[K]) save(t *Task[K]) {
+	if tm.persistence == nil {
+		return
+	}
+	if err := tm.persistence.Store.SaveTask(t.info(tm.persistence.Kind)); err != nil {
+		log.Errorf("failed save task [%s]: %+v", t.Name, err)
+	}
+}
+
+func (tm *Manager[K]) delete(tid K) {
+	if tm.persistence == nil {
+		return
+	}
+	if err := tm.persistence.Store.DeleteTask(tm.persistence.Kind, fmt.Sprint(tid)); err != nil {
+		log.Errorf("failed delete task [%v]: %+v", tid, err)
+	}
+}
+
+// Persist set the persistence of the manager and restore the persisted tasks,
+// finished tasks are kept as history, unfinished tasks are resumed or marked as errored.
+// An unfinished task with parent is resumed by its root, so only the roots are resumed.
+func (tm *Manager[K]) Persist(p *Persistence[K]) error {
+	tm.persistence = p
+	infos, err := p.Store.GetTasks(p.Kind)
+	if err != nil {
+		return errors.WithMessagef(err, "failed get [%s] tasks", p.Kind)
+	}
+	tasks := make(map[string]*Task[K], len(infos))
+	for i := range infos {
+		info := &infos[i]
+		id, err := p.ParseID(info.ID)
+		if err != nil {
+			log.Warnf("invalid id of [%s] task [%s]: %+v", p.Kind, info.ID, err)
+			continue
+		}
+		t := &Task[K]{
+			ID:       id,
+			Name:     info.Name,
+			Args:     info.Args,
+			state:    info.State,
+			status:   info.Status,
+			progress: info.Progress,
+			size:     info.Size,
+		}
+		if info.Error != "" {
+			t.Error = errors.New(info.Error)
+		}
+		tasks[info.ID] = t
+		tm.tasks.Store(id, t)
+	}
+	// link the children to their parents, the infos are ordered by creation
+	var roots []*Task[K]
+	for i := range infos {
+		t, ok := tasks[infos[i].ID]
+		if !ok {
+			continue
+		}
+		if parent, ok := tasks[infos[i].ParentID]; ok && infos[i].ParentID != "" {
+			parent.addChild(t)
+			// a task has children only if its func succeeded
+			parent.funcSucceeded = true
+		} else {
+			roots = append(roots, t)
+		}
+	}
+	for _, t := range roots {
+		tm.restore(t)
+	}
+	return nil
+}
+
+// restore resumes the unfinished root task, or marks its unfinished descendants as errored
+func (tm *Manager[K]) restore(t *Task[K]) {
+	p := tm.persistence
+	if t.Done() {
+		tm.interruptChildren(t)
+		return
+	}
+	// the func of the root will submit the children again
+	for _, child := range t.GetChildren() {
+		tm.removeTree(child)
+	}
+	t.funcSucceeded = false
+	var f Func[K]
+	if p.Resume != nil {
+		f = p.Resume(t.info(p.Kind))
+	}
+	if f == nil {
+		t.state = ERRORED
+		t.Error = ErrInterrupted
+		tm.save(t)
+		return
+	}
+	t.Func = f
+	WithCancelCtx(t)
+	tm.save(t)
+	log.Infof("resume [%s] task [%s]", p.Kind, t.Name)
+	tm.do(t)
+}
+
+func (tm *Manager[K]) interruptChildren(t *Task[K]) {
+	for _, child := range t.GetChildren() {
+		if !child.Done() {
+			child.state = ERRORED
+			child.Error = ErrInterrupted
+			tm.save(child)
+		}
+		tm.interruptChildren(child)
+	}
+}
@@ -3,6 +3,7 @@ package task
 
 import (
 	"context"
+	"sync"
 
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -23,9 +24,11 @@ type Callback[K comparable] func(task *Task[K])
 type Task[K comparable] struct {
 	ID       K
 	Name     string
+	Args     string // json encoded arguments, see SetArgs
 	state    string // pending, running, finished, canceling, canceled, errored
 	status   string
 	progress int
+	size     int64 // bytes handled by the task, see SetSize
 
 	Error error
 
@@ -34,6 +37,12 @@ type Task[K comparable] struct {
 
 	Ctx    context.Context
 	cancel context.CancelFunc
+
+	parent        *Task[K]
+	mu            sync.Mutex // protect children and waiting
+	children      []*Task[K]
+	waiting       bool // the task's own func is done and it's waiting for children
+	funcSucceeded bool // the task's own func succeeded, maybe waiting for children
 }
 
 func (t *Task[K]) SetStatus(status string) {
@@ -44,27 +53,38 @@ func (t *Task[K]) SetProgress(percentage int) {
 	t.progress = percentage
 }
 
-func (t Task[K]) GetProgress() int {
-	return t.progress
+// GetProgress returns the progress of the task,
+// or the aggregated progress of its descendants if it has children.
+func (t *Task[K]) GetProgress() int {
+	if len(t.GetChildren()) == 0 {
+		return t.progress
+	}
+	s := t.GetStats()
+	if s.TotalBytes > 0 {
+		return int(s.DoneBytes * 100 / s.TotalBytes)
+	}
+	return (s.DoneFiles + s.FailedFiles) * 100 / s.TotalFiles
 }
 
-func (t Task[K]) GetState() string {
+func (t *Task[K]) GetState() string {
 	return t.state
 }
 
-func (t Task[K]) GetStatus() string {
+func (t *Task[K]) GetStatus() string {
 	return t.status
 }
 
-func (t Task[K]) GetErrMsg() string {
+func (t *Task[K]) GetErrMsg() string {
 	if t.Error == nil {
 		return ""
 	}
 	return t.Error.Error()
 }
 
-func (t *Task[K]) run() {
+// run runs the func of task, it returns true if the task need to wait for its children
+func (t *Task[K]) run() (waiting bool) {
 	t.state = RUNNING
+	t.funcSucceeded = false
 	defer func() {
 		if err := recover(); err != nil {
 			log.Errorf("error [%+v] while run task [%s]", err, t.Name)
@@ -81,11 +101,20 @@ func (t *Task[K]) run() {
 	} else if t.Error != nil {
 		t.state = ERRORED
 	} else {
-		t.state = SUCCEEDED
-		t.SetProgress(100)
-		if t.callback != nil {
-			t.callback(t)
+		t.funcSucceeded = true
+		if len(t.GetChildren()) > 0 {
+			return true
 		}
+		t.finish()
+	}
+	return false
+}
+
+func (t *Task[K]) finish() {
+	t.state = SUCCEEDED
+	t.SetProgress(100)
+	if t.callback != nil {
+		t.callback(t)
 	}
 }
 
@@ -1,6 +1,7 @@
 package task
 
 import (
+	"strconv"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -94,3 +95,174 @@ func TestTask_Retry(t *testing.T) {
 		t.Errorf("task error: %+v, but expected nil", task.Error)
 	}
 }
+
+type memStore struct {
+	infos map[string]Info
+}
+
+func (s *memStore) SaveTask(info *Info) error {
+	s.infos[info.Kind+info.ID] = *info
+	return nil
+}
+
+func (s *memStore) DeleteTask(kind string, id string) error {
+	delete(s.infos, kind+id)
+	return nil
+}
+
+func (s *memStore) GetTasks(kind string) ([]Info, error) {
+	var infos []Info
+	for _, info := range s.infos {
+		if info.Kind == kind {
+			infos = append(infos, info)
+		}
+	}
+	return infos, nil
+}
+
+func TestTask_Persist(t *testing.T) {
+	store := &memStore{infos: map[string]Info{
+		"test1": {ID: "1", Kind: "test", Name: "done", State: SUCCEEDED, Progress: 100},
+		"test2": {ID: "2", Kind: "test", Name: "resume", State: RUNNING, Args: `"resume"`},
+		"test3": {ID: "3", Kind: "test", Name: "lost", State: PENDING},
+	}}
+	parseID := func(id string) (uint64, error) {
+		return strconv.ParseUint(id, 10, 64)
+	}
+	tm := NewTaskManager(3, func(id *uint64) {
+		atomic.AddUint64(id, 1)
+	})
+	err := tm.Persist(&Persistence[uint64]{
+		Kind:    "test",
+		Store:   store,
+		ParseID: parseID,
+		Resume: func(info *Info) Func[uint64] {
+			if info.Args == "" {
+				return nil
+			}
+			return func(task *Task[uint64]) error {
+				return nil
+			}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond * 100)
+	if task := tm.MustGet(1); task.GetState() != SUCCEEDED {
+		t.Errorf("finished task state: %s", task.GetState())
+	}
+	if task := tm.MustGet(2); task.GetState() != SUCCEEDED || store.infos["test2"].State != SUCCEEDED {
+		t.Errorf("resumed task state: %s", task.GetState())
+	}
+	if task := tm.MustGet(3); task.GetState() != ERRORED || !errors.Is(task.Error, ErrInterrupted) {
+		t.Errorf("interrupted task state: %s", task.GetState())
+	}
+	id := tm.Submit(WithCancelCtx(&Task[uint64]{
+		Name: "new",
+		Func: func(task *Task[uint64]) error {
+			return nil
+		},
+	}))
+	if id != 4 {
+		t.Errorf("new task id: %d, expected 4", id)
+	}
+	time.Sleep(time.Millisecond * 100)
+	tm.ClearDone()
+	if len(store.infos) != 0 {
+		t.Errorf("tasks not deleted from store: %+v", store.infos)
+	}
+}
+
+func TestTask_Children(t *testing.T) {
+	tm := NewTaskManager(2, func(id *uint64) {
+		atomic.AddUint64(id, 1)
+	})
+	var parentRuns, failRuns int32
+	id := tm.Submit(WithCancelCtx(&Task[uint64]{
+		Name: "parent",
+		Func: func(task *Task[uint64]) error {
+			atomic.AddInt32(&parentRuns, 1)
+			for i := 0; i < 3; i++ {
+				i := i
+				child := WithCancelCtx(&Task[uint64]{
+					Name: "child" + strconv.Itoa(i),
+					Func: func(task *Task[uint64]) error {
+						// the last child fails at the first time
+						if i == 2 && atomic.AddInt32(&failRuns, 1) == 1 {
+							return errors.New("test error")
+						}
+						return nil
+					},
+				})
+				child.SetSize(10)
+				tm.SubmitChild(task, child)
+			}
+			return nil
+		},
+	}))
+	task := tm.MustGet(id)
+	time.Sleep(time.Second)
+	if task.GetState() != ERRORED {
+		t.Fatalf("parent state: %s, expected errored", task.GetState())
+	}
+	stats := task.GetStats()
+	if stats.TotalFiles != 3 || stats.DoneFiles != 2 || stats.FailedFiles != 1 || stats.TotalBytes != 30 || stats.DoneBytes != 20 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	if failed := task.GetFailedItems(); len(failed) != 1 || failed[0].Name != "child2" {
+		t.Errorf("unexpected failed items: %+v", failed)
+	}
+	if len(tm.ListDone()) != 1 {
+		t.Errorf("children should not be listed")
+	}
+	if err := tm.Retry(id); err != nil {
+		t.Fatalf("failed retry: %+v", err)
+	}
+	time.Sleep(time.Second)
+	if task.GetState() != SUCCEEDED {
+		t.Fatalf("parent state: %s, expected succeeded", task.GetState())
+	}
+	if parentRuns != 1 {
+		t.Errorf("parent func ran %d times, only the failed child should be retried", parentRuns)
+	}
+	tm.ClearDone()
+	if len(tm.GetAll()) != 0 {
+		t.Errorf("children should be removed with parent")
+	}
+}
+
+func TestTask_CancelChildren(t *testing.T) {
+	tm := NewTaskManager(2, func(id *uint64) {
+		atomic.AddUint64(id, 1)
+	})
+	id := tm.Submit(WithCancelCtx(&Task[uint64]{
+		Name: "parent",
+		Func: func(task *Task[uint64]) error {
+			for i := 0; i < 4; i++ {
+				tm.SubmitChild(task, WithCancelCtx(&Task[uint64]{
+					Name: "child" + strconv.Itoa(i),
+					Func: func(task *Task[uint64]) error {
+						<-task.Ctx.Done()
+						return nil
+					},
+				}))
+			}
+			return nil
+		},
+	}))
+	task := tm.MustGet(id)
+	time.Sleep(time.Millisecond * 100)
+	if err := tm.Cancel(id); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond * 500)
+	if task.GetState() != CANCELED {
+		t.Errorf("parent state: %s, expected canceled", task.GetState())
+	}
+	for _, child := range task.GetChildren() {
+		if child.GetState() != CANCELED {
+			t.Errorf("child [%s] state: %s, expected canceled", child.Name, child.GetState())
+		}
+	}
+}
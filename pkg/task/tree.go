@@ -0,0 +1,230 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// Stats is the aggregated progress of a task and its descendants,
+// every task without children is counted as a file.
+type Stats struct {
+	TotalBytes  int64
+	DoneBytes   int64
+	TotalFiles  int
+	DoneFiles   int
+	FailedFiles int
+}
+
+// FailedItem is a failed task without children
+type FailedItem struct {
+	Name  string `json:"name"`
+	Error string `json:"error"`
+}
+
+// SetSize set the bytes handled by the task, used to aggregate the progress of parent
+func (t *Task[K]) SetSize(size int64) {
+	t.size = size
+}
+
+func (t *Task[K]) GetSize() int64 {
+	return t.size
+}
+
+func (t *Task[K]) GetParent() *Task[K] {
+	return t.parent
+}
+
+func (t *Task[K]) GetChildren() []*Task[K] {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	children := make([]*Task[K], len(t.children))
+	copy(children, t.children)
+	return children
+}
+
+func (t *Task[K]) addChild(child *Task[K]) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	child.parent = t
+	t.children = append(t.children, child)
+}
+
+func (t *Task[K]) removeChild(child *Task[K]) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i := range t.children {
+		if t.children[i] == child {
+			t.children = append(t.children[:i], t.children[i+1:]...)
+			return
+		}
+	}
+}
+
+func (t *Task[K]) GetStats() Stats {
+	children := t.GetChildren()
+	if len(children) == 0 {
+		s := Stats{TotalBytes: t.size, TotalFiles: 1}
+		switch t.state {
+		case SUCCEEDED:
+			s.DoneFiles = 1
+			s.DoneBytes = t.size
+		case ERRORED:
+			s.FailedFiles = 1
+		default:
+			s.DoneBytes = t.size * int64(t.progress) / 100
+		}
+		return s
+	}
+	var s Stats
+	for _, child := range children {
+		cs := child.GetStats()
+		s.TotalBytes += cs.TotalBytes
+		s.DoneBytes += cs.DoneBytes
+		s.TotalFiles += cs.TotalFiles
+		s.DoneFiles += cs.DoneFiles
+		s.FailedFiles += cs.FailedFiles
+	}
+	return s
+}
+
+// GetFailedItems returns the failed descendants without children
+func (t *Task[K]) GetFailedItems() []FailedItem {
+	children := t.GetChildren()
+	if len(children) == 0 {
+		if t.state == ERRORED {
+			return []FailedItem{{Name: t.Name, Error: t.GetErrMsg()}}
+		}
+		return nil
+	}
+	var items []FailedItem
+	for _, child := range children {
+		items = append(items, child.GetFailedItems()...)
+	}
+	return items
+}
+
+// resetCtx creates a new context for retry, derived from the parent's if it has one
+func (t *Task[K]) resetCtx() {
+	parent := context.Background()
+	if t.parent != nil && t.parent.Ctx != nil {
+		parent = t.parent.Ctx
+	}
+	t.Ctx, t.cancel = context.WithCancel(parent)
+}
+
+// SubmitChild submits a task as a child of parent,
+// the child is canceled with the parent and its progress is aggregated to the parent.
+func (tm *Manager[K]) SubmitChild(parent, child *Task[K]) K {
+	parent.addChild(child)
+	child.resetCtx()
+	child.state = PENDING
+	return tm.Submit(child)
+}
+
+// waitChildren marks the task waiting for its children, the final state of the task
+// is set by the last child done, or now if all the children are already done
+func (tm *Manager[K]) waitChildren(t *Task[K]) {
+	t.SetStatus("waiting for children")
+	t.mu.Lock()
+	t.waiting = true
+	t.mu.Unlock()
+	tm.finishWaiting(t)
+}
+
+// finishWaiting sets the final state of the task if it's waiting and all its children are done
+func (tm *Manager[K]) finishWaiting(t *Task[K]) {
+	t.mu.Lock()
+	if !t.waiting {
+		t.mu.Unlock()
+		return
+	}
+	for _, child := range t.children {
+		if !child.Done() {
+			t.mu.Unlock()
+			return
+		}
+	}
+	t.waiting = false
+	t.mu.Unlock()
+	stats := t.GetStats()
+	if errors.Is(t.Ctx.Err(), context.Canceled) {
+		t.state = CANCELED
+	} else if failed := t.GetFailedItems(); len(failed) > 0 {
+		names := make([]string, 0, len(failed))
+		for _, item := range failed {
+			names = append(names, fmt.Sprintf("%s: %s", item.Name, item.Error))
+		}
+		t.Error = errors.Errorf("%d of %d files failed:\n%s", len(failed), stats.TotalFiles, strings.Join(names, "\n"))
+		t.state = ERRORED
+		log.Errorf("error [%+v] while run task [%s]", t.Error, t.Name)
+	} else {
+		t.finish()
+	}
+	t.SetStatus(fmt.Sprintf("%d/%d files done", stats.DoneFiles, stats.TotalFiles))
+	tm.save(t)
+	tm.done(t)
+}
+
+// retry reruns the task, if the task's own func succeeded,
+// only the children not succeeded are retried.
+func (tm *Manager[K]) retry(t *Task[K]) error {
+	children := t.GetChildren()
+	if !t.funcSucceeded || len(children) == 0 {
+		if t.Func == nil {
+			// the task is restored from store, try to rebuild its func
+			if tm.persistence == nil || tm.persistence.Resume == nil {
+				return errors.WithStack(ErrTaskNotResumable)
+			}
+			t.Func = tm.persistence.Resume(t.info(tm.persistence.Kind))
+			if t.Func == nil {
+				return errors.WithStack(ErrTaskNotResumable)
+			}
+		}
+		for _, child := range children {
+			tm.removeTree(child)
+		}
+		t.resetCtx()
+		t.Error = nil
+		t.state = PENDING
+		tm.save(t)
+		tm.do(t)
+		return nil
+	}
+	t.resetCtx()
+	t.Error = nil
+	t.state = RUNNING
+	var errs []string
+	for _, child := range children {
+		if child.state == SUCCEEDED {
+			continue
+		}
+		if err := tm.retry(child); err != nil {
+			// keep the child failed, it's reported by waitChildren
+			child.Error = err
+			child.state = ERRORED
+			errs = append(errs, err.Error())
+		}
+	}
+	tm.save(t)
+	tm.waitChildren(t)
+	if len(errs) > 0 {
+		return errors.Errorf("failed retry %d children: %s", len(errs), strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// removeTree removes the task and its descendants from the manager
+func (tm *Manager[K]) removeTree(t *Task[K]) {
+	for _, child := range t.GetChildren() {
+		tm.removeTree(child)
+	}
+	if t.parent != nil {
+		t.parent.removeChild(t)
+	}
+	tm.tasks.Delete(t.ID)
+	tm.delete(t.ID)
+}
@@ -3,9 +3,13 @@ package utils
 import (
 	"crypto/md5"
 	"crypto/sha1"
+	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
+	"hash"
 	"strings"
+
+	"github.com/pkg/errors"
 )
 
 func GetSHA1Encode(data string) string {
@@ -36,3 +40,97 @@ func SafeAtob(data string) (string, error) {
 	}
 	return string(bytes), err
 }
+
+// HashType is a kind of content hash of file, such as md5, sha1
+type HashType struct {
+	Name    string
+	Width   int              // length of the encoded hash, 0 if it's not fixed
+	NewFunc func() hash.Hash // nil if the hash can't be calculated locally
+}
+
+var hashTypes = map[string]*HashType{}
+
+// RegisterHash registers a hash type, drivers can register the special hashes of their backends
+func RegisterHash(name string, width int, newFunc func() hash.Hash) *HashType {
+	name = strings.ToLower(name)
+	if ht, ok := hashTypes[name]; ok {
+		return ht
+	}
+	ht := &HashType{Name: name, Width: width, NewFunc: newFunc}
+	hashTypes[name] = ht
+	return ht
+}
+
+func GetHashType(name string) (*HashType, bool) {
+	ht, ok := hashTypes[strings.ToLower(name)]
+	return ht, ok
+}
+
+var (
+	MD5    = RegisterHash("md5", 32, md5.New)
+	SHA1   = RegisterHash("sha1", 40, sha1.New)
+	SHA256 = RegisterHash("sha256", 64, sha256.New)
+)
+
+// HashInfo holds the hashes of a file by their types
+type HashInfo struct {
+	h map[*HashType]string
+}
+
+// NewHashInfo create a HashInfo with a hash, empty hash is ignored
+func NewHashInfo(ht *HashType, str string) HashInfo {
+	return HashInfo{}.With(ht, str)
+}
+
+// With returns a copy of the HashInfo with the hash added
+func (hi HashInfo) With(ht *HashType, str string) HashInfo {
+	str = strings.TrimSpace(str)
+	if ht == nil || str == "" || (ht.Width > 0 && len(str) != ht.Width) {
+		return hi
+	}
+	h := make(map[*HashType]string, len(hi.h)+1)
+	for k, v := range hi.h {
+		h[k] = v
+	}
+	h[ht] = str
+	return HashInfo{h: h}
+}
+
+func (hi HashInfo) GetHash(ht *HashType) string {
+	return hi.h[ht]
+}
+
+func (hi HashInfo) Empty() bool {
+	return len(hi.h) == 0
+}
+
+// Export returns the hashes by the names of their types
+func (hi HashInfo) Export() map[string]string {
+	m := make(map[string]string, len(hi.h))
+	for ht, v := range hi.h {
+		m[ht.Name] = v
+	}
+	return m
+}
+
+func (hi HashInfo) MarshalJSON() ([]byte, error) {
+	return Json.Marshal(hi.Export())
+}
+
+// ErrHashMismatch is returned by CompareHash if a common hash is different
+var ErrHashMismatch = errors.New("hash mismatch")
+
+// CompareHash compares the common hashes of a and b,
+// ok is false if they have no common hash, so they can't be compared.
+func CompareHash(a, b HashInfo) (ok bool, err error) {
+	for ht, v := range a.h {
+		if w, has := b.h[ht]; has {
+			// hex encoded hashes maybe in upper case
+			if !strings.EqualFold(v, w) {
+				return true, errors.Wrapf(ErrHashMismatch, "%s: %s != %s", ht.Name, v, w)
+			}
+			ok = true
+		}
+	}
+	return ok, nil
+}
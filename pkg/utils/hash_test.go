@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCompareHash(t *testing.T) {
+	a := NewHashInfo(MD5, "d41d8cd98f00b204e9800998ecf8427e").With(SHA1, "da39a3ee5e6b4b0d3255bfef95601890afd80709")
+	b := NewHashInfo(SHA1, "DA39A3EE5E6B4B0D3255BFEF95601890AFD80709")
+	if ok, err := CompareHash(a, b); !ok || err != nil {
+		t.Errorf("expected equal, got ok=%v err=%v", ok, err)
+	}
+	c := NewHashInfo(SHA256, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855")
+	if ok, _ := CompareHash(a, c); ok {
+		t.Errorf("expected not comparable")
+	}
+	d := NewHashInfo(MD5, "00000000000000000000000000000000")
+	if _, err := CompareHash(a, d); !errors.Is(err, ErrHashMismatch) {
+		t.Errorf("expected mismatch, got %v", err)
+	}
+	if !NewHashInfo(MD5, "invalid").Empty() {
+		t.Errorf("hash with invalid width should be ignored")
+	}
+}
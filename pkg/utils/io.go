@@ -3,6 +3,8 @@ package utils
 import (
 	"context"
 	"io"
+
+	"github.com/pkg/errors"
 )
 
 // here is some syntaxic sugar inspired by the Tomas Senart's video,
@@ -69,3 +71,71 @@ func (l limitWriter) Write(p []byte) (n int, err error) {
 func LimitWriter(w io.Writer, size int64) io.Writer {
 	return &limitWriter{w: w, limit: size}
 }
+
+// ReadCloser combines a reader and a closer, such as a limited reader of a stream
+type ReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// RangeReadSeeker implements io.ReadSeeker by opening the reader from the offset,
+// the reader is reopened only if the position is changed by Seek.
+type RangeReadSeeker struct {
+	ctx      context.Context
+	size     int64
+	open     func(ctx context.Context, offset, length int64) (io.ReadCloser, error)
+	offset   int64
+	rc       io.ReadCloser
+	rcOffset int64
+}
+
+func NewRangeReadSeeker(ctx context.Context, size int64, open func(ctx context.Context, offset, length int64) (io.ReadCloser, error)) *RangeReadSeeker {
+	return &RangeReadSeeker{ctx: ctx, size: size, open: open}
+}
+
+func (r *RangeReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.size
+	default:
+		return 0, errors.Errorf("invalid whence: %d", whence)
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+	r.offset = offset
+	return offset, nil
+}
+
+func (r *RangeReadSeeker) Read(p []byte) (int, error) {
+	if r.offset >= r.size {
+		return 0, io.EOF
+	}
+	if r.rc != nil && r.rcOffset != r.offset {
+		_ = r.rc.Close()
+		r.rc = nil
+	}
+	if r.rc == nil {
+		rc, err := r.open(r.ctx, r.offset, -1)
+		if err != nil {
+			return 0, err
+		}
+		r.rc, r.rcOffset = rc, r.offset
+	}
+	n, err := r.rc.Read(p)
+	r.offset += int64(n)
+	r.rcOffset += int64(n)
+	return n, err
+}
+
+func (r *RangeReadSeeker) Close() error {
+	if r.rc == nil {
+		return nil
+	}
+	err := r.rc.Close()
+	r.rc = nil
+	return err
+}
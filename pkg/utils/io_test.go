@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRangeReadSeeker(t *testing.T) {
+	data := []byte("0123456789abcdefghij")
+	opened := 0
+	open := func(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+		opened++
+		return io.NopCloser(bytes.NewReader(data[offset:])), nil
+	}
+	r := httptest.NewRequest(http.MethodGet, "/file", nil)
+	r.Header.Set("Range", "bytes=5-9")
+	w := httptest.NewRecorder()
+	rs := NewRangeReadSeeker(r.Context(), int64(len(data)), open)
+	http.ServeContent(w, r, "file.bin", time.Now(), rs)
+	_ = rs.Close()
+	if w.Code != http.StatusPartialContent {
+		t.Fatalf("expected 206, got %d", w.Code)
+	}
+	if w.Body.String() != "56789" {
+		t.Errorf("unexpected body: %s", w.Body.String())
+	}
+	if opened != 1 {
+		t.Errorf("expected reader opened once, got %d", opened)
+	}
+}
@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/Xhofe/go-cache"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// HashPassword returns the salted bcrypt hash of the password
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// IsHashedPassword reports whether s is a bcrypt hash rather than a plaintext password
+func IsHashedPassword(s string) bool {
+	if len(s) != 60 {
+		return false
+	}
+	return strings.HasPrefix(s, "$2a$") || strings.HasPrefix(s, "$2b$") || strings.HasPrefix(s, "$2y$")
+}
+
+// passwordCache caches the successful comparisons,
+// because bcrypt is slow and meta passwords are checked on every request
+var passwordCache = cache.NewMemCache[bool]()
+
+// ComparePassword reports whether password matches the hash,
+// a plaintext hash (not migrated yet) is compared directly.
+func ComparePassword(hash, password string) bool {
+	if !IsHashedPassword(hash) {
+		return hash == password
+	}
+	sum := sha256.Sum256([]byte(hash + "\x00" + password))
+	key := hex.EncodeToString(sum[:])
+	if _, ok := passwordCache.Get(key); ok {
+		return true
+	}
+	if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) != nil {
+		return false
+	}
+	passwordCache.Set(key, true, cache.WithEx[bool](time.Minute*10))
+	return true
+}
@@ -0,0 +1,26 @@
+package utils
+
+import "testing"
+
+func TestComparePassword(t *testing.T) {
+	hash, err := HashPassword("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsHashedPassword(hash) {
+		t.Errorf("%s should be a hashed password", hash)
+	}
+	if IsHashedPassword("secret") {
+		t.Errorf("plaintext should not be a hashed password")
+	}
+	if !ComparePassword(hash, "secret") || !ComparePassword(hash, "secret") {
+		t.Errorf("password should match the hash")
+	}
+	if ComparePassword(hash, "wrong") {
+		t.Errorf("wrong password should not match the hash")
+	}
+	// plaintext left by old versions
+	if !ComparePassword("secret", "secret") || ComparePassword("secret", "wrong") {
+		t.Errorf("plaintext password compared wrongly")
+	}
+}
@@ -1,6 +1,7 @@
 package random
 
 import (
+	crand "crypto/rand"
 	"math/rand"
 	"time"
 
@@ -31,3 +32,15 @@ func init() {
 	s := rand.NewSource(time.Now().UnixNano())
 	Rand = rand.New(s)
 }
+
+// SecureString returns a random string generated by crypto/rand, for the ids that must not be guessed
+func SecureString(n int) string {
+	b := make([]byte, n)
+	if _, err := crand.Read(b); err != nil {
+		panic(err)
+	}
+	for i := range b {
+		b[i] = letterBytes[int(b[i])%len(letterBytes)]
+	}
+	return string(b)
+}
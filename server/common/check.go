@@ -4,20 +4,24 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/alist-org/alist/v3/internal/authz"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/pkg/utils"
 )
 
-func CanWrite(meta *model.Meta, path string) bool {
-	if meta == nil || !meta.Write {
+func CanAccess(user *model.User, meta *model.Meta, reqPath string, password string) bool {
+	// the user must be able to list or read the path by the acl rules
+	if !authz.Can(user, model.ActionList, reqPath) && !authz.Can(user, model.ActionRead, reqPath) {
 		return false
 	}
-	return meta.WSub || meta.Path == path
+	return CanAccessMeta(user, meta, reqPath, password)
 }
 
-func CanAccess(user *model.User, meta *model.Meta, reqPath string, password string) bool {
+// CanAccessMeta checks the hide and password rules of meta only, it's used by the writes
+// which don't need to list or read the path, such as the uploads of the upload-only tokens
+func CanAccessMeta(user *model.User, meta *model.Meta, reqPath string, password string) bool {
 	// if the reqPath is in hide (only can check the nearest meta) and user can't see hides, can't access
-	if meta != nil && !user.CanSeeHides() && meta.Hide != "" {
+	if meta != nil && !authz.CanSeeHides(user) && meta.Hide != "" {
 		for _, hide := range strings.Split(meta.Hide, "\n") {
 			re := regexp.MustCompile(hide)
 			if re.MatchString(reqPath[len(meta.Path):]) {
@@ -26,7 +30,7 @@ func CanAccess(user *model.User, meta *model.Meta, reqPath string, password stri
 		}
 	}
 	// if is not guest and can access without password
-	if user.CanAccessWithoutPassword() {
+	if authz.CanAccessWithoutPassword(user) {
 		return true
 	}
 	// if meta is nil or password is empty, can access
@@ -38,5 +42,5 @@ func CanAccess(user *model.User, meta *model.Meta, reqPath string, password stri
 		return true
 	}
 	// validate password
-	return meta.Password == password
+	return meta.ValidatePassword(password)
 }
@@ -11,6 +11,7 @@ import (
 	"strings"
 
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
@@ -18,6 +19,26 @@ import (
 var HttpClient = &http.Client{}
 
 func Proxy(w http.ResponseWriter, r *http.Request, link *model.Link, file model.Obj) error {
+	// read data by range, so Range requests can be served
+	if rs := getReadSeeker(r, link, file); rs != nil {
+		defer func() {
+			_ = rs.Close()
+		}()
+		for h, val := range link.Header {
+			// Content-Length is set by ServeContent according to the range
+			if strings.EqualFold(h, "Content-Length") || strings.EqualFold(h, "Set-Cookie") {
+				continue
+			}
+			w.Header()[h] = val
+		}
+		if w.Header().Get("Content-Type") == "" {
+			// avoid sniffing the content, which reopens the reader
+			w.Header().Set("Content-Type", utils.GetMimeType(file.GetName()))
+		}
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"; filename*=UTF-8''%s`, file.GetName(), url.QueryEscape(file.GetName())))
+		http.ServeContent(w, r, file.GetName(), file.ModTime(), rs)
+		return nil
+	}
 	// read data with native
 	var err error
 	if link.Data != nil {
@@ -102,3 +123,17 @@ func Proxy(w http.ResponseWriter, r *http.Request, link *model.Link, file model.
 		return nil
 	}
 }
+
+// getReadSeeker returns a seekable reader of the link if the driver supports reading by range
+func getReadSeeker(r *http.Request, link *model.Link, file model.Obj) io.ReadSeekCloser {
+	if link.RangeReader != nil {
+		if link.Data != nil {
+			_ = link.Data.Close()
+		}
+		return utils.NewRangeReadSeeker(r.Context(), file.GetSize(), link.RangeReader)
+	}
+	if rs, ok := link.Data.(io.ReadSeekCloser); ok && link.Status == 0 {
+		return rs
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package handles
 
 import (
 	"github.com/alist-org/alist/v3/internal/aria2"
+	"github.com/alist-org/alist/v3/internal/authz"
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/db"
 	"github.com/alist-org/alist/v3/internal/model"
@@ -43,10 +44,6 @@ type AddAria2Req struct {
 
 func AddAria2(c *gin.Context) {
 	user := c.MustGet("user").(*model.User)
-	if !user.CanAddAria2Tasks() {
-		common.ErrorStrResp(c, "permission denied", 403)
-		return
-	}
 	if !aria2.IsAria2Ready() {
 		common.ErrorStrResp(c, "aria2 not ready", 500)
 		return
@@ -61,6 +58,10 @@ func AddAria2(c *gin.Context) {
 		common.ErrorResp(c, err, 403)
 		return
 	}
+	if !authz.Can(user, model.ActionAria2, reqPath) {
+		common.ErrorStrResp(c, "permission denied", 403)
+		return
+	}
 	for _, url := range req.Urls {
 		err := aria2.AddURI(c, url, reqPath)
 		if err != nil {
@@ -0,0 +1,77 @@
+package handles
+
+import (
+	"encoding/csv"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+func ListAuditLogs(c *gin.Context) {
+	var req model.AuditReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	logs, total, err := db.GetAuditLogs(req)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: logs,
+		Total:   total,
+	})
+}
+
+// ExportAuditLogs writes the audit logs matching the filters as csv, the pagination is ignored
+func ExportAuditLogs(c *gin.Context) {
+	var req model.AuditReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="audit.csv"`)
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write([]string{"id", "time", "user_id", "username", "ip", "via", "action", "src", "dst", "success", "error"})
+	// the headers are already sent, so the errors can only be logged
+	err := db.WalkAuditLogs(req, func(l *model.AuditLog) error {
+		return w.Write([]string{
+			strconv.Itoa(int(l.ID)),
+			l.Time.Format(time.RFC3339),
+			strconv.Itoa(int(l.UserID)),
+			csvCell(l.Username),
+			csvCell(l.IP),
+			csvCell(l.Via),
+			csvCell(l.Action),
+			csvCell(l.Src),
+			csvCell(l.Dst),
+			strconv.FormatBool(l.Success),
+			csvCell(l.Error),
+		})
+	})
+	w.Flush()
+	if err == nil {
+		err = w.Error()
+	}
+	if err != nil {
+		log.Errorf("failed write audit logs: %+v", err)
+	}
+}
+
+// csvCell prefixes the value with ' if it starts with a formula char,
+// so that the paths or names given by users are not run as formulas by the spreadsheets
+func csvCell(v string) string {
+	if v != "" && strings.ContainsRune("=+-@", rune(v[0])) {
+		return "'" + v
+	}
+	return v
+}
@@ -0,0 +1,32 @@
+package handles
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+func TestExportAuditLogs(t *testing.T) {
+	l := model.AuditLog{Time: time.Now(), Username: "=cmd", Action: "fs.put", Dst: "/-x/@y.txt", Success: true}
+	if err := db.CreateAuditLog(&l); err != nil {
+		t.Fatalf("failed create audit log: %+v", err)
+	}
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/api/admin/audit/export", ExportAuditLogs)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/audit/export?username==cmd", nil))
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expect the header and 1 log, got %q", w.Body.String())
+	}
+	if !strings.Contains(lines[1], ",'=cmd,") || !strings.Contains(lines[1], ",/-x/@y.txt,") {
+		t.Errorf("expect the formula escaped only at the start, got %q", lines[1])
+	}
+}
@@ -7,11 +7,15 @@ import (
 	"time"
 
 	"github.com/Xhofe/go-cache"
+	"github.com/alist-org/alist/v3/internal/audit"
 	"github.com/alist-org/alist/v3/internal/db"
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/alist-org/alist/v3/server/common"
 	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
 	"github.com/pquerna/otp/totp"
+	log "github.com/sirupsen/logrus"
 )
 
 var loginCache = cache.NewMemCache[int]()
@@ -45,19 +49,28 @@ func Login(c *gin.Context) {
 	if err != nil {
 		common.ErrorResp(c, err, 400)
 		loginCache.Set(ip, count+1)
+		audit.LogUser(c, &model.User{Username: req.Username}, audit.Login, "", "", err)
 		return
 	}
 	// validate password
 	if err := user.ValidatePassword(req.Password); err != nil {
 		common.ErrorResp(c, err, 400)
 		loginCache.Set(ip, count+1)
+		audit.LogUser(c, user, audit.Login, "", "", err)
 		return
 	}
+	// migrate plaintext password left by old versions
+	if !utils.IsHashedPassword(user.Password) {
+		if err := db.UpdateUser(user); err != nil {
+			log.Errorf("failed migrate password of user [%s]: %+v", user.Username, err)
+		}
+	}
 	// check 2FA
 	if user.OtpSecret != "" {
 		if !totp.Validate(req.OtpCode, user.OtpSecret) {
 			common.ErrorStrResp(c, "Invalid 2FA code", 402)
 			loginCache.Set(ip, count+1)
+			audit.LogUser(c, user, audit.Login, "", "", errors.New("invalid 2FA code"))
 			return
 		}
 	}
@@ -69,6 +82,7 @@ func Login(c *gin.Context) {
 	}
 	common.SuccessResp(c, gin.H{"token": token})
 	loginCache.Del(ip)
+	audit.LogUser(c, user, audit.Login, "", "", nil)
 }
 
 type UserResp struct {
@@ -0,0 +1,58 @@
+package handles
+
+import (
+	"github.com/alist-org/alist/v3/internal/audit"
+	"github.com/alist-org/alist/v3/internal/backup"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+type ExportBackupReq struct {
+	Password string `json:"password"`
+}
+
+func ExportBackup(c *gin.Context) {
+	var req ExportBackupReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	b, err := backup.Create(req.Password)
+	audit.Log(c, audit.BackupExport, "", "", err)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, b)
+}
+
+type ImportBackupReq struct {
+	Backup   model.Backup `json:"backup"`
+	Password string       `json:"password"`
+	// merge or replace, default is merge
+	Mode string `json:"mode"`
+}
+
+func ImportBackup(c *gin.Context) {
+	var req ImportBackupReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if req.Mode != "" && req.Mode != "merge" && req.Mode != "replace" {
+		common.ErrorStrResp(c, "invalid mode: "+req.Mode, 400)
+		return
+	}
+	err := backup.Restore(&req.Backup, req.Password, req.Mode == "replace")
+	audit.Log(c, audit.BackupImport, req.Mode, "", err)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := backup.Reload(c); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c)
+}
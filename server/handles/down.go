@@ -27,7 +27,7 @@ func Down(c *gin.Context) {
 		Proxy(c)
 		return
 	} else {
-		link, _, err := fs.Link(c, rawPath, model.LinkArgs{
+		link, file, err := fs.Link(c, rawPath, model.LinkArgs{
 			IP:     c.ClientIP(),
 			Header: c.Request.Header,
 			Type:   c.Query("type"),
@@ -36,6 +36,13 @@ func Down(c *gin.Context) {
 			common.ErrorResp(c, err, 500)
 			return
 		}
+		if link.URL == "" {
+			// the link without url can't be redirected to, such as the alias of local storage
+			if err := common.Proxy(c.Writer, c.Request, link, file); err != nil {
+				common.ErrorResp(c, err, 500, true)
+			}
+			return
+		}
 		c.Header("Referrer-Policy", "no-referrer")
 		c.Header("Cache-Control", "max-age=0, no-cache, no-store, must-revalidate")
 		c.Redirect(302, link.URL)
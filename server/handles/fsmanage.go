@@ -4,15 +4,15 @@ import (
 	"fmt"
 	stdpath "path"
 
-	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/authz"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/fs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/sign"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/alist-org/alist/v3/server/common"
+	"github.com/alist-org/alist/v3/server/webdav"
 	"github.com/gin-gonic/gin"
-	"github.com/pkg/errors"
 )
 
 type MkdirOrLinkReq struct {
@@ -31,18 +31,12 @@ func FsMkdir(c *gin.Context) {
 		common.ErrorResp(c, err, 403)
 		return
 	}
-	if !user.CanWrite() {
-		meta, err := db.GetNearestMeta(stdpath.Dir(reqPath))
-		if err != nil {
-			if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
-				common.ErrorResp(c, err, 500, true)
-				return
-			}
-		}
-		if !common.CanWrite(meta, reqPath) {
-			common.ErrorResp(c, errs.PermissionDenied, 403)
-			return
-		}
+	if !authz.Can(user, model.ActionWrite, reqPath) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
+	if !checkUnlocked(c, reqPath) {
+		return
 	}
 	if err := fs.MakeDir(c, reqPath); err != nil {
 		common.ErrorResp(c, err, 500)
@@ -69,10 +63,6 @@ func FsMove(c *gin.Context) {
 		return
 	}
 	user := c.MustGet("user").(*model.User)
-	if !user.CanMove() {
-		common.ErrorResp(c, errs.PermissionDenied, 403)
-		return
-	}
 	srcDir, err := user.JoinPath(req.SrcDir)
 	if err != nil {
 		common.ErrorResp(c, err, 403)
@@ -83,8 +73,25 @@ func FsMove(c *gin.Context) {
 		common.ErrorResp(c, err, 403)
 		return
 	}
+	if !authz.Can(user, model.ActionMove, dstDir) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
+	var addedTask []string
 	for _, name := range req.Names {
-		err := fs.Move(c, stdpath.Join(srcDir, name), dstDir)
+		if !authz.Can(user, model.ActionMove, stdpath.Join(srcDir, name)) {
+			common.ErrorResp(c, errs.PermissionDenied, 403)
+			return
+		}
+		if !checkUnlocked(c, stdpath.Join(srcDir, name), stdpath.Join(dstDir, name)) {
+			return
+		}
+	}
+	for _, name := range req.Names {
+		ok, err := fs.Move(c, stdpath.Join(srcDir, name), dstDir)
+		if ok {
+			addedTask = append(addedTask, name)
+		}
 		if err != nil {
 			common.ErrorResp(c, err, 500)
 			return
@@ -92,7 +99,11 @@ func FsMove(c *gin.Context) {
 	}
 	fs.ClearCache(srcDir)
 	fs.ClearCache(dstDir)
-	common.SuccessResp(c)
+	if len(addedTask) > 0 {
+		common.SuccessResp(c, fmt.Sprintf("Added %d tasks", len(addedTask)))
+	} else {
+		common.SuccessResp(c)
+	}
 }
 
 func FsCopy(c *gin.Context) {
@@ -106,10 +117,6 @@ func FsCopy(c *gin.Context) {
 		return
 	}
 	user := c.MustGet("user").(*model.User)
-	if !user.CanCopy() {
-		common.ErrorResp(c, errs.PermissionDenied, 403)
-		return
-	}
 	srcDir, err := user.JoinPath(req.SrcDir)
 	if err != nil {
 		common.ErrorResp(c, err, 403)
@@ -120,7 +127,20 @@ func FsCopy(c *gin.Context) {
 		common.ErrorResp(c, err, 403)
 		return
 	}
+	if !authz.Can(user, model.ActionCopy, dstDir) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
 	var addedTask []string
+	for _, name := range req.Names {
+		if !authz.Can(user, model.ActionCopy, stdpath.Join(srcDir, name)) {
+			common.ErrorResp(c, errs.PermissionDenied, 403)
+			return
+		}
+		if !checkUnlocked(c, stdpath.Join(dstDir, name)) {
+			return
+		}
+	}
 	for _, name := range req.Names {
 		ok, err := fs.Copy(c, stdpath.Join(srcDir, name), dstDir)
 		if ok {
@@ -153,15 +173,18 @@ func FsRename(c *gin.Context) {
 		return
 	}
 	user := c.MustGet("user").(*model.User)
-	if !user.CanRename() {
-		common.ErrorResp(c, errs.PermissionDenied, 403)
-		return
-	}
 	reqPath, err := user.JoinPath(req.Path)
 	if err != nil {
 		common.ErrorResp(c, err, 403)
 		return
 	}
+	if !authz.Can(user, model.ActionRename, reqPath) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
+	if !checkUnlocked(c, reqPath, stdpath.Join(stdpath.Dir(reqPath), req.Name)) {
+		return
+	}
 	if err := fs.Rename(c, reqPath, req.Name); err != nil {
 		common.ErrorResp(c, err, 500)
 		return
@@ -186,15 +209,20 @@ func FsRemove(c *gin.Context) {
 		return
 	}
 	user := c.MustGet("user").(*model.User)
-	if !user.CanRemove() {
-		common.ErrorResp(c, errs.PermissionDenied, 403)
-		return
-	}
 	reqDir, err := user.JoinPath(req.Dir)
 	if err != nil {
 		common.ErrorResp(c, err, 403)
 		return
 	}
+	for _, name := range req.Names {
+		if !authz.Can(user, model.ActionRemove, stdpath.Join(reqDir, name)) {
+			common.ErrorResp(c, errs.PermissionDenied, 403)
+			return
+		}
+		if !checkUnlocked(c, stdpath.Join(reqDir, name)) {
+			return
+		}
+	}
 	for _, name := range req.Names {
 		err := fs.Remove(c, stdpath.Join(reqDir, name))
 		if err != nil {
@@ -206,6 +234,22 @@ func FsRemove(c *gin.Context) {
 	common.SuccessResp(c)
 }
 
+// checkUnlocked responds 423 if any of the paths is locked by webdav
+func checkUnlocked(c *gin.Context, paths ...string) bool {
+	for _, path := range paths {
+		locked, err := webdav.Locked(path)
+		if err != nil {
+			common.ErrorResp(c, err, 500)
+			return false
+		}
+		if locked {
+			common.ErrorResp(c, errs.ObjectLocked, 423)
+			return false
+		}
+	}
+	return true
+}
+
 // Link return real link, just for proxy program, it may contain cookie, so just allowed for admin
 func Link(c *gin.Context) {
 	var req MkdirOrLinkReq
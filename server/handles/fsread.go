@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/alist-org/alist/v3/internal/authz"
 	"github.com/alist-org/alist/v3/internal/db"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/fs"
@@ -31,13 +32,14 @@ type DirReq struct {
 }
 
 type ObjResp struct {
-	Name     string    `json:"name"`
-	Size     int64     `json:"size"`
-	IsDir    bool      `json:"is_dir"`
-	Modified time.Time `json:"modified"`
-	Sign     string    `json:"sign"`
-	Thumb    string    `json:"thumb"`
-	Type     int       `json:"type"`
+	Name     string         `json:"name"`
+	Size     int64          `json:"size"`
+	IsDir    bool           `json:"is_dir"`
+	Modified time.Time      `json:"modified"`
+	Sign     string         `json:"sign"`
+	Thumb    string         `json:"thumb"`
+	Type     int            `json:"type"`
+	HashInfo utils.HashInfo `json:"hash_info"`
 }
 
 type FsListResp struct {
@@ -73,7 +75,11 @@ func FsList(c *gin.Context) {
 		common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
 		return
 	}
-	if !user.CanWrite() && !common.CanWrite(meta, reqPath) && req.Refresh {
+	if !authz.Can(user, model.ActionList, reqPath) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
+	if !authz.Can(user, model.ActionWrite, reqPath) && req.Refresh {
 		common.ErrorStrResp(c, "Refresh without permission", 403)
 		return
 	}
@@ -92,7 +98,7 @@ func FsList(c *gin.Context) {
 		Content:  toObjsResp(objs, reqPath, isEncrypt(meta, reqPath)),
 		Total:    int64(total),
 		Readme:   getReadme(meta, reqPath),
-		Write:    user.CanWrite() || common.CanWrite(meta, reqPath),
+		Write:    authz.Can(user, model.ActionWrite, reqPath),
 		Provider: provider,
 	})
 }
@@ -130,6 +136,10 @@ func FsDirs(c *gin.Context) {
 		common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
 		return
 	}
+	if !authz.Can(user, model.ActionList, reqPath) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
 	objs, err := fs.List(c, reqPath)
 	if err != nil {
 		common.ErrorResp(c, err, 500)
@@ -203,6 +213,7 @@ func toObjsResp(objs []model.Obj, parent string, encrypt bool) []ObjResp {
 			Sign:     common.Sign(obj, parent, encrypt),
 			Thumb:    thumb,
 			Type:     utils.GetObjType(obj.GetName(), obj.IsDir()),
+			HashInfo: model.GetHash(obj),
 		})
 	}
 	return resp
@@ -250,6 +261,15 @@ func FsGet(c *gin.Context) {
 		common.ErrorResp(c, err, 500)
 		return
 	}
+	// getting a folder is a part of listing
+	action := model.ActionRead
+	if obj.IsDir() {
+		action = model.ActionList
+	}
+	if !authz.Can(user, action, reqPath) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
 	var rawURL string
 
 	storage, err := fs.GetStorage(reqPath)
@@ -304,6 +324,7 @@ func FsGet(c *gin.Context) {
 			Modified: obj.ModTime(),
 			Sign:     common.Sign(obj, parentPath, isEncrypt(meta, reqPath)),
 			Type:     utils.GetFileType(obj.GetName()),
+			HashInfo: model.GetHash(obj),
 		},
 		RawURL:   rawURL,
 		Readme:   getReadme(meta, reqPath),
@@ -356,6 +377,10 @@ func FsOther(c *gin.Context) {
 		common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
 		return
 	}
+	if !authz.Can(user, model.ActionRead, req.Path) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
 	res, err := fs.Other(c, req.FsOtherArgs)
 	if err != nil {
 		common.ErrorResp(c, err, 500)
@@ -363,3 +388,55 @@ func FsOther(c *gin.Context) {
 	}
 	common.SuccessResp(c, res)
 }
+
+type FsUsageReq struct {
+	Path     string `json:"path" form:"path"`
+	Password string `json:"password" form:"password"`
+}
+
+type FsUsageResp struct {
+	Total int64 `json:"total"`
+	Used  int64 `json:"used"`
+	Free  int64 `json:"free"`
+}
+
+// FsUsage get the capacity of the storage that the path is in
+func FsUsage(c *gin.Context) {
+	var req FsUsageReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	reqPath, err := user.JoinPath(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	meta, err := db.GetNearestMeta(reqPath)
+	if err != nil {
+		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			common.ErrorResp(c, err, 500)
+			return
+		}
+	}
+	c.Set("meta", meta)
+	if !common.CanAccess(user, meta, reqPath, req.Password) {
+		common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
+		return
+	}
+	if !authz.Can(user, model.ActionRead, reqPath) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
+	space, err := fs.GetSpace(c, reqPath)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, FsUsageResp{
+		Total: space.Total,
+		Used:  space.Used,
+		Free:  space.Free(),
+	})
+}
@@ -44,7 +44,7 @@ func FsStream(c *gin.Context) {
 		WebPutAsTask: asTask,
 	}
 	if asTask {
-		err = fs.PutAsTask(dir, stream)
+		err = fs.PutAsTask(c, dir, stream)
 	} else {
 		err = fs.PutDirectly(c, dir, stream)
 	}
@@ -100,7 +100,7 @@ func FsForm(c *gin.Context) {
 		WebPutAsTask: false,
 	}
 	if asTask {
-		err = fs.PutAsTask(dir, stream)
+		err = fs.PutAsTask(c, dir, stream)
 	} else {
 		err = fs.PutDirectly(c, dir, stream)
 	}
@@ -0,0 +1,66 @@
+package handles
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	_ "github.com/alist-org/alist/v3/drivers/local"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/server/middlewares"
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	conf.Conf = conf.DefaultConfig()
+	d, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	db.Init(d)
+}
+
+func TestFsStreamWithUploadToken(t *testing.T) {
+	root := t.TempDir()
+	addition := `{"root_folder_path":"` + filepath.ToSlash(root) + `"}`
+	if _, err := op.CreateStorage(context.Background(), model.Storage{Driver: "Local", MountPath: "/up", Addition: addition}); err != nil {
+		t.Fatalf("failed create local storage: %+v", err)
+	}
+	// the user can write, the token can't list or read
+	user := model.User{Username: "uploader", Password: "uploader", BasePath: "/", Role: model.GENERAL, Permission: 1 << 3}
+	if err := db.CreateUser(&user); err != nil {
+		t.Fatalf("failed create user: %+v", err)
+	}
+	token, err := db.CreateToken(&model.Token{UserID: user.ID, Name: "upload", Scopes: model.ScopeUpload})
+	if err != nil {
+		t.Fatalf("failed create token: %+v", err)
+	}
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.PUT("/api/fs/put", middlewares.Auth, middlewares.FsUp, FsStream)
+	put := func(path string) string {
+		req := httptest.NewRequest(http.MethodPut, "/api/fs/put", strings.NewReader("hello"))
+		req.Header.Set("Authorization", token)
+		req.Header.Set("File-Path", path)
+		req.ContentLength = 5
+		req.Header.Set("Content-Length", "5")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+	if body := put("/up/a.txt"); !strings.Contains(body, `"code":200`) {
+		t.Fatalf("failed upload with the upload token: %s", body)
+	}
+	if content, err := os.ReadFile(filepath.Join(root, "a.txt")); err != nil || string(content) != "hello" {
+		t.Errorf("unexpected uploaded file: %q %+v", content, err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/alist-org/alist/v3/internal/audit"
 	"github.com/alist-org/alist/v3/internal/db"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/pkg/utils"
@@ -45,7 +46,9 @@ func CreateMeta(c *gin.Context) {
 		return
 	}
 	req.Path = utils.StandardizePath(req.Path)
-	if err := db.CreateMeta(&req); err != nil {
+	err = db.CreateMeta(&req)
+	audit.Log(c, audit.MetaCreate, req.Path, "", err)
+	if err != nil {
 		common.ErrorResp(c, err, 500, true)
 	} else {
 		common.SuccessResp(c)
@@ -64,7 +67,9 @@ func UpdateMeta(c *gin.Context) {
 		return
 	}
 	req.Path = utils.StandardizePath(req.Path)
-	if err := db.UpdateMeta(&req); err != nil {
+	err = db.UpdateMeta(&req)
+	audit.Log(c, audit.MetaUpdate, req.Path, "", err)
+	if err != nil {
 		common.ErrorResp(c, err, 500, true)
 	} else {
 		common.SuccessResp(c)
@@ -89,7 +94,13 @@ func DeleteMeta(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	if err := db.DeleteMetaById(uint(id)); err != nil {
+	path := idStr
+	if meta, err := db.GetMetaById(uint(id)); err == nil {
+		path = meta.Path
+	}
+	err = db.DeleteMetaById(uint(id))
+	audit.Log(c, audit.MetaDelete, path, "", err)
+	if err != nil {
 		common.ErrorResp(c, err, 500, true)
 		return
 	}
@@ -0,0 +1,22 @@
+package handles
+
+import (
+	"crypto/subtle"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics exports the metrics in the prometheus text format
+func Metrics(c *gin.Context) {
+	if token := conf.Conf.Metrics.Token; token != "" {
+		if subtle.ConstantTimeCompare([]byte(c.GetHeader("Authorization")), []byte("Bearer "+token)) != 1 {
+			c.Status(401)
+			return
+		}
+	}
+	c.Header("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	c.Status(200)
+	metrics.Write(c.Writer)
+}
@@ -0,0 +1,22 @@
+package handles
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/alist-org/alist/v3/server/s3"
+	"github.com/gin-gonic/gin"
+)
+
+// S3Credentials returns the s3 access key of current user,
+// the secret is derived from the password so it's changed with the password
+func S3Credentials(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+	if user.IsGuest() {
+		common.ErrorStrResp(c, "Guest user has no s3 credentials", 403)
+		return
+	}
+	common.SuccessResp(c, gin.H{
+		"access_key_id":     user.Username,
+		"secret_access_key": s3.SecretKey(user),
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/alist-org/alist/v3/internal/audit"
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/db"
 	"github.com/alist-org/alist/v3/internal/model"
@@ -17,7 +18,9 @@ import (
 func ResetToken(c *gin.Context) {
 	token := random.Token()
 	item := model.SettingItem{Key: "token", Value: token, Type: conf.TypeString, Group: model.SINGLE, Flag: model.PRIVATE}
-	if err := db.SaveSettingItem(item); err != nil {
+	err := db.SaveSettingItem(item)
+	audit.Log(c, audit.SettingToken, conf.Token, "", err)
+	if err != nil {
 		common.ErrorResp(c, err, 500)
 		return
 	}
@@ -51,7 +54,12 @@ func SaveSettings(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	if err := db.SaveSettingItems(req); err != nil {
+	keys := changedSettingKeys(req)
+	err := db.SaveSettingItems(req)
+	if len(keys) > 0 {
+		audit.Log(c, audit.SettingSave, strings.Join(keys, ","), "", err)
+	}
+	if err != nil {
 		common.ErrorResp(c, err, 500)
 	} else {
 		common.SuccessResp(c)
@@ -59,6 +67,18 @@ func SaveSettings(c *gin.Context) {
 	}
 }
 
+// changedSettingKeys returns the keys of items whose value differs from the saved one
+func changedSettingKeys(items []model.SettingItem) []string {
+	saved := db.GetSettingsMap()
+	var keys []string
+	for _, item := range items {
+		if v, ok := saved.Load(item.Key); !ok || v != item.Value {
+			keys = append(keys, item.Key)
+		}
+	}
+	return keys
+}
+
 func ListSettings(c *gin.Context) {
 	groupStr := c.Query("group")
 	groupsStr := c.Query("groups")
@@ -93,7 +113,9 @@ func ListSettings(c *gin.Context) {
 
 func DeleteSetting(c *gin.Context) {
 	key := c.Query("key")
-	if err := db.DeleteSettingItemByKey(key); err != nil {
+	err := db.DeleteSettingItemByKey(key)
+	audit.Log(c, audit.SettingDelete, key, "", err)
+	if err != nil {
 		common.ErrorResp(c, err, 500)
 		return
 	}
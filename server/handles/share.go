@@ -0,0 +1,265 @@
+package handles
+
+import (
+	stdpath "path"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+func ListShares(c *gin.Context) {
+	listShares(c, c.MustGet("user").(*model.User).ID)
+}
+
+// ListAllShares lists the shares of all users for admin
+func ListAllShares(c *gin.Context) {
+	listShares(c, 0)
+}
+
+func listShares(c *gin.Context, creatorID uint) {
+	var req model.PageReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	shares, total, err := db.GetShares(creatorID, req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: shares,
+		Total:   total,
+	})
+}
+
+// getOwnShare returns the share if it's created by current user or current user is admin
+func getOwnShare(c *gin.Context, id string) (*model.Share, bool) {
+	user := c.MustGet("user").(*model.User)
+	share, err := db.GetShareById(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		common.ErrorStrResp(c, "share not found", 404)
+		return nil, false
+	}
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return nil, false
+	}
+	if !user.IsAdmin() && share.CreatorID != user.ID {
+		common.ErrorStrResp(c, "Permission denied", 403)
+		return nil, false
+	}
+	return share, true
+}
+
+func GetShare(c *gin.Context) {
+	share, ok := getOwnShare(c, c.Query("id"))
+	if !ok {
+		return
+	}
+	common.SuccessResp(c, share)
+}
+
+// checkSharePath joins the path with the base path of user and checks the user can access it
+func checkSharePath(c *gin.Context, share *model.Share) bool {
+	user := c.MustGet("user").(*model.User)
+	reqPath, err := user.JoinPath(share.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return false
+	}
+	meta, err := db.GetNearestMeta(reqPath)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		common.ErrorResp(c, err, 500, true)
+		return false
+	}
+	if !common.CanAccess(user, meta, reqPath, "") {
+		common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
+		return false
+	}
+	c.Set("meta", meta)
+	if _, err := fs.Get(c, reqPath); err != nil {
+		common.ErrorResp(c, err, 400)
+		return false
+	}
+	share.Path = reqPath
+	return true
+}
+
+// ShareReq is the share in create and update requests, the password isn't in the responses
+type ShareReq struct {
+	model.Share
+	// Password is kept unchanged by update if it's nil, and removed if it's empty
+	Password *string `json:"password"`
+}
+
+func CreateShare(c *gin.Context) {
+	var shareReq ShareReq
+	if err := c.ShouldBind(&shareReq); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req := shareReq.Share
+	if shareReq.Password != nil {
+		req.Password = *shareReq.Password
+	}
+	user := c.MustGet("user").(*model.User)
+	if user.IsGuest() {
+		common.ErrorStrResp(c, "Guest user can't create share", 403)
+		return
+	}
+	if !checkSharePath(c, &req) {
+		return
+	}
+	req.CreatorID, req.Creator = user.ID, user.Username
+	if err := db.CreateShare(&req); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, req)
+}
+
+func UpdateShare(c *gin.Context) {
+	var shareReq ShareReq
+	if err := c.ShouldBind(&shareReq); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req := shareReq.Share
+	old, ok := getOwnShare(c, req.ID)
+	if !ok {
+		return
+	}
+	req.Password = old.Password
+	if shareReq.Password != nil {
+		req.Password = *shareReq.Password
+	}
+	// the path is kept if unchanged, so that admin can update the shares of other users
+	if req.Path != old.Path && !checkSharePath(c, &req) {
+		return
+	}
+	if err := db.UpdateShare(&req); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func DeleteShare(c *gin.Context) {
+	id := c.Query("id")
+	if _, ok := getOwnShare(c, id); !ok {
+		return
+	}
+	if err := db.DeleteShareById(id); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+// ShareServe serves the shared obj without an account,
+// the files are downloaded and the folders are listed if it's allowed
+func ShareServe(c *gin.Context) {
+	share, err := db.GetShareById(c.Param("id"))
+	if err != nil {
+		common.ErrorStrResp(c, "share not found", 404)
+		return
+	}
+	if share.IsExpired() {
+		common.ErrorResp(c, errs.ShareExpired, 403)
+		return
+	}
+	if !share.ValidatePassword(sharePassword(c)) {
+		common.ErrorStrResp(c, "password is incorrect", 403)
+		return
+	}
+	// the shared obj is accessed as the creator, but the hidden objs are hidden like for guest
+	creator, err := db.GetUserById(share.CreatorID)
+	if err != nil {
+		common.ErrorStrResp(c, "share not found", 404)
+		return
+	}
+	guest, err := db.GetGuest()
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	// clean the sub path first so that it can't escape the shared root
+	reqPath := stdpath.Join(share.Path, stdpath.Clean("/"+c.Param("path")))
+	meta, err := db.GetNearestMeta(reqPath)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	if !common.CanAccess(creator, meta, reqPath, "") {
+		common.ErrorStrResp(c, "Permission denied", 403)
+		return
+	}
+	c.Set("user", guest)
+	c.Set("meta", meta)
+	obj, err := fs.Get(c, reqPath)
+	if err != nil {
+		common.ErrorResp(c, err, 404)
+		return
+	}
+	if obj.IsDir() {
+		shareList(c, share, meta, reqPath)
+		return
+	}
+	// the range requests from the middle of file are not counted, e.g. seeking in a video
+	if rng := c.GetHeader("Range"); rng == "" || strings.HasPrefix(rng, "bytes=0-") {
+		if err := db.IncreaseShareDownloads(share.ID); err != nil {
+			if errors.Is(errors.Cause(err), errs.ShareDownloadsReached) {
+				common.ErrorResp(c, err, 403)
+			} else {
+				common.ErrorResp(c, err, 500, true)
+			}
+			return
+		}
+	}
+	log.Debugf("download %s by share %s", reqPath, share.ID)
+	c.Set("path", reqPath)
+	Down(c)
+}
+
+// sharePassword returns the password of share in the header or the post form,
+// it's not accepted in the query so that it's not logged or leaked by the referer
+func sharePassword(c *gin.Context) string {
+	if password := c.GetHeader("Password"); password != "" {
+		return password
+	}
+	return c.PostForm("password")
+}
+
+func shareList(c *gin.Context, share *model.Share, meta *model.Meta, reqPath string) {
+	if !share.AllowList {
+		common.ErrorStrResp(c, "listing is not allowed", 403)
+		return
+	}
+	var req model.PageReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	objs, err := fs.List(c, reqPath)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	total, objs := pagination(objs, &req)
+	common.SuccessResp(c, FsListResp{
+		Content: toObjsResp(objs, reqPath, false),
+		Total:   int64(total),
+		Readme:  getReadme(meta, reqPath),
+	})
+}
@@ -0,0 +1,74 @@
+package handles
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/gin-gonic/gin"
+)
+
+func TestShareServePassword(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "a.txt"), []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	addition := `{"root_folder_path":"` + filepath.ToSlash(root) + `"}`
+	if _, err := op.CreateStorage(context.Background(), model.Storage{Driver: "Local", MountPath: "/shared", Addition: addition}); err != nil {
+		t.Fatalf("failed create local storage: %+v", err)
+	}
+	creator := model.User{Username: "sharer", Password: "sharer", BasePath: "/", Role: model.ADMIN}
+	if err := db.CreateUser(&creator); err != nil {
+		t.Fatalf("failed create user: %+v", err)
+	}
+	if _, err := db.GetGuest(); err != nil {
+		if err := db.CreateUser(&model.User{Username: "guest", Password: "guest", BasePath: "/", Role: model.GUEST}); err != nil {
+			t.Fatalf("failed create guest: %+v", err)
+		}
+	}
+	share := model.Share{Path: "/shared", CreatorID: creator.ID, Password: "secret", AllowList: true}
+	if err := db.CreateShare(&share); err != nil {
+		t.Fatalf("failed create share: %+v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/s/:id", ShareServe)
+	r.POST("/s/:id", ShareServe)
+	r.GET("/api/share/get", func(c *gin.Context) {
+		c.Set("user", &creator)
+		GetShare(c)
+	})
+	serve := func(req *http.Request) string {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	// the password in the query is ignored
+	if body := serve(httptest.NewRequest(http.MethodGet, "/s/"+share.ID+"?pwd=secret", nil)); !strings.Contains(body, `"code":403`) {
+		t.Errorf("expect 403 with the password in query, got %s", body)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/s/"+share.ID, nil)
+	req.Header.Set("Password", "secret")
+	if body := serve(req); !strings.Contains(body, `"code":200`) || !strings.Contains(body, "a.txt") {
+		t.Errorf("expect listing with the password in header, got %s", body)
+	}
+	req = httptest.NewRequest(http.MethodPost, "/s/"+share.ID, strings.NewReader(url.Values{"password": {"secret"}}.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if body := serve(req); !strings.Contains(body, `"code":200`) || !strings.Contains(body, "a.txt") {
+		t.Errorf("expect listing with the password in form, got %s", body)
+	}
+
+	if body := serve(httptest.NewRequest(http.MethodGet, "/api/share/get?id=missing", nil)); !strings.Contains(body, `"code":404`) {
+		t.Errorf("expect 404 for the missing share, got %s", body)
+	}
+}
@@ -1,13 +1,21 @@
 package handles
 
 import (
+	"context"
 	"strconv"
+	"sync"
+	"time"
 
+	"github.com/alist-org/alist/v3/internal/audit"
 	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/health"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/server/common"
 	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -25,18 +33,79 @@ func ListStorages(c *gin.Context) {
 		return
 	}
 	common.SuccessResp(c, common.PageResp{
-		Content: storages,
+		Content: storageResps(c, storages),
 		Total:   total,
 	})
 }
 
+type StorageResp struct {
+	model.Storage
+	Space  *model.Space         `json:"space"`
+	Health *model.StorageHealth `json:"health"`
+}
+
+// spaceTimeout limits the time of getting the capacity of each storage,
+// so that a slow storage doesn't block listing the storages
+var spaceTimeout = 10 * time.Second
+
+// storageResps adds the health and capacity of the loaded storages, the capacity is got concurrently,
+// the space is nil if the driver doesn't support it or failed to get it in time
+func storageResps(c *gin.Context, storages []model.Storage) []StorageResp {
+	resp := make([]StorageResp, len(storages))
+	var wg sync.WaitGroup
+	for i := range storages {
+		resp[i].Storage = storages[i]
+		resp[i].Health, _ = health.Get(storages[i].ID)
+		storageDriver, err := op.GetStorageByVirtualPath(storages[i].MountPath)
+		if err != nil || storages[i].Disabled {
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			space, err := getSpace(c, storageDriver)
+			if err == nil {
+				resp[i].Space = space
+			} else if !errors.Is(err, errs.NotImplement) {
+				log.Warnf("failed get space of %s: %+v", storages[i].MountPath, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	return resp
+}
+
+// getSpace gets the capacity of storage with spaceTimeout,
+// it doesn't wait for the drivers which ignore the canceled context
+func getSpace(ctx context.Context, storageDriver driver.Driver) (*model.Space, error) {
+	ctx, cancel := context.WithTimeout(ctx, spaceTimeout)
+	defer cancel()
+	type result struct {
+		space *model.Space
+		err   error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		space, err := op.GetSpace(ctx, storageDriver)
+		ch <- result{space, err}
+	}()
+	select {
+	case r := <-ch:
+		return r.space, r.err
+	case <-ctx.Done():
+		return nil, errors.WithStack(ctx.Err())
+	}
+}
+
 func CreateStorage(c *gin.Context) {
 	var req model.Storage
 	if err := c.ShouldBind(&req); err != nil {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	if id, err := op.CreateStorage(c, req); err != nil {
+	id, err := op.CreateStorage(c, req)
+	audit.Log(c, audit.StorageCreate, req.MountPath, "", err)
+	if err != nil {
 		common.ErrorWithDataResp(c, err, 500, gin.H{
 			"id": id,
 		}, true)
@@ -53,7 +122,9 @@ func UpdateStorage(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	if err := op.UpdateStorage(c, req); err != nil {
+	err := op.UpdateStorage(c, req)
+	audit.Log(c, audit.StorageUpdate, req.MountPath, "", err)
+	if err != nil {
 		common.ErrorResp(c, err, 500, true)
 	} else {
 		common.SuccessResp(c)
@@ -67,7 +138,10 @@ func DeleteStorage(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	if err := op.DeleteStorageById(c, uint(id)); err != nil {
+	path := storageMountPath(uint(id))
+	err = op.DeleteStorageById(c, uint(id))
+	audit.Log(c, audit.StorageDelete, path, "", err)
+	if err != nil {
 		common.ErrorResp(c, err, 500, true)
 		return
 	}
@@ -81,7 +155,10 @@ func DisableStorage(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	if err := op.DisableStorage(c, uint(id)); err != nil {
+	path := storageMountPath(uint(id))
+	err = op.DisableStorage(c, uint(id))
+	audit.Log(c, audit.StorageDisable, path, "", err)
+	if err != nil {
 		common.ErrorResp(c, err, 500, true)
 		return
 	}
@@ -95,7 +172,10 @@ func EnableStorage(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	if err := op.EnableStorage(c, uint(id)); err != nil {
+	path := storageMountPath(uint(id))
+	err = op.EnableStorage(c, uint(id))
+	audit.Log(c, audit.StorageEnable, path, "", err)
+	if err != nil {
 		common.ErrorResp(c, err, 500, true)
 		return
 	}
@@ -114,5 +194,14 @@ func GetStorage(c *gin.Context) {
 		common.ErrorResp(c, err, 500, true)
 		return
 	}
-	common.SuccessResp(c, storage)
+	common.SuccessResp(c, storageResps(c, []model.Storage{*storage})[0])
+}
+
+// storageMountPath returns the mount path of storage for the audit logs, or its id if it's not found
+func storageMountPath(id uint) string {
+	storage, err := db.GetStorageById(id)
+	if err != nil {
+		return strconv.Itoa(int(id))
+	}
+	return storage.MountPath
 }
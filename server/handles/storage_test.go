@@ -0,0 +1,38 @@
+package handles
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/drivers/local"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+// slowQuota is a storage whose GetSpace ignores the context and blocks until released
+type slowQuota struct {
+	*local.Local
+	release chan struct{}
+}
+
+func (d *slowQuota) GetSpace(ctx context.Context) (int64, int64, error) {
+	<-d.release
+	return 0, 0, nil
+}
+
+func TestGetSpaceTimeout(t *testing.T) {
+	spaceTimeout = 50 * time.Millisecond
+	defer func() { spaceTimeout = 10 * time.Second }()
+	d := &slowQuota{Local: &local.Local{}, release: make(chan struct{})}
+	d.SetStorage(model.Storage{MountPath: "/slow"})
+	defer close(d.release)
+	start := time.Now()
+	_, err := getSpace(context.Background(), d)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expect deadline exceeded, got %+v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("expect not waiting for the slow storage, took %s", time.Since(start))
+	}
+}
@@ -17,10 +17,18 @@ type TaskInfo struct {
 	Status   string `json:"status"`
 	Progress int    `json:"progress"`
 	Error    string `json:"error"`
+	// the fields below are only set for the tasks with parent or children
+	ParentID    string `json:"parent_id,omitempty"`
+	Children    int    `json:"children,omitempty"`
+	TotalBytes  int64  `json:"total_bytes,omitempty"`
+	DoneBytes   int64  `json:"done_bytes,omitempty"`
+	TotalFiles  int    `json:"total_files,omitempty"`
+	DoneFiles   int    `json:"done_files,omitempty"`
+	FailedFiles int    `json:"failed_files,omitempty"`
 }
 
 func getTaskInfoUint(task *task.Task[uint64]) TaskInfo {
-	return TaskInfo{
+	info := TaskInfo{
 		ID:       strconv.FormatUint(task.ID, 10),
 		Name:     task.Name,
 		State:    task.GetState(),
@@ -28,6 +36,19 @@ func getTaskInfoUint(task *task.Task[uint64]) TaskInfo {
 		Progress: task.GetProgress(),
 		Error:    task.GetErrMsg(),
 	}
+	if parent := task.GetParent(); parent != nil {
+		info.ParentID = strconv.FormatUint(parent.ID, 10)
+	}
+	if children := task.GetChildren(); len(children) > 0 {
+		stats := task.GetStats()
+		info.Children = len(children)
+		info.TotalBytes = stats.TotalBytes
+		info.DoneBytes = stats.DoneBytes
+		info.TotalFiles = stats.TotalFiles
+		info.DoneFiles = stats.DoneFiles
+		info.FailedFiles = stats.FailedFiles
+	}
+	return info
 }
 
 func getTaskInfoStr(task *task.Task[string]) TaskInfo {
@@ -206,7 +227,108 @@ func DeleteCopyTask(c *gin.Context) {
 	}
 }
 
+func RetryCopyTask(c *gin.Context) {
+	id := c.Query("tid")
+	tid, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := fs.CopyTaskManager.Retry(tid); err != nil {
+		common.ErrorResp(c, err, 500)
+	} else {
+		common.SuccessResp(c)
+	}
+}
+
+// ChildrenCopyTask lists the children of a copy task
+func ChildrenCopyTask(c *gin.Context) {
+	id := c.Query("tid")
+	tid, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	t, ok := fs.CopyTaskManager.Get(tid)
+	if !ok {
+		common.ErrorResp(c, task.ErrTaskNotFound, 404)
+		return
+	}
+	common.SuccessResp(c, getTaskInfosUint(t.GetChildren()))
+}
+
+// FailedCopyTask lists the failed files of a copy task and its descendants
+func FailedCopyTask(c *gin.Context) {
+	id := c.Query("tid")
+	tid, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	t, ok := fs.CopyTaskManager.Get(tid)
+	if !ok {
+		common.ErrorResp(c, task.ErrTaskNotFound, 404)
+		return
+	}
+	common.SuccessResp(c, t.GetFailedItems())
+}
+
 func ClearDoneCopyTasks(c *gin.Context) {
 	fs.CopyTaskManager.ClearDone()
 	common.SuccessResp(c)
 }
+
+func UndoneMoveTask(c *gin.Context) {
+	common.SuccessResp(c, getTaskInfosUint(fs.MoveTaskManager.ListUndone()))
+}
+
+func DoneMoveTask(c *gin.Context) {
+	common.SuccessResp(c, getTaskInfosUint(fs.MoveTaskManager.ListDone()))
+}
+
+func CancelMoveTask(c *gin.Context) {
+	id := c.Query("tid")
+	tid, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := fs.MoveTaskManager.Cancel(tid); err != nil {
+		common.ErrorResp(c, err, 500)
+	} else {
+		common.SuccessResp(c)
+	}
+}
+
+func RetryMoveTask(c *gin.Context) {
+	id := c.Query("tid")
+	tid, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := fs.MoveTaskManager.Retry(tid); err != nil {
+		common.ErrorResp(c, err, 500)
+	} else {
+		common.SuccessResp(c)
+	}
+}
+
+func DeleteMoveTask(c *gin.Context) {
+	id := c.Query("tid")
+	tid, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := fs.MoveTaskManager.Remove(tid); err != nil {
+		common.ErrorResp(c, err, 500)
+	} else {
+		common.SuccessResp(c)
+	}
+}
+
+func ClearDoneMoveTasks(c *gin.Context) {
+	fs.MoveTaskManager.ClearDone()
+	common.SuccessResp(c)
+}
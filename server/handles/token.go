@@ -0,0 +1,85 @@
+package handles
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+func ListTokens(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+	tokens, err := db.GetTokens(user.ID)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, tokens)
+}
+
+// CreateToken creates a personal access token of current user,
+// the token is only returned here, it can't be got again.
+func CreateToken(c *gin.Context) {
+	var req model.Token
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	if user.IsGuest() {
+		common.ErrorStrResp(c, "Guest user can't create tokens", 403)
+		return
+	}
+	scopes := req.ScopeList()
+	if len(scopes) == 0 {
+		common.ErrorStrResp(c, "scopes is required", 400)
+		return
+	}
+	for _, s := range scopes {
+		if !model.ValidScope(s) {
+			common.ErrorStrResp(c, fmt.Sprintf("unknown scope: %s", s), 400)
+			return
+		}
+		if s == model.ScopeAdmin && !user.IsAdmin() {
+			common.ErrorStrResp(c, "only admin can create admin tokens", 403)
+			return
+		}
+	}
+	req.Scopes = strings.Join(scopes, ",")
+	if req.Path != "" {
+		path, err := user.JoinPath(req.Path)
+		if err != nil {
+			common.ErrorResp(c, err, 403)
+			return
+		}
+		req.Path = path
+	}
+	req.UserID = user.ID
+	token, err := db.CreateToken(&req)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, gin.H{
+		"token": token,
+		"info":  req,
+	})
+}
+
+func DeleteToken(c *gin.Context) {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	if err := db.DeleteToken(user.ID, uint(id)); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
@@ -3,6 +3,7 @@ package handles
 import (
 	"strconv"
 
+	"github.com/alist-org/alist/v3/internal/audit"
 	"github.com/alist-org/alist/v3/internal/db"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/server/common"
@@ -23,6 +24,10 @@ func ListUsers(c *gin.Context) {
 		common.ErrorResp(c, err, 500, true)
 		return
 	}
+	// the hash of password is only kept in the backup
+	for i := range users {
+		users[i].Password = ""
+	}
 	common.SuccessResp(c, common.PageResp{
 		Content: users,
 		Total:   total,
@@ -39,7 +44,9 @@ func CreateUser(c *gin.Context) {
 		common.ErrorStrResp(c, "admin or guest user can not be created", 400, true)
 		return
 	}
-	if err := db.CreateUser(&req); err != nil {
+	err := db.CreateUser(&req)
+	audit.Log(c, audit.UserCreate, req.Username, "", err)
+	if err != nil {
 		common.ErrorResp(c, err, 500, true)
 	} else {
 		common.SuccessResp(c)
@@ -67,7 +74,9 @@ func UpdateUser(c *gin.Context) {
 	if req.OtpSecret == "" {
 		req.OtpSecret = user.OtpSecret
 	}
-	if err := db.UpdateUser(&req); err != nil {
+	err = db.UpdateUser(&req)
+	audit.Log(c, audit.UserUpdate, user.Username, req.Username, err)
+	if err != nil {
 		common.ErrorResp(c, err, 500)
 	} else {
 		common.SuccessResp(c)
@@ -81,7 +90,13 @@ func DeleteUser(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
-	if err := db.DeleteUserById(uint(id)); err != nil {
+	name := idStr
+	if user, err := db.GetUserById(uint(id)); err == nil {
+		name = user.Username
+	}
+	err = db.DeleteUserById(uint(id))
+	audit.Log(c, audit.UserDelete, name, "", err)
+	if err != nil {
 		common.ErrorResp(c, err, 500)
 		return
 	}
@@ -100,6 +115,7 @@ func GetUser(c *gin.Context) {
 		common.ErrorResp(c, err, 500, true)
 		return
 	}
+	user.Password = ""
 	common.SuccessResp(c, user)
 }
 
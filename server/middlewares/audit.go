@@ -0,0 +1,14 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// Audit puts the client ip and the entrance into the context for the audit logs
+func Audit(via string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("ip", c.ClientIP())
+		c.Set("via", via)
+		c.Next()
+	}
+}
@@ -1,6 +1,8 @@
 package middlewares
 
 import (
+	"strings"
+
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/db"
 	"github.com/alist-org/alist/v3/internal/model"
@@ -13,54 +15,57 @@ import (
 // Auth is a middleware that checks if the user is logged in.
 // if token is empty, set user to guest
 func Auth(c *gin.Context) {
-	token := c.GetHeader("Authorization")
+	user, status, err := getUser(c.GetHeader("Authorization"))
+	if err != nil {
+		common.ErrorResp(c, err, status)
+		c.Abort()
+		return
+	}
+	c.Set("user", user)
+	log.Debugf("use user: %+v", user)
+	c.Next()
+}
+
+// getUser returns the user of token, the status is for the error
+func getUser(token string) (*model.User, int, error) {
 	if token == setting.GetStr(conf.Token) {
 		admin, err := db.GetAdmin()
-		if err != nil {
-			common.ErrorResp(c, err, 500)
-			c.Abort()
-			return
-		}
-		c.Set("user", admin)
-		log.Debugf("use admin token: %+v", admin)
-		c.Next()
-		return
+		return admin, 500, err
 	}
 	if token == "" {
 		guest, err := db.GetGuest()
-		if err != nil {
-			common.ErrorResp(c, err, 500)
-			c.Abort()
-			return
-		}
-		c.Set("user", guest)
-		log.Debugf("use empty token: %+v", guest)
-		c.Next()
-		return
+		return guest, 500, err
+	}
+	if strings.HasPrefix(token, model.TokenPrefix) {
+		user, err := db.GetUserByToken(token)
+		return user, 401, err
 	}
 	userClaims, err := common.ParseToken(token)
 	if err != nil {
-		common.ErrorResp(c, err, 401)
-		c.Abort()
-		return
+		return nil, 401, err
 	}
 	user, err := db.GetUserByName(userClaims.Username)
-	if err != nil {
-		common.ErrorResp(c, err, 401)
-		c.Abort()
-		return
-	}
-	c.Set("user", user)
-	log.Debugf("use login token: %+v", user)
-	c.Next()
+	return user, 401, err
 }
 
 func AuthAdmin(c *gin.Context) {
 	user := c.MustGet("user").(*model.User)
-	if !user.IsAdmin() {
+	if !user.IsAdmin() || (user.Token != nil && !user.Token.HasScope(model.ScopeAdmin)) {
 		common.ErrorStrResp(c, "You are not an admin", 403)
 		c.Abort()
 	} else {
 		c.Next()
 	}
 }
+
+// AuthWithoutToken rejects the requests authenticated by personal access tokens,
+// so a token can't be used to manage the account or create more powerful tokens
+func AuthWithoutToken(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+	if user.Token != nil {
+		common.ErrorStrResp(c, "This can't be done with an access token", 403)
+		c.Abort()
+	} else {
+		c.Next()
+	}
+}
@@ -4,10 +4,12 @@ import (
 	"net/url"
 	stdpath "path"
 
+	"github.com/alist-org/alist/v3/internal/authz"
 	"github.com/alist-org/alist/v3/internal/db"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/server/common"
+	"github.com/alist-org/alist/v3/server/webdav"
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
 )
@@ -35,10 +37,23 @@ func FsUp(c *gin.Context) {
 			return
 		}
 	}
-	if !(common.CanAccess(user, meta, path, password) && (user.CanWrite() || common.CanWrite(meta, path))) {
+	// the path doesn't need to be listed or read, so that the upload-only tokens can write it
+	if !(common.CanAccessMeta(user, meta, path, password) && authz.Can(user, model.ActionWrite, path)) {
 		common.ErrorResp(c, errs.PermissionDenied, 403)
 		c.Abort()
 		return
 	}
+	locked, err := webdav.Locked(path)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		c.Abort()
+		return
+	}
+	if locked {
+		common.ErrorResp(c, errs.ObjectLocked, 423)
+		c.Abort()
+		return
+	}
+	c.Set("path", path)
 	c.Next()
 }
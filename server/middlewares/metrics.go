@@ -0,0 +1,38 @@
+package middlewares
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// servedVia maps the route prefixes to the via label of served bytes
+var servedVia = map[string]string{
+	"/d/":  "d",
+	"/p/":  "p",
+	"/dav": "webdav",
+}
+
+// Metrics records the count, latency and served bytes of requests by route
+func Metrics(c *gin.Context) {
+	start := time.Now()
+	c.Next()
+	route := c.FullPath()
+	if route == "" {
+		route = "unmatched"
+	}
+	method := c.Request.Method
+	metrics.HTTPRequests.Inc(method, route, strconv.Itoa(c.Writer.Status()))
+	metrics.HTTPDuration.Observe(time.Since(start).Seconds(), method, route)
+	if size := c.Writer.Size(); size > 0 {
+		for prefix, via := range servedVia {
+			if strings.HasPrefix(route, prefix) {
+				metrics.ServedBytes.Add(float64(size), via)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package middlewares
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/ratelimit"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimit limits the request rate and the bandwidth of the client,
+// it should be used after the middlewares which set the "user" and the "path" of request.
+// If the user isn't set, such as /d and /p, it's resolved from the Authorization header,
+// and the client is limited by ip if the header is empty or invalid.
+func RateLimit(c *gin.Context) {
+	var user *model.User
+	if u, ok := c.Get("user"); ok {
+		user = u.(*model.User)
+	} else if u, _, err := getUser(c.GetHeader("Authorization")); err == nil {
+		user = u
+	}
+	ip := c.ClientIP()
+	if d, ok := ratelimit.Allow(user, ip); !ok {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(d.Seconds()))))
+		// the real status is used since the downloaders and webdav clients don't read the body
+		c.AbortWithStatusJSON(429, common.Resp[interface{}]{
+			Code:    429,
+			Message: "Too many requests, try again later",
+		})
+		return
+	}
+	path := c.GetString("path")
+	if limiters := ratelimit.Limiters(ratelimit.Download, user, ip, path); len(limiters) > 0 {
+		c.Writer = &limitedWriter{
+			ResponseWriter: c.Writer,
+			w:              &ratelimit.Writer{Ctx: c.Request.Context(), Writer: c.Writer, Limiters: limiters},
+		}
+	}
+	if limiters := ratelimit.Limiters(ratelimit.Upload, user, ip, path); len(limiters) > 0 && c.Request.Body != nil {
+		c.Request.Body = &ratelimit.Reader{Ctx: c.Request.Context(), Reader: c.Request.Body, Limiters: limiters}
+	}
+	c.Next()
+}
+
+type limitedWriter struct {
+	gin.ResponseWriter
+	w *ratelimit.Writer
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	return w.w.Write(p)
+}
+
+func (w *limitedWriter) WriteString(s string) (int, error) {
+	return w.w.Write([]byte(s))
+}
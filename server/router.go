@@ -10,12 +10,25 @@ import (
 	"github.com/alist-org/alist/v3/server/static"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
 )
 
 func Init(r *gin.Engine) {
 	common.SecretKey = []byte(conf.Conf.JwtSecret)
 	Cors(r)
+	if conf.Conf.Metrics.Enable {
+		r.Use(middlewares.Metrics)
+		// the metrics on the main listener are public without a token, so they are refused
+		if conf.Conf.Metrics.Port == 0 {
+			if conf.Conf.Metrics.Token != "" {
+				r.GET("/metrics", handles.Metrics)
+			} else {
+				log.Warnf("/metrics is not served since neither metrics.port nor metrics.token is set")
+			}
+		}
+	}
 	r.Use(middlewares.StoragesLoaded)
+	r.Use(middlewares.Audit("api"))
 	if conf.Conf.MaxConnections > 0 {
 		r.Use(middlewares.MaxAllowed(conf.Conf.MaxConnections))
 	}
@@ -23,23 +36,35 @@ func Init(r *gin.Engine) {
 
 	r.GET("/favicon.ico", handles.Favicon)
 	r.GET("/i/:link_name", handles.Plist)
-	r.GET("/d/*path", middlewares.Down, handles.Down)
-	r.GET("/p/*path", middlewares.Down, handles.Proxy)
+	r.GET("/d/*path", middlewares.Down, middlewares.RateLimit, handles.Down)
+	r.GET("/p/*path", middlewares.Down, middlewares.RateLimit, handles.Proxy)
+	r.GET("/s/:id", handles.ShareServe)
+	r.GET("/s/:id/*path", handles.ShareServe)
+	// the password of share is posted by the form
+	r.POST("/s/:id", handles.ShareServe)
+	r.POST("/s/:id/*path", handles.ShareServe)
 
 	api := r.Group("/api")
 	auth := api.Group("", middlewares.Auth)
 
 	api.POST("/auth/login", handles.Login)
 	auth.GET("/me", handles.CurrentUser)
-	auth.POST("/me/update", handles.UpdateCurrent)
-	auth.POST("/auth/2fa/generate", handles.Generate2FA)
-	auth.POST("/auth/2fa/verify", handles.Verify2FA)
+	auth.POST("/me/update", middlewares.AuthWithoutToken, handles.UpdateCurrent)
+	auth.GET("/me/s3", middlewares.AuthWithoutToken, handles.S3Credentials)
+	auth.POST("/auth/2fa/generate", middlewares.AuthWithoutToken, handles.Generate2FA)
+	auth.POST("/auth/2fa/verify", middlewares.AuthWithoutToken, handles.Verify2FA)
+
+	token := auth.Group("/me/token", middlewares.AuthWithoutToken)
+	token.GET("/list", handles.ListTokens)
+	token.POST("/create", handles.CreateToken)
+	token.POST("/delete", handles.DeleteToken)
 
 	// no need auth
 	public := api.Group("/public")
 	public.Any("/settings", handles.PublicSettings)
 
 	_fs(auth.Group("/fs"))
+	share(auth.Group("/share"))
 	admin(auth.Group("/admin", middlewares.AuthAdmin))
 	if flags.Dev {
 		dev(r.Group("/dev"))
@@ -63,6 +88,39 @@ func admin(g *gin.RouterGroup) {
 	user.POST("/cancel_2fa", handles.Cancel2FAById)
 	user.POST("/delete", handles.DeleteUser)
 
+	group := g.Group("/group")
+	group.GET("/list", handles.ListGroups)
+	group.GET("/get", handles.GetGroup)
+	group.POST("/create", handles.CreateGroup)
+	group.POST("/update", handles.UpdateGroup)
+	group.POST("/delete", handles.DeleteGroup)
+
+	acl := g.Group("/acl")
+	acl.GET("/list", handles.ListACLRules)
+	acl.GET("/get", handles.GetACLRule)
+	acl.POST("/create", handles.CreateACLRule)
+	acl.POST("/update", handles.UpdateACLRule)
+	acl.POST("/delete", handles.DeleteACLRule)
+
+	hook := g.Group("/webhook")
+	hook.GET("/list", handles.ListWebhooks)
+	hook.GET("/get", handles.GetWebhook)
+	hook.POST("/create", handles.CreateWebhook)
+	hook.POST("/update", handles.UpdateWebhook)
+	hook.POST("/delete", handles.DeleteWebhook)
+	hook.POST("/ping", handles.PingWebhook)
+	hook.GET("/deliveries", handles.ListWebhookDeliveries)
+
+	auditLog := g.Group("/audit")
+	auditLog.GET("/list", handles.ListAuditLogs)
+	auditLog.GET("/export", handles.ExportAuditLogs)
+
+	adminShare := g.Group("/share")
+	adminShare.GET("/list", handles.ListAllShares)
+	adminShare.GET("/get", handles.GetShare)
+	adminShare.POST("/update", handles.UpdateShare)
+	adminShare.POST("/delete", handles.DeleteShare)
+
 	storage := g.Group("/storage")
 	storage.GET("/list", handles.ListStorages)
 	storage.GET("/get", handles.GetStorage)
@@ -85,6 +143,10 @@ func admin(g *gin.RouterGroup) {
 	setting.POST("/reset_token", handles.ResetToken)
 	setting.POST("/set_aria2", handles.SetAria2)
 
+	backup := g.Group("/backup")
+	backup.POST("/export", handles.ExportBackup)
+	backup.POST("/import", handles.ImportBackup)
+
 	task := g.Group("/task")
 	task.GET("/down/undone", handles.UndoneDownTask)
 	task.GET("/down/done", handles.DoneDownTask)
@@ -103,9 +165,18 @@ func admin(g *gin.RouterGroup) {
 	task.POST("/upload/clear_done", handles.ClearDoneUploadTasks)
 	task.GET("/copy/undone", handles.UndoneCopyTask)
 	task.GET("/copy/done", handles.DoneCopyTask)
+	task.GET("/copy/children", handles.ChildrenCopyTask)
+	task.GET("/copy/failed", handles.FailedCopyTask)
 	task.POST("/copy/cancel", handles.CancelCopyTask)
+	task.POST("/copy/retry", handles.RetryCopyTask)
 	task.POST("/copy/delete", handles.DeleteCopyTask)
 	task.POST("/copy/clear_done", handles.ClearDoneCopyTasks)
+	task.GET("/move/undone", handles.UndoneMoveTask)
+	task.GET("/move/done", handles.DoneMoveTask)
+	task.POST("/move/cancel", handles.CancelMoveTask)
+	task.POST("/move/retry", handles.RetryMoveTask)
+	task.POST("/move/delete", handles.DeleteMoveTask)
+	task.POST("/move/clear_done", handles.ClearDoneMoveTasks)
 
 	ms := g.Group("/message")
 	ms.POST("/get", message.HttpInstance.GetHandle)
@@ -119,19 +190,28 @@ func admin(g *gin.RouterGroup) {
 
 }
 
+func share(g *gin.RouterGroup) {
+	g.GET("/list", handles.ListShares)
+	g.GET("/get", handles.GetShare)
+	g.POST("/create", handles.CreateShare)
+	g.POST("/update", handles.UpdateShare)
+	g.POST("/delete", handles.DeleteShare)
+}
+
 func _fs(g *gin.RouterGroup) {
 	g.Any("/list", handles.FsList)
 	g.Any("/search", middlewares.SearchIndex, handles.Search)
 	g.Any("/get", handles.FsGet)
 	g.Any("/other", handles.FsOther)
+	g.Any("/usage", handles.FsUsage)
 	g.Any("/dirs", handles.FsDirs)
 	g.POST("/mkdir", handles.FsMkdir)
 	g.POST("/rename", handles.FsRename)
 	g.POST("/move", handles.FsMove)
 	g.POST("/copy", handles.FsCopy)
 	g.POST("/remove", handles.FsRemove)
-	g.PUT("/put", middlewares.FsUp, handles.FsStream)
-	g.PUT("/form", middlewares.FsUp, handles.FsForm)
+	g.PUT("/put", middlewares.FsUp, middlewares.RateLimit, handles.FsStream)
+	g.PUT("/form", middlewares.FsUp, middlewares.RateLimit, handles.FsForm)
 	g.POST("/link", middlewares.AuthAdmin, handles.Link)
 	g.POST("/add_aria2", handles.AddAria2)
 }
@@ -0,0 +1,320 @@
+package s3
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	signV4Algorithm          = "AWS4-HMAC-SHA256"
+	amzDateFormat            = "20060102T150405Z"
+	unsignedPayload          = "UNSIGNED-PAYLOAD"
+	streamingPayload         = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
+	streamingUnsignedPayload = "STREAMING-UNSIGNED-PAYLOAD-TRAILER"
+	maxSkew                  = 15 * time.Minute
+	maxPresignExpires        = 7 * 24 * 60 * 60 // a week in seconds, the same as aws
+)
+
+// SecretKey derives the secret access key of user, the access key id is the username.
+// It's changed with the password of user.
+func SecretKey(user *model.User) string {
+	mac := hmac.New(sha256.New, []byte(conf.Conf.JwtSecret))
+	mac.Write([]byte("s3:" + user.Username + ":" + user.Password))
+	return hex.EncodeToString(mac.Sum(nil))[:40]
+}
+
+// Auth verifies the aws signature v4 of the request, anonymous requests are treated as guest.
+// The body is replaced with the one checked with the signed payload while reading.
+func Auth(c *gin.Context) {
+	user, sig, err := authenticate(c.Request)
+	if err == nil {
+		c.Request.Body, err = verifyBody(c.Request, sig)
+	}
+	if err != nil {
+		writeError(c, err)
+		c.Abort()
+		return
+	}
+	c.Set("user", user)
+	c.Next()
+}
+
+// signature is the parsed signature info of a request
+type signature struct {
+	accessKey     string
+	date          string // yyyymmdd
+	region        string
+	service       string
+	signedHeaders []string
+	signature     string
+	amzDate       time.Time
+	presigned     bool
+	key           []byte // the signing key, set after the signature is verified
+}
+
+// authenticate returns the user of request and its signature, which is nil for anonymous requests
+func authenticate(r *http.Request) (*model.User, *signature, error) {
+	var sig *signature
+	var err error
+	if strings.HasPrefix(r.Header.Get("Authorization"), signV4Algorithm) {
+		sig, err = parseHeaderSignature(r)
+	} else if r.URL.Query().Get("X-Amz-Algorithm") == signV4Algorithm {
+		sig, err = parsePresignedSignature(r)
+	} else if r.Header.Get("Authorization") == "" {
+		guest, err := db.GetGuest()
+		if err != nil {
+			return nil, nil, ErrAccessDenied
+		}
+		return guest, nil, nil
+	} else {
+		// signature v2 is not supported
+		return nil, nil, ErrAuthorizationHeader
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	user, err := db.GetUserByName(sig.accessKey)
+	if err != nil {
+		return nil, nil, ErrInvalidAccessKeyID
+	}
+	if user.IsGuest() {
+		return nil, nil, ErrInvalidAccessKeyID
+	}
+	secret := SecretKey(user)
+	expected := computeSignature(r, sig, secret)
+	if !hmac.Equal([]byte(expected), []byte(sig.signature)) {
+		return nil, nil, ErrSignatureDoesNotMatch
+	}
+	sig.key = signingKey(sig, secret)
+	return user, sig, nil
+}
+
+// verifyBody wraps the body to check it with the payload hash of signature while reading,
+// the aws-chunked body is decoded and the signature of every chunk is checked.
+// The body of anonymous or unsigned payload requests is not checked.
+func verifyBody(r *http.Request, sig *signature) (io.ReadCloser, error) {
+	hash := r.Header.Get("X-Amz-Content-Sha256")
+	if strings.HasPrefix(hash, "STREAMING-") {
+		var signer *chunkSigner
+		if sig != nil && hash != streamingUnsignedPayload {
+			if hash != streamingPayload {
+				return nil, ErrNotImplemented
+			}
+			signer = &chunkSigner{
+				key:      sig.key,
+				amzDate:  sig.amzDate.UTC().Format(amzDateFormat),
+				scope:    sig.scope(),
+				previous: sig.signature,
+			}
+		}
+		return utils.ReadCloser{Reader: newChunkedReader(r.Body, signer), Closer: r.Body}, nil
+	}
+	if sig == nil {
+		return r.Body, nil
+	}
+	expected := payloadHash(r, sig.presigned)
+	if expected == unsignedPayload {
+		return r.Body, nil
+	}
+	return utils.ReadCloser{Reader: newHashReader(r.Body, expected, r.ContentLength), Closer: r.Body}, nil
+}
+
+// parseHeaderSignature parses the Authorization header like
+// AWS4-HMAC-SHA256 Credential=AKID/20130524/us-east-1/s3/aws4_request, SignedHeaders=host;x-amz-date, Signature=xxx
+func parseHeaderSignature(r *http.Request) (*signature, error) {
+	auth := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), signV4Algorithm))
+	fields := make(map[string]string)
+	for _, field := range strings.Split(auth, ",") {
+		kv := strings.SplitN(strings.TrimSpace(field), "=", 2)
+		if len(kv) != 2 {
+			return nil, ErrAuthorizationHeader
+		}
+		fields[kv[0]] = kv[1]
+	}
+	sig := &signature{
+		signedHeaders: strings.Split(fields["SignedHeaders"], ";"),
+		signature:     fields["Signature"],
+	}
+	if err := sig.parseCredential(fields["Credential"]); err != nil {
+		return nil, err
+	}
+	date := r.Header.Get("X-Amz-Date")
+	if date == "" {
+		date = r.Header.Get("Date")
+	}
+	t, err := time.Parse(amzDateFormat, date)
+	if err != nil {
+		if t, err = http.ParseTime(date); err != nil {
+			return nil, ErrAuthorizationHeader
+		}
+	}
+	sig.amzDate = t
+	if d := time.Since(t); d > maxSkew || d < -maxSkew {
+		return nil, ErrRequestTimeTooSkewed
+	}
+	return sig, nil
+}
+
+func parsePresignedSignature(r *http.Request) (*signature, error) {
+	q := r.URL.Query()
+	sig := &signature{
+		signedHeaders: strings.Split(q.Get("X-Amz-SignedHeaders"), ";"),
+		signature:     q.Get("X-Amz-Signature"),
+		presigned:     true,
+	}
+	if err := sig.parseCredential(q.Get("X-Amz-Credential")); err != nil {
+		return nil, err
+	}
+	t, err := time.Parse(amzDateFormat, q.Get("X-Amz-Date"))
+	if err != nil {
+		return nil, ErrAuthorizationHeader
+	}
+	sig.amzDate = t
+	expires, err := strconv.ParseInt(q.Get("X-Amz-Expires"), 10, 64)
+	if err != nil {
+		return nil, ErrAuthorizationHeader
+	}
+	if expires < 0 || expires > maxPresignExpires {
+		return nil, ErrAuthorizationQuery
+	}
+	if time.Now().After(t.Add(time.Duration(expires) * time.Second)) {
+		return nil, ErrExpiredPresignRequest
+	}
+	return sig, nil
+}
+
+func (s *signature) parseCredential(credential string) error {
+	parts := strings.Split(credential, "/")
+	if len(parts) != 5 || parts[4] != "aws4_request" {
+		return ErrAuthorizationHeader
+	}
+	s.accessKey, s.date, s.region, s.service = parts[0], parts[1], parts[2], parts[3]
+	return nil
+}
+
+func computeSignature(r *http.Request, sig *signature, secret string) string {
+	canonicalRequest := strings.Join([]string{
+		r.Method,
+		uriEncode(r.URL.Path, false),
+		canonicalQuery(r.URL.Query(), sig.presigned),
+		canonicalHeaders(r, sig.signedHeaders),
+		strings.Join(sig.signedHeaders, ";"),
+		payloadHash(r, sig.presigned),
+	}, "\n")
+	stringToSign := strings.Join([]string{
+		signV4Algorithm,
+		sig.amzDate.UTC().Format(amzDateFormat),
+		sig.scope(),
+		hexSHA256([]byte(canonicalRequest)),
+	}, "\n")
+	return hex.EncodeToString(hmacSHA256(signingKey(sig, secret), stringToSign))
+}
+
+func (s *signature) scope() string {
+	return strings.Join([]string{s.date, s.region, s.service, "aws4_request"}, "/")
+}
+
+func signingKey(sig *signature, secret string) []byte {
+	key := hmacSHA256([]byte("AWS4"+secret), sig.date)
+	key = hmacSHA256(key, sig.region)
+	key = hmacSHA256(key, sig.service)
+	return hmacSHA256(key, "aws4_request")
+}
+
+func canonicalQuery(query url.Values, presigned bool) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		if presigned && k == "X-Amz-Signature" {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var pairs []string
+	for _, k := range keys {
+		values := query[k]
+		sort.Strings(values)
+		for _, v := range values {
+			pairs = append(pairs, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+func canonicalHeaders(r *http.Request, signedHeaders []string) string {
+	var b strings.Builder
+	for _, h := range signedHeaders {
+		var value string
+		switch h {
+		case "host":
+			value = r.Host
+			if value == "" {
+				value = r.URL.Host
+			}
+		case "content-length":
+			value = strconv.FormatInt(r.ContentLength, 10)
+			if v := r.Header.Get("Content-Length"); v != "" {
+				value = v
+			}
+		default:
+			values := r.Header.Values(h)
+			for i := range values {
+				values[i] = strings.Join(strings.Fields(values[i]), " ")
+			}
+			value = strings.Join(values, ",")
+		}
+		b.WriteString(h + ":" + value + "\n")
+	}
+	return b.String()
+}
+
+func payloadHash(r *http.Request, presigned bool) string {
+	if h := r.Header.Get("X-Amz-Content-Sha256"); h != "" {
+		return h
+	}
+	if presigned {
+		return unsignedPayload
+	}
+	// the empty payload
+	return hexSHA256(nil)
+}
+
+// uriEncode encodes the string as the aws canonical uri, the unreserved characters are kept
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if (ch >= 'A' && ch <= 'Z') || (ch >= 'a' && ch <= 'z') || (ch >= '0' && ch <= '9') ||
+			ch == '-' || ch == '_' || ch == '.' || ch == '~' || (ch == '/' && !encodeSlash) {
+			b.WriteByte(ch)
+		} else {
+			b.WriteString("%" + strings.ToUpper(hex.EncodeToString([]byte{ch})))
+		}
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func hexSHA256(data []byte) string {
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
+}
@@ -0,0 +1,170 @@
+package s3
+
+import (
+	"context"
+	stdpath "path"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/authz"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// Bucket maps a bucket to a path, which is relative to the base path of user
+type Bucket struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+func getBuckets() ([]Bucket, error) {
+	var buckets []Bucket
+	err := utils.Json.UnmarshalFromString(setting.GetStr(conf.S3Buckets, "[]"), &buckets)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed parse setting [%s]", conf.S3Buckets)
+	}
+	return buckets, nil
+}
+
+func getBucket(name string) (*Bucket, error) {
+	buckets, err := getBuckets()
+	if err != nil {
+		return nil, err
+	}
+	for i := range buckets {
+		if buckets[i].Name == name {
+			return &buckets[i], nil
+		}
+	}
+	return nil, ErrNoSuchBucket
+}
+
+func listBuckets(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+	buckets, err := getBuckets()
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	res := listAllMyBucketsResult{
+		Xmlns: xmlns,
+		Owner: owner{ID: user.Username, DisplayName: user.Username},
+	}
+	for _, b := range buckets {
+		// only the buckets which the user can access are listed
+		reqPath, err := user.JoinPath(b.Path)
+		if err != nil {
+			continue
+		}
+		meta, _ := db.GetNearestMeta(reqPath)
+		if !common.CanAccess(user, meta, reqPath, "") {
+			continue
+		}
+		res.Buckets = append(res.Buckets, bucketResp{
+			Name:         b.Name,
+			CreationDate: formatTime(time.Unix(0, 0)),
+		})
+	}
+	writeXML(c, 200, res)
+}
+
+// request is a resolved s3 request of a bucket or an object
+type request struct {
+	c      *gin.Context
+	user   *model.User
+	bucket *Bucket
+	key    string
+	path   string // the full path of key in alist
+}
+
+func newRequest(c *gin.Context, bucketName, key string) (*request, error) {
+	bucket, err := getBucket(bucketName)
+	if err != nil {
+		return nil, err
+	}
+	r := &request{
+		c:      c,
+		user:   c.MustGet("user").(*model.User),
+		bucket: bucket,
+		key:    key,
+	}
+	r.path, err = r.objPath(key)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// objPath returns the full path of the key in alist
+func (r *request) objPath(key string) (string, error) {
+	return keyPath(r.user, r.bucket, key)
+}
+
+// keyPath returns the full path of the key of bucket in alist,
+// the keys with .. are rejected so that they can't escape the path of bucket
+func keyPath(user *model.User, bucket *Bucket, key string) (string, error) {
+	for _, s := range strings.Split(key, "/") {
+		if s == ".." {
+			return "", ErrInvalidArgument
+		}
+	}
+	bucketPath := stdpath.Join("/", bucket.Path)
+	p := stdpath.Join(bucketPath, key)
+	if p != bucketPath && !strings.HasPrefix(p, strings.TrimSuffix(bucketPath, "/")+"/") {
+		return "", ErrAccessDenied
+	}
+	p, err := user.JoinPath(p)
+	if err != nil {
+		return "", ErrAccessDenied
+	}
+	return p, nil
+}
+
+// ctx returns the context with the user and the meta of path, which is required by fs.List
+func (r *request) ctx(path string) (context.Context, *model.Meta) {
+	meta, _ := db.GetNearestMeta(path)
+	ctx := context.WithValue(r.c.Request.Context(), "user", r.user)
+	ctx = context.WithValue(ctx, "ip", r.c.ClientIP())
+	ctx = context.WithValue(ctx, "via", "s3")
+	return context.WithValue(ctx, "meta", meta), meta
+}
+
+func (r *request) checkRead(path string) error {
+	_, meta := r.ctx(path)
+	if !common.CanAccess(r.user, meta, path, "") {
+		return ErrAccessDenied
+	}
+	return nil
+}
+
+func (r *request) checkWrite(path string) error {
+	if !authz.Can(r.user, model.ActionWrite, path) {
+		return ErrAccessDenied
+	}
+	return nil
+}
+
+func (r *request) checkRemove(path string) error {
+	if !authz.Can(r.user, model.ActionRemove, path) {
+		return ErrAccessDenied
+	}
+	return nil
+}
+
+func headBucket(r *request) {
+	if err := r.checkRead(r.path); err != nil {
+		writeError(r.c, err)
+		return
+	}
+	r.c.Status(200)
+}
+
+func getBucketLocation(r *request) {
+	writeXML(r.c, 200, locationConstraint{Xmlns: xmlns})
+}
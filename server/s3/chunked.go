@@ -0,0 +1,160 @@
+package s3
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// chunkSigner computes the signatures of the chunks of aws-chunked body,
+// every chunk is signed with the signature of the previous one, the first with the request's.
+type chunkSigner struct {
+	key      []byte
+	amzDate  string
+	scope    string
+	previous string
+}
+
+func (s *chunkSigner) sign(chunkHash []byte) string {
+	stringToSign := strings.Join([]string{
+		signV4Algorithm + "-PAYLOAD",
+		s.amzDate,
+		s.scope,
+		s.previous,
+		hexSHA256(nil),
+		hex.EncodeToString(chunkHash),
+	}, "\n")
+	s.previous = hex.EncodeToString(hmacSHA256(s.key, stringToSign))
+	return s.previous
+}
+
+// chunkedReader decodes the aws-chunked body like
+// <hex size>;chunk-signature=<signature>\r\n<data>\r\n ... 0;chunk-signature=<signature>\r\n\r\n
+// the chunk signatures are verified if signer is not nil, a chunk is checked after its data is read.
+type chunkedReader struct {
+	r         *bufio.Reader
+	left      int64 // the left bytes of current chunk
+	done      bool
+	signer    *chunkSigner
+	hash      hash.Hash
+	signature string // the signature of current chunk
+}
+
+func newChunkedReader(r io.Reader, signer *chunkSigner) io.Reader {
+	return &chunkedReader{r: bufio.NewReader(r), signer: signer, hash: sha256.New()}
+}
+
+func (cr *chunkedReader) Read(p []byte) (int, error) {
+	if cr.done {
+		return 0, io.EOF
+	}
+	if cr.left == 0 {
+		if err := cr.nextChunk(); err != nil {
+			return 0, err
+		}
+		if cr.done {
+			return 0, io.EOF
+		}
+	}
+	if int64(len(p)) > cr.left {
+		p = p[:cr.left]
+	}
+	n, err := cr.r.Read(p)
+	cr.left -= int64(n)
+	cr.hash.Write(p[:n])
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err == nil && cr.left == 0 {
+		// the bytes of a chunk mismatched are withheld as hashReader does
+		if err := cr.verify(); err != nil {
+			return 0, err
+		}
+		err = cr.readCRLF()
+	}
+	return n, err
+}
+
+func (cr *chunkedReader) nextChunk() error {
+	line, err := cr.r.ReadString('\n')
+	if err != nil {
+		return errors.Wrap(io.ErrUnexpectedEOF, "failed read chunk header")
+	}
+	line = strings.TrimRight(line, "\r\n")
+	size, ext, _ := strings.Cut(line, ";")
+	cr.left, err = strconv.ParseInt(strings.TrimSpace(size), 16, 64)
+	if err != nil || cr.left < 0 {
+		return errors.Errorf("invalid chunk size: %s", size)
+	}
+	cr.signature = strings.TrimPrefix(ext, "chunk-signature=")
+	cr.hash.Reset()
+	if cr.left == 0 {
+		// the last chunk without data is signed too
+		if err := cr.verify(); err != nil {
+			return err
+		}
+		cr.done = true
+	}
+	return nil
+}
+
+func (cr *chunkedReader) verify() error {
+	if cr.signer == nil {
+		return nil
+	}
+	expected := cr.signer.sign(cr.hash.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(cr.signature)) {
+		return ErrSignatureDoesNotMatch
+	}
+	return nil
+}
+
+func (cr *chunkedReader) readCRLF() error {
+	var buf [2]byte
+	if _, err := io.ReadFull(cr.r, buf[:]); err != nil || buf != [2]byte{'\r', '\n'} {
+		return errors.New("malformed chunk: missing CRLF after data")
+	}
+	return nil
+}
+
+// hashReader checks the sha256 of body with the signed one, when the content length
+// is read or the body is read to the end. The bytes of the last read are withheld if
+// they mismatch, so that the readers reading exactly the size get the error too.
+type hashReader struct {
+	r        io.Reader
+	hash     hash.Hash
+	expected string
+	size     int64 // -1 if unknown
+	read     int64
+	checked  bool
+}
+
+func newHashReader(r io.Reader, expected string, size int64) io.Reader {
+	return &hashReader{r: r, hash: sha256.New(), expected: strings.ToLower(expected), size: size}
+}
+
+func (hr *hashReader) Read(p []byte) (int, error) {
+	if hr.checked {
+		return 0, io.EOF
+	}
+	n, err := hr.r.Read(p)
+	hr.hash.Write(p[:n])
+	hr.read += int64(n)
+	if err == io.EOF || hr.size >= 0 && hr.read >= hr.size {
+		hr.checked = true
+		if hex.EncodeToString(hr.hash.Sum(nil)) != hr.expected {
+			return 0, ErrContentSHA256Mismatch
+		}
+		if err == nil {
+			err = io.EOF
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,189 @@
+package s3
+
+import (
+	"context"
+	stdpath "path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/authz"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+)
+
+const maxKeys = 1000
+
+// entry is an object or a common prefix in the listing
+type entry struct {
+	key   string
+	obj   model.Obj
+	isDir bool // a common prefix
+}
+
+// listObjects serves both ListObjects and ListObjectsV2
+func listObjects(r *request) {
+	if err := r.checkRead(r.path); err != nil {
+		writeError(r.c, err)
+		return
+	}
+	q := r.c.Request.URL.Query()
+	v2 := q.Get("list-type") == "2"
+	prefix := q.Get("prefix")
+	delimiter := q.Get("delimiter")
+	max := maxKeys
+	if s := q.Get("max-keys"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			writeError(r.c, ErrInvalidArgument)
+			return
+		}
+		if n < max {
+			max = n
+		}
+	}
+	marker := q.Get("marker")
+	if v2 {
+		marker = q.Get("start-after")
+		if token := q.Get("continuation-token"); token != "" {
+			marker = token
+		}
+	}
+	entries, err := r.listEntries(prefix, delimiter)
+	if err != nil {
+		writeError(r.c, err)
+		return
+	}
+	encode := func(s string) string { return s }
+	if q.Get("encoding-type") == "url" {
+		encode = func(s string) string { return uriEncode(s, false) }
+	}
+	res := listBucketResult{
+		Xmlns:        xmlns,
+		Name:         r.bucket.Name,
+		Prefix:       encode(prefix),
+		Delimiter:    encode(delimiter),
+		MaxKeys:      max,
+		EncodingType: q.Get("encoding-type"),
+	}
+	var last string
+	count := 0
+	for _, e := range entries {
+		if e.key <= marker {
+			continue
+		}
+		if count >= max {
+			res.IsTruncated = true
+			break
+		}
+		count++
+		last = e.key
+		if e.isDir {
+			res.CommonPrefixes = append(res.CommonPrefixes, commonPrefix{Prefix: encode(e.key)})
+			continue
+		}
+		res.Contents = append(res.Contents, object{
+			Key:          encode(e.key),
+			LastModified: formatTime(e.obj.ModTime()),
+			ETag:         etag(e.obj, stdpath.Join(r.path, e.key)),
+			Size:         e.obj.GetSize(),
+			StorageClass: "STANDARD",
+		})
+	}
+	if v2 {
+		res.KeyCount = count
+		res.StartAfter = encode(q.Get("start-after"))
+		res.ContinuationToken = q.Get("continuation-token")
+		if res.IsTruncated {
+			res.NextContinuationToken = last
+		}
+	} else {
+		res.Marker = encode(q.Get("marker"))
+		if res.IsTruncated && delimiter != "" {
+			res.NextMarker = encode(last)
+		}
+	}
+	writeXML(r.c, 200, res)
+}
+
+// listEntries returns the sorted entries under the prefix, with the delimiter "/"
+// only the dir of prefix is listed, otherwise the whole bucket under it is walked.
+func (r *request) listEntries(prefix, delimiter string) ([]entry, error) {
+	var entries []entry
+	if delimiter == "/" {
+		// the dir part of prefix, e.g. "a/b/c" -> "a/b/"
+		dir := prefix[:strings.LastIndex(prefix, "/")+1]
+		objs, err := r.list(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objs {
+			key := dir + obj.GetName()
+			if !strings.HasPrefix(key, prefix) {
+				continue
+			}
+			if obj.IsDir() {
+				entries = append(entries, entry{key: key + "/", isDir: true})
+			} else {
+				entries = append(entries, entry{key: key, obj: obj})
+			}
+		}
+	} else {
+		if delimiter != "" {
+			return nil, ErrNotImplemented
+		}
+		if err := r.walk("", prefix, &entries); err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].key < entries[j].key
+	})
+	return entries, nil
+}
+
+func (r *request) walk(dir, prefix string, entries *[]entry) error {
+	objs, err := r.list(dir)
+	if err != nil {
+		return err
+	}
+	for _, obj := range objs {
+		key := dir + obj.GetName()
+		if obj.IsDir() {
+			// only walk into the dirs that may contain the prefix
+			if strings.HasPrefix(key+"/", prefix) || strings.HasPrefix(prefix, key+"/") {
+				if err := r.walk(key+"/", prefix, entries); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if strings.HasPrefix(key, prefix) {
+			*entries = append(*entries, entry{key: key, obj: obj})
+		}
+	}
+	return nil
+}
+
+// list returns the objs of dir in bucket, a nonexistent dir is treated as empty
+func (r *request) list(dir string) ([]model.Obj, error) {
+	path := stdpath.Join(r.path, dir)
+	if err := r.checkRead(path); err != nil || !authz.Can(r.user, model.ActionList, path) {
+		return nil, nil
+	}
+	ctx, _ := r.ctx(path)
+	objs, err := fs.List(ctx, path)
+	if err != nil {
+		if errs.IsObjectNotFound(err) || isNotFolder(ctx, path) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return objs, nil
+}
+
+func isNotFolder(ctx context.Context, path string) bool {
+	obj, err := fs.Get(ctx, path)
+	return err != nil || !obj.IsDir()
+}
@@ -0,0 +1,292 @@
+package s3
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// the parts of multipart uploads are saved in the temp dir until completed or aborted:
+// <temp_dir>/s3/<upload id>/upload.json and <part number>.part with <part number>.md5.
+// The uploads without new parts in uploadExpiry are removed.
+
+const uploadExpiry = 7 * 24 * time.Hour
+
+type upload struct {
+	Bucket string `json:"bucket"`
+	Key    string `json:"key"`
+	User   string `json:"user"`
+}
+
+func uploadDir(id string) string {
+	return filepath.Join(conf.Conf.TempDir, "s3", id)
+}
+
+// cleanExpiredUploads removes the dirs of the uploads which are not modified since uploadExpiry before now
+func cleanExpiredUploads(now time.Time) {
+	root := filepath.Join(conf.Conf.TempDir, "s3")
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Warnf("failed read s3 upload dirs: %+v", err)
+		}
+		return
+	}
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil || now.Sub(info.ModTime()) < uploadExpiry {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(root, e.Name())); err != nil {
+			log.Warnf("failed remove expired s3 upload %s: %+v", e.Name(), err)
+		}
+	}
+}
+
+// getUpload returns the dir of upload, it must be created by the same user for the same key
+func (r *request) getUpload() (string, error) {
+	id := r.c.Query("uploadId")
+	if _, err := uuid.Parse(id); err != nil {
+		return "", ErrNoSuchUpload
+	}
+	dir := uploadDir(id)
+	data, err := os.ReadFile(filepath.Join(dir, "upload.json"))
+	if err != nil {
+		return "", ErrNoSuchUpload
+	}
+	var u upload
+	if err := utils.Json.Unmarshal(data, &u); err != nil {
+		return "", errors.WithStack(err)
+	}
+	if u.Bucket != r.bucket.Name || u.Key != r.key || u.User != r.user.Username {
+		return "", ErrNoSuchUpload
+	}
+	return dir, nil
+}
+
+func createMultipartUpload(r *request) {
+	if err := r.checkWrite(r.path); err != nil {
+		writeError(r.c, err)
+		return
+	}
+	id := uuid.NewString()
+	dir := uploadDir(id)
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		writeError(r.c, errors.WithStack(err))
+		return
+	}
+	data, _ := utils.Json.Marshal(upload{Bucket: r.bucket.Name, Key: r.key, User: r.user.Username})
+	if err := os.WriteFile(filepath.Join(dir, "upload.json"), data, 0666); err != nil {
+		writeError(r.c, errors.WithStack(err))
+		return
+	}
+	writeXML(r.c, 200, initiateMultipartUploadResult{
+		Xmlns:    xmlns,
+		Bucket:   r.bucket.Name,
+		Key:      r.key,
+		UploadID: id,
+	})
+}
+
+func uploadPart(r *request) {
+	dir, err := r.getUpload()
+	if err != nil {
+		writeError(r.c, err)
+		return
+	}
+	n, err := strconv.Atoi(r.c.Query("partNumber"))
+	if err != nil || n < 1 || n > 10000 {
+		writeError(r.c, ErrInvalidArgument)
+		return
+	}
+	body, _, err := requestBody(r.c.Request)
+	if err != nil {
+		writeError(r.c, err)
+		return
+	}
+	part := filepath.Join(dir, fmt.Sprintf("%d.part", n))
+	f, err := os.Create(part)
+	if err != nil {
+		writeError(r.c, errors.WithStack(err))
+		return
+	}
+	defer f.Close()
+	h := md5.New()
+	if _, err := io.Copy(io.MultiWriter(f, h), body); err != nil {
+		// the part is not kept if the body doesn't match the signature
+		_ = f.Close()
+		_ = os.Remove(part)
+		writeError(r.c, errors.WithStack(err))
+		return
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+	if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("%d.md5", n)), []byte(sum), 0666); err != nil {
+		writeError(r.c, errors.WithStack(err))
+		return
+	}
+	r.c.Header("ETag", `"`+sum+`"`)
+	r.c.Status(200)
+}
+
+// getParts returns the uploaded parts sorted by part number
+func getParts(dir string) ([]partResp, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var parts []partResp
+	for _, f := range files {
+		name := strings.TrimSuffix(f.Name(), ".part")
+		n, err := strconv.Atoi(name)
+		if err != nil || name == f.Name() {
+			continue
+		}
+		info, err := f.Info()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		sum, err := os.ReadFile(filepath.Join(dir, name+".md5"))
+		if err != nil {
+			// the part is still uploading
+			continue
+		}
+		parts = append(parts, partResp{
+			PartNumber:   n,
+			LastModified: formatTime(info.ModTime()),
+			ETag:         `"` + string(sum) + `"`,
+			Size:         info.Size(),
+		})
+	}
+	sort.Slice(parts, func(i, j int) bool {
+		return parts[i].PartNumber < parts[j].PartNumber
+	})
+	return parts, nil
+}
+
+func listParts(r *request) {
+	dir, err := r.getUpload()
+	if err != nil {
+		writeError(r.c, err)
+		return
+	}
+	parts, err := getParts(dir)
+	if err != nil {
+		writeError(r.c, err)
+		return
+	}
+	writeXML(r.c, 200, listPartsResult{
+		Xmlns:    xmlns,
+		Bucket:   r.bucket.Name,
+		Key:      r.key,
+		UploadID: r.c.Query("uploadId"),
+		Parts:    parts,
+	})
+}
+
+// completeMultipartUpload concatenates the parts and puts it to the storage,
+// the etag is the md5 of the md5s of parts with the count of parts like s3
+func completeMultipartUpload(r *request) {
+	dir, err := r.getUpload()
+	if err != nil {
+		writeError(r.c, err)
+		return
+	}
+	var req completeMultipartUploadRequest
+	if err := xml.NewDecoder(r.c.Request.Body).Decode(&req); err != nil || len(req.Parts) == 0 {
+		writeError(r.c, ErrMalformedXML)
+		return
+	}
+	uploaded, err := getParts(dir)
+	if err != nil {
+		writeError(r.c, err)
+		return
+	}
+	partMap := make(map[int]partResp, len(uploaded))
+	for _, p := range uploaded {
+		partMap[p.PartNumber] = p
+	}
+	var (
+		readers []io.Reader
+		size    int64
+		md5s    []byte
+	)
+	defer func() {
+		for _, rd := range readers {
+			_ = rd.(io.Closer).Close()
+		}
+	}()
+	for i, p := range req.Parts {
+		if i > 0 && p.PartNumber <= req.Parts[i-1].PartNumber {
+			writeError(r.c, ErrInvalidPartOrder)
+			return
+		}
+		uploadedPart, ok := partMap[p.PartNumber]
+		if !ok || strings.Trim(p.ETag, `"`) != strings.Trim(uploadedPart.ETag, `"`) {
+			writeError(r.c, ErrInvalidPart)
+			return
+		}
+		sum, _ := hex.DecodeString(strings.Trim(uploadedPart.ETag, `"`))
+		md5s = append(md5s, sum...)
+		f, err := os.Open(filepath.Join(dir, fmt.Sprintf("%d.part", p.PartNumber)))
+		if err != nil {
+			writeError(r.c, errors.WithStack(err))
+			return
+		}
+		readers = append(readers, f)
+		size += uploadedPart.Size
+	}
+	if _, err := r.put(r.path, io.NopCloser(io.MultiReader(readers...)), size); err != nil {
+		writeError(r.c, err)
+		return
+	}
+	// the parts are closed before removing them
+	for _, rd := range readers {
+		_ = rd.(io.Closer).Close()
+	}
+	readers = nil
+	if err := os.RemoveAll(dir); err != nil {
+		log.Warnf("failed remove s3 upload dir %s: %+v", dir, err)
+	}
+	writeXML(r.c, 200, completeMultipartUploadResult{
+		Xmlns:  xmlns,
+		Bucket: r.bucket.Name,
+		Key:    r.key,
+		ETag:   fmt.Sprintf(`"%s-%d"`, utils.GetMD5Encode(string(md5s)), len(req.Parts)),
+	})
+}
+
+func abortMultipartUpload(r *request) {
+	dir, err := r.getUpload()
+	if err != nil {
+		writeError(r.c, err)
+		return
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		writeError(r.c, errors.WithStack(err))
+		return
+	}
+	r.c.Status(204)
+}
+
+// listMultipartUploads lists nothing, the in-progress uploads are only known by their ids
+func listMultipartUploads(r *request) {
+	writeXML(r.c, 200, struct {
+		XMLName xml.Name `xml:"ListMultipartUploadsResult"`
+		Xmlns   string   `xml:"xmlns,attr"`
+		Bucket  string   `xml:"Bucket"`
+	}{Xmlns: xmlns, Bucket: r.bucket.Name})
+}
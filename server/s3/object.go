@@ -0,0 +1,259 @@
+package s3
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	stdpath "path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/authz"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/pkg/errors"
+)
+
+// etag returns the md5 of the obj if it's known, otherwise a pseudo etag by its path, size and modified time
+func etag(obj model.Obj, path string) string {
+	if h := model.GetHash(obj).GetHash(utils.MD5); h != "" {
+		return `"` + strings.ToLower(h) + `"`
+	}
+	return `"` + utils.GetMD5Encode(fmt.Sprintf("%s-%d-%d", path, obj.GetSize(), obj.ModTime().UnixNano())) + `"`
+}
+
+func (r *request) getFile() (model.Obj, error) {
+	if err := r.checkRead(r.path); err != nil {
+		return nil, err
+	}
+	ctx, _ := r.ctx(r.path)
+	obj, err := fs.Get(ctx, r.path)
+	if err != nil {
+		return nil, err
+	}
+	if obj.IsDir() {
+		return nil, ErrNoSuchKey
+	}
+	if !authz.Can(r.user, model.ActionRead, r.path) {
+		return nil, ErrAccessDenied
+	}
+	return obj, nil
+}
+
+func setObjectHeaders(w http.ResponseWriter, obj model.Obj, path string) {
+	w.Header().Set("ETag", etag(obj, path))
+	w.Header().Set("Last-Modified", obj.ModTime().UTC().Format(http.TimeFormat))
+	w.Header().Set("Content-Type", utils.GetMimeType(obj.GetName()))
+	w.Header().Set("Accept-Ranges", "bytes")
+}
+
+func headObject(r *request) {
+	obj, err := r.getFile()
+	if err != nil {
+		writeError(r.c, err)
+		return
+	}
+	setObjectHeaders(r.c.Writer, obj, r.path)
+	r.c.Header("Content-Length", strconv.FormatInt(obj.GetSize(), 10))
+	r.c.Status(200)
+}
+
+// getObject proxies the content of file, the Range header is handled by common.Proxy
+func getObject(r *request) {
+	obj, err := r.getFile()
+	if err != nil {
+		writeError(r.c, err)
+		return
+	}
+	ctx, _ := r.ctx(r.path)
+	link, _, err := fs.Link(ctx, r.path, model.LinkArgs{
+		IP:     r.c.ClientIP(),
+		Header: r.c.Request.Header,
+	})
+	if err != nil {
+		writeError(r.c, err)
+		return
+	}
+	setObjectHeaders(r.c.Writer, obj, r.path)
+	if err := common.Proxy(r.c.Writer, r.c.Request, link, obj); err != nil {
+		writeError(r.c, err)
+	}
+}
+
+// requestBody returns the body of request with its decoded size, the aws-chunked body is decoded by Auth
+func requestBody(req *http.Request) (io.ReadCloser, int64, error) {
+	size := req.ContentLength
+	if strings.HasPrefix(req.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		decoded, err := strconv.ParseInt(req.Header.Get("X-Amz-Decoded-Content-Length"), 10, 64)
+		if err != nil {
+			return nil, 0, ErrMissingContentLength
+		}
+		size = decoded
+	}
+	if size < 0 {
+		return nil, 0, ErrMissingContentLength
+	}
+	return req.Body, size, nil
+}
+
+// put uploads the content to the path, it returns the md5 of the content
+func (r *request) put(path string, rc io.ReadCloser, size int64) (string, error) {
+	h := md5.New()
+	dir, name := stdpath.Split(path)
+	stream := &model.FileStream{
+		Obj: &model.Object{
+			Name:     name,
+			Size:     size,
+			Modified: time.Now(),
+		},
+		ReadCloser: utils.ReadCloser{Reader: io.TeeReader(rc, h), Closer: rc},
+		Mimetype:   utils.GetMimeType(name),
+	}
+	ctx, _ := r.ctx(dir)
+	if err := fs.PutDirectly(ctx, dir, stream); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func putObject(r *request) {
+	if err := r.checkWrite(r.path); err != nil {
+		writeError(r.c, err)
+		return
+	}
+	body, size, err := requestBody(r.c.Request)
+	if err != nil {
+		writeError(r.c, err)
+		return
+	}
+	// the key ended with slash is a dir
+	if strings.HasSuffix(r.key, "/") && size == 0 {
+		ctx, _ := r.ctx(r.path)
+		if err := fs.MakeDir(ctx, r.path); err != nil {
+			writeError(r.c, err)
+			return
+		}
+		r.c.Header("ETag", `"`+utils.GetMD5Encode("")+`"`)
+		r.c.Status(200)
+		return
+	}
+	sum, err := r.put(r.path, body, size)
+	if err != nil {
+		writeError(r.c, err)
+		return
+	}
+	r.c.Header("ETag", `"`+sum+`"`)
+	r.c.Status(200)
+}
+
+// copySourcePath parses the header X-Amz-Copy-Source like /bucket/key?versionId=xxx
+func (r *request) copySourcePath() (string, error) {
+	src, err := url.PathUnescape(strings.SplitN(r.c.GetHeader("X-Amz-Copy-Source"), "?", 2)[0])
+	if err != nil {
+		return "", ErrInvalidArgument
+	}
+	bucketName, key, _ := strings.Cut(strings.TrimPrefix(src, "/"), "/")
+	bucket, err := getBucket(bucketName)
+	if err != nil {
+		return "", err
+	}
+	return keyPath(r.user, bucket, key)
+}
+
+// copyObject copies the src object by stream, so it works between storages and with a new name
+func copyObject(r *request) {
+	srcPath, err := r.copySourcePath()
+	if err != nil {
+		writeError(r.c, err)
+		return
+	}
+	if err := r.checkRead(srcPath); err != nil || !authz.Can(r.user, model.ActionRead, srcPath) {
+		writeError(r.c, ErrAccessDenied)
+		return
+	}
+	if err := r.checkWrite(r.path); err != nil {
+		writeError(r.c, err)
+		return
+	}
+	ctx, _ := r.ctx(srcPath)
+	stream, err := fs.GetStream(ctx, srcPath)
+	if err != nil {
+		writeError(r.c, err)
+		return
+	}
+	if stream.IsDir() {
+		_ = stream.Close()
+		writeError(r.c, ErrNoSuchKey)
+		return
+	}
+	sum, err := r.put(r.path, stream, stream.GetSize())
+	if err != nil {
+		writeError(r.c, err)
+		return
+	}
+	writeXML(r.c, 200, copyObjectResult{
+		Xmlns:        xmlns,
+		LastModified: formatTime(time.Now()),
+		ETag:         `"` + sum + `"`,
+	})
+}
+
+func (r *request) remove(path string) error {
+	ctx, _ := r.ctx(path)
+	_, err := fs.Get(ctx, path)
+	if err != nil {
+		// deleting a nonexistent object is not an error in s3
+		return nil
+	}
+	return fs.Remove(ctx, path)
+}
+
+func deleteObject(r *request) {
+	if err := r.checkRemove(r.path); err != nil {
+		writeError(r.c, err)
+		return
+	}
+	if err := r.remove(r.path); err != nil {
+		writeError(r.c, err)
+		return
+	}
+	r.c.Status(http.StatusNoContent)
+}
+
+func deleteObjects(r *request) {
+	var req deleteRequest
+	if err := xml.NewDecoder(r.c.Request.Body).Decode(&req); err != nil {
+		writeError(r.c, ErrMalformedXML)
+		return
+	}
+	res := deleteResult{Xmlns: xmlns}
+	for _, o := range req.Objects {
+		path, err := r.objPath(o.Key)
+		if err == nil {
+			err = r.checkRemove(path)
+		}
+		if err == nil {
+			err = r.remove(path)
+		}
+		if err != nil {
+			var apiErr APIError
+			if !errors.As(err, &apiErr) {
+				apiErr = ErrInternalError
+				apiErr.Message = err.Error()
+			}
+			res.Errors = append(res.Errors, deleteError{Key: o.Key, Code: apiErr.Code, Message: apiErr.Message})
+			continue
+		}
+		if !req.Quiet {
+			res.Deleted = append(res.Deleted, deletedObject{Key: o.Key})
+		}
+	}
+	writeXML(r.c, 200, res)
+}
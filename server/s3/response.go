@@ -0,0 +1,226 @@
+package s3
+
+import (
+	"encoding/xml"
+	"net/http"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const xmlns = "http://s3.amazonaws.com/doc/2006-03-01/"
+
+// timeFormat is the format of time in the xml responses
+const timeFormat = "2006-01-02T15:04:05.000Z"
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeFormat)
+}
+
+// APIError is an error of s3 api with its code and http status
+type APIError struct {
+	Code    string
+	Message string
+	Status  int
+}
+
+func (e APIError) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+var (
+	ErrAccessDenied          = APIError{"AccessDenied", "Access Denied", http.StatusForbidden}
+	ErrInvalidAccessKeyID    = APIError{"InvalidAccessKeyId", "The access key Id you provided does not exist in our records.", http.StatusForbidden}
+	ErrSignatureDoesNotMatch = APIError{"SignatureDoesNotMatch", "The request signature we calculated does not match the signature you provided.", http.StatusForbidden}
+	ErrRequestTimeTooSkewed  = APIError{"RequestTimeTooSkewed", "The difference between the request time and the server's time is too large.", http.StatusForbidden}
+	ErrExpiredPresignRequest = APIError{"AccessDenied", "Request has expired", http.StatusForbidden}
+	ErrAuthorizationHeader   = APIError{"AuthorizationHeaderMalformed", "The authorization header is malformed.", http.StatusBadRequest}
+	ErrAuthorizationQuery    = APIError{"AuthorizationQueryParametersError", "X-Amz-Expires must be less than a week (in seconds) that is 604800.", http.StatusBadRequest}
+	ErrContentSHA256Mismatch = APIError{"XAmzContentSHA256Mismatch", "The provided 'x-amz-content-sha256' header does not match what was computed.", http.StatusBadRequest}
+	ErrNoSuchBucket          = APIError{"NoSuchBucket", "The specified bucket does not exist", http.StatusNotFound}
+	ErrNoSuchKey             = APIError{"NoSuchKey", "The specified key does not exist.", http.StatusNotFound}
+	ErrNoSuchUpload          = APIError{"NoSuchUpload", "The specified multipart upload does not exist.", http.StatusNotFound}
+	ErrInvalidArgument       = APIError{"InvalidArgument", "Invalid Argument", http.StatusBadRequest}
+	ErrInvalidPart           = APIError{"InvalidPart", "One or more of the specified parts could not be found.", http.StatusBadRequest}
+	ErrInvalidPartOrder      = APIError{"InvalidPartOrder", "The list of parts was not in ascending order.", http.StatusBadRequest}
+	ErrMalformedXML          = APIError{"MalformedXML", "The XML you provided was not well-formed.", http.StatusBadRequest}
+	ErrMissingContentLength  = APIError{"MissingContentLength", "You must provide the Content-Length HTTP header.", http.StatusLengthRequired}
+	ErrNotImplemented        = APIError{"NotImplemented", "A header you provided implies functionality that is not implemented.", http.StatusNotImplemented}
+	ErrInternalError         = APIError{"InternalError", "We encountered an internal error. Please try again.", http.StatusInternalServerError}
+)
+
+type errorResponse struct {
+	XMLName  xml.Name `xml:"Error"`
+	Code     string   `xml:"Code"`
+	Message  string   `xml:"Message"`
+	Resource string   `xml:"Resource"`
+}
+
+// writeError writes the error as a s3 error response,
+// the errors which are not APIError are treated as internal errors.
+func writeError(c *gin.Context, err error) {
+	var apiErr APIError
+	switch {
+	case errors.As(err, &apiErr):
+	case errs.IsObjectNotFound(err):
+		apiErr = ErrNoSuchKey
+	case errors.Is(errors.Cause(err), errs.PermissionDenied):
+		apiErr = ErrAccessDenied
+	default:
+		log.Errorf("s3 %s %s: %+v", c.Request.Method, c.Request.URL.Path, err)
+		apiErr = ErrInternalError
+		apiErr.Message = err.Error()
+	}
+	if c.Request.Method == http.MethodHead {
+		c.Status(apiErr.Status)
+		return
+	}
+	writeXML(c, apiErr.Status, errorResponse{
+		Code:     apiErr.Code,
+		Message:  apiErr.Message,
+		Resource: c.Request.URL.Path,
+	})
+}
+
+func writeXML(c *gin.Context, status int, v interface{}) {
+	data, err := xml.Marshal(v)
+	if err != nil {
+		log.Errorf("failed marshal s3 response: %+v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	c.Data(status, "application/xml", append([]byte(xml.Header), data...))
+}
+
+type bucketResp struct {
+	Name         string `xml:"Name"`
+	CreationDate string `xml:"CreationDate"`
+}
+
+type owner struct {
+	ID          string `xml:"ID"`
+	DisplayName string `xml:"DisplayName"`
+}
+
+type listAllMyBucketsResult struct {
+	XMLName xml.Name     `xml:"ListAllMyBucketsResult"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	Owner   owner        `xml:"Owner"`
+	Buckets []bucketResp `xml:"Buckets>Bucket"`
+}
+
+type object struct {
+	Key          string `xml:"Key"`
+	LastModified string `xml:"LastModified"`
+	ETag         string `xml:"ETag"`
+	Size         int64  `xml:"Size"`
+	StorageClass string `xml:"StorageClass"`
+}
+
+type commonPrefix struct {
+	Prefix string `xml:"Prefix"`
+}
+
+type listBucketResult struct {
+	XMLName        xml.Name       `xml:"ListBucketResult"`
+	Xmlns          string         `xml:"xmlns,attr"`
+	Name           string         `xml:"Name"`
+	Prefix         string         `xml:"Prefix"`
+	Delimiter      string         `xml:"Delimiter,omitempty"`
+	MaxKeys        int            `xml:"MaxKeys"`
+	EncodingType   string         `xml:"EncodingType,omitempty"`
+	IsTruncated    bool           `xml:"IsTruncated"`
+	Contents       []object       `xml:"Contents"`
+	CommonPrefixes []commonPrefix `xml:"CommonPrefixes"`
+	// v1
+	Marker     string `xml:"Marker,omitempty"`
+	NextMarker string `xml:"NextMarker,omitempty"`
+	// v2
+	KeyCount              int    `xml:"KeyCount,omitempty"`
+	StartAfter            string `xml:"StartAfter,omitempty"`
+	ContinuationToken     string `xml:"ContinuationToken,omitempty"`
+	NextContinuationToken string `xml:"NextContinuationToken,omitempty"`
+}
+
+type locationConstraint struct {
+	XMLName xml.Name `xml:"LocationConstraint"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	Value   string   `xml:",chardata"`
+}
+
+type copyObjectResult struct {
+	XMLName      xml.Name `xml:"CopyObjectResult"`
+	Xmlns        string   `xml:"xmlns,attr"`
+	LastModified string   `xml:"LastModified"`
+	ETag         string   `xml:"ETag"`
+}
+
+type deleteRequest struct {
+	XMLName xml.Name `xml:"Delete"`
+	Quiet   bool     `xml:"Quiet"`
+	Objects []struct {
+		Key string `xml:"Key"`
+	} `xml:"Object"`
+}
+
+type deletedObject struct {
+	Key string `xml:"Key"`
+}
+
+type deleteError struct {
+	Key     string `xml:"Key"`
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+type deleteResult struct {
+	XMLName xml.Name        `xml:"DeleteResult"`
+	Xmlns   string          `xml:"xmlns,attr"`
+	Deleted []deletedObject `xml:"Deleted"`
+	Errors  []deleteError   `xml:"Error"`
+}
+
+type initiateMultipartUploadResult struct {
+	XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Bucket   string   `xml:"Bucket"`
+	Key      string   `xml:"Key"`
+	UploadID string   `xml:"UploadId"`
+}
+
+type completePart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+type completeMultipartUploadRequest struct {
+	XMLName xml.Name       `xml:"CompleteMultipartUpload"`
+	Parts   []completePart `xml:"Part"`
+}
+
+type completeMultipartUploadResult struct {
+	XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	Bucket  string   `xml:"Bucket"`
+	Key     string   `xml:"Key"`
+	ETag    string   `xml:"ETag"`
+}
+
+type partResp struct {
+	PartNumber   int    `xml:"PartNumber"`
+	LastModified string `xml:"LastModified"`
+	ETag         string `xml:"ETag"`
+	Size         int64  `xml:"Size"`
+}
+
+type listPartsResult struct {
+	XMLName  xml.Name   `xml:"ListPartsResult"`
+	Xmlns    string     `xml:"xmlns,attr"`
+	Bucket   string     `xml:"Bucket"`
+	Key      string     `xml:"Key"`
+	UploadID string     `xml:"UploadId"`
+	Parts    []partResp `xml:"Part"`
+}
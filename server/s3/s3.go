@@ -0,0 +1,87 @@
+// Package s3 serves a s3 compatible api over the mounted tree,
+// every bucket is mapped to a path and the keys are the paths relative to it.
+package s3
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/server/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+func Init(r *gin.Engine) {
+	r.Use(middlewares.StoragesLoaded)
+	r.Use(Auth)
+	r.Any("/*path", Serve)
+	go func() {
+		for ; ; time.Sleep(time.Hour) {
+			cleanExpiredUploads(time.Now())
+		}
+	}()
+}
+
+// Serve dispatches the request to the handler of s3 operation by method and query
+func Serve(c *gin.Context) {
+	bucketName, key, _ := strings.Cut(strings.TrimPrefix(c.Request.URL.Path, "/"), "/")
+	q := c.Request.URL.Query()
+	method := c.Request.Method
+	if bucketName == "" {
+		if method == http.MethodGet {
+			listBuckets(c)
+		} else {
+			writeError(c, ErrNotImplemented)
+		}
+		return
+	}
+	r, err := newRequest(c, bucketName, key)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	if key == "" {
+		switch {
+		case method == http.MethodGet && q.Has("location"):
+			getBucketLocation(r)
+		case method == http.MethodGet && q.Has("uploads"):
+			listMultipartUploads(r)
+		case method == http.MethodGet:
+			listObjects(r)
+		case method == http.MethodHead:
+			headBucket(r)
+		case method == http.MethodPut:
+			// the bucket is created by setting, treat it as owned
+			headBucket(r)
+		case method == http.MethodPost && q.Has("delete"):
+			deleteObjects(r)
+		default:
+			writeError(c, ErrNotImplemented)
+		}
+		return
+	}
+	switch {
+	case method == http.MethodGet && q.Has("uploadId"):
+		listParts(r)
+	case method == http.MethodGet:
+		getObject(r)
+	case method == http.MethodHead:
+		headObject(r)
+	case method == http.MethodPut && q.Has("uploadId"):
+		uploadPart(r)
+	case method == http.MethodPut && c.GetHeader("X-Amz-Copy-Source") != "":
+		copyObject(r)
+	case method == http.MethodPut:
+		putObject(r)
+	case method == http.MethodPost && q.Has("uploads"):
+		createMultipartUpload(r)
+	case method == http.MethodPost && q.Has("uploadId"):
+		completeMultipartUpload(r)
+	case method == http.MethodDelete && q.Has("uploadId"):
+		abortMultipartUpload(r)
+	case method == http.MethodDelete:
+		deleteObject(r)
+	default:
+		writeError(c, ErrNotImplemented)
+	}
+}
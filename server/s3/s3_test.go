@@ -0,0 +1,168 @@
+package s3
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+)
+
+func TestSignature(t *testing.T) {
+	const secret = "secret"
+	// the s3 client of sdk signs the escaped path as is
+	signer := v4.NewSigner(credentials.NewStaticCredentials("admin", secret, ""), func(s *v4.Signer) {
+		s.DisableURIPathEscaping = true
+	})
+	req, _ := http.NewRequest(http.MethodPut, "http://127.0.0.1:5246/bucket/a%20b/c.txt?x-id=PutObject", nil)
+	body := bytes.NewReader([]byte("hello"))
+	if _, err := signer.Sign(req, body, "s3", "us-east-1", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	sig, err := parseHeaderSignature(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := computeSignature(req, sig, secret); got != sig.signature {
+		t.Errorf("signature mismatch: got %s, want %s", got, sig.signature)
+	}
+	if got := computeSignature(req, sig, "wrong"); got == sig.signature {
+		t.Errorf("signature should mismatch with wrong secret")
+	}
+}
+
+func TestPresignedSignature(t *testing.T) {
+	const secret = "secret"
+	signer := v4.NewSigner(credentials.NewStaticCredentials("admin", secret, ""))
+	req, _ := http.NewRequest(http.MethodGet, "http://127.0.0.1:5246/bucket/dir/file.txt", nil)
+	if _, err := signer.Presign(req, nil, "s3", "us-east-1", time.Hour, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	sig, err := parsePresignedSignature(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := computeSignature(req, sig, secret); got != sig.signature {
+		t.Errorf("signature mismatch: got %s, want %s", got, sig.signature)
+	}
+}
+
+func TestChunkedReader(t *testing.T) {
+	body := "5;chunk-signature=aaa\r\nhello\r\n6;chunk-signature=bbb\r\n world\r\n0;chunk-signature=ccc\r\n\r\n"
+	data, err := io.ReadAll(newChunkedReader(strings.NewReader(body), nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "hello world" {
+		t.Errorf("got %q, want %q", data, "hello world")
+	}
+	_, err = io.ReadAll(newChunkedReader(strings.NewReader("5;chunk-signature=aaa\r\nhel"), nil))
+	if err == nil {
+		t.Errorf("expect error for truncated body")
+	}
+}
+
+func TestChunkSignature(t *testing.T) {
+	// the example of aws-chunked upload in the aws documents
+	sig := &signature{date: "20130524", region: "us-east-1", service: "s3"}
+	newSigner := func() *chunkSigner {
+		return &chunkSigner{
+			key:      signingKey(sig, "wJalrXUtnFEMI/K7MDENG/bPxRfiCYEXAMPLEKEY"),
+			amzDate:  "20130524T000000Z",
+			scope:    sig.scope(),
+			previous: "4f232c4386841ef735655705268965c44a0e4690baa4adea153f7db9fa80a0a9",
+		}
+	}
+	body := "10000;chunk-signature=ad80c730a21e5b8d04586a2213dd63b9a0e99e0e2307b0ade35a65485a288648\r\n" +
+		strings.Repeat("a", 65536) + "\r\n" +
+		"400;chunk-signature=0055627c9e194cb4542bae2aa5492e3c1575bbb81b612b7d234b86a503ef5497\r\n" +
+		strings.Repeat("a", 1024) + "\r\n" +
+		"0;chunk-signature=b6c6ea8a5354eaf15b3cb7646744f4275b71ea724fed81ceb9323e279d449df9\r\n\r\n"
+	data, err := io.ReadAll(newChunkedReader(strings.NewReader(body), newSigner()))
+	if err != nil || len(data) != 65536+1024 {
+		t.Fatalf("failed read signed chunks: %d %+v", len(data), err)
+	}
+	tampered := strings.Replace(body, "aaaa\r\n400", "aaab\r\n400", 1)
+	if _, err := io.ReadAll(newChunkedReader(strings.NewReader(tampered), newSigner())); err != ErrSignatureDoesNotMatch {
+		t.Errorf("expect signature mismatch for tampered chunk, got %+v", err)
+	}
+}
+
+func TestHashReader(t *testing.T) {
+	expected := hexSHA256([]byte("hello"))
+	if data, err := io.ReadAll(newHashReader(strings.NewReader("hello"), expected, 5)); err != nil || string(data) != "hello" {
+		t.Errorf("failed read body: %q %+v", data, err)
+	}
+	// the reader reading exactly the size gets the error too
+	buf := make([]byte, 5)
+	if _, err := io.ReadFull(newHashReader(strings.NewReader("hellx"), expected, 5), buf); err != ErrContentSHA256Mismatch {
+		t.Errorf("expect sha256 mismatch, got %+v", err)
+	}
+}
+
+func TestPresignedExpires(t *testing.T) {
+	signer := v4.NewSigner(credentials.NewStaticCredentials("admin", "secret", ""))
+	req, _ := http.NewRequest(http.MethodGet, "http://127.0.0.1:5246/bucket/file.txt", nil)
+	if _, err := signer.Presign(req, nil, "s3", "us-east-1", 8*24*time.Hour, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parsePresignedSignature(req); err != ErrAuthorizationQuery {
+		t.Errorf("expect error for expires longer than a week, got %+v", err)
+	}
+}
+
+func TestKeyPath(t *testing.T) {
+	user := &model.User{BasePath: "/home"}
+	bucket := &Bucket{Name: "b", Path: "/data"}
+	tests := []struct {
+		key  string
+		want string
+		err  error
+	}{
+		{"a/b.txt", "/home/data/a/b.txt", nil},
+		{"", "/home/data", nil},
+		{"a/./b.txt", "/home/data/a/b.txt", nil},
+		{"../other/secret", "", ErrInvalidArgument},
+		{"a/../../other", "", ErrInvalidArgument},
+		{"a/..", "", ErrInvalidArgument},
+	}
+	for _, tt := range tests {
+		got, err := keyPath(user, bucket, tt.key)
+		if got != tt.want || err != tt.err {
+			t.Errorf("keyPath(%q) = %q, %v, want %q, %v", tt.key, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestCleanExpiredUploads(t *testing.T) {
+	conf.Conf = conf.DefaultConfig()
+	conf.Conf.TempDir = t.TempDir()
+	now := time.Now()
+	for _, id := range []string{"expired", "active"} {
+		if err := os.MkdirAll(uploadDir(id), 0o777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(uploadDir(id), "1.part"), []byte("part"), 0o666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := now.Add(-uploadExpiry - time.Minute)
+	if err := os.Chtimes(uploadDir("expired"), old, old); err != nil {
+		t.Fatal(err)
+	}
+	cleanExpiredUploads(now)
+	if _, err := os.Stat(uploadDir("expired")); !os.IsNotExist(err) {
+		t.Errorf("the expired upload should be removed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(uploadDir("active"), "1.part")); err != nil {
+		t.Errorf("the active upload should be kept: %v", err)
+	}
+}
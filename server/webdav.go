@@ -3,12 +3,16 @@ package server
 import (
 	"context"
 	"net/http"
+	"strings"
 
+	"github.com/alist-org/alist/v3/internal/authz"
 	"github.com/alist-org/alist/v3/internal/db"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/middlewares"
 	"github.com/alist-org/alist/v3/server/webdav"
 	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -17,7 +21,7 @@ var handler *webdav.Handler
 func init() {
 	handler = &webdav.Handler{
 		Prefix:     "/dav",
-		LockSystem: webdav.NewMemLS(),
+		LockSystem: webdav.NewDBLS(),
 		Logger: func(request *http.Request, err error) {
 			log.Errorf("%s %s %+v", request.Method, request.URL.Path, err)
 		},
@@ -25,7 +29,7 @@ func init() {
 }
 
 func WebDav(dav *gin.RouterGroup) {
-	dav.Use(WebDAVAuth)
+	dav.Use(WebDAVAuth, middlewares.RateLimit)
 	dav.Any("/*path", ServeWebDAV)
 	dav.Any("", ServeWebDAV)
 	dav.Handle("PROPFIND", "/*path", ServeWebDAV)
@@ -41,6 +45,8 @@ func WebDav(dav *gin.RouterGroup) {
 func ServeWebDAV(c *gin.Context) {
 	user := c.MustGet("user").(*model.User)
 	ctx := context.WithValue(c.Request.Context(), "user", user)
+	ctx = context.WithValue(ctx, "ip", c.ClientIP())
+	ctx = context.WithValue(ctx, "via", "webdav")
 	handler.ServeHTTP(c.Writer, c.Request.WithContext(ctx))
 }
 
@@ -58,8 +64,8 @@ func WebDAVAuth(c *gin.Context) {
 		c.Abort()
 		return
 	}
-	user, err := db.GetUserByName(username)
-	if err != nil || user.ValidatePassword(password) != nil {
+	user, err := webdavUser(username, password)
+	if err != nil {
 		if c.Request.Method == "OPTIONS" {
 			c.Set("user", guest)
 			c.Next()
@@ -69,7 +75,8 @@ func WebDAVAuth(c *gin.Context) {
 		c.Abort()
 		return
 	}
-	if !user.CanWebdavRead() {
+	reqPath, _ := user.JoinPath(strings.TrimPrefix(c.Request.URL.Path, "/dav"))
+	if !authz.Can(user, model.ActionWebdav, reqPath) {
 		if c.Request.Method == "OPTIONS" {
 			c.Set("user", guest)
 			c.Next()
@@ -79,7 +86,7 @@ func WebDAVAuth(c *gin.Context) {
 		c.Abort()
 		return
 	}
-	if !user.CanWebdavManage() && utils.SliceContains([]string{"PUT", "DELETE", "PROPPATCH", "MKCOL", "COPY", "MOVE"}, c.Request.Method) {
+	if !authz.Can(user, model.ActionWebdavManage, reqPath) && utils.SliceContains([]string{"PUT", "DELETE", "PROPPATCH", "MKCOL", "COPY", "MOVE"}, c.Request.Method) {
 		if c.Request.Method == "OPTIONS" {
 			c.Set("user", guest)
 			c.Next()
@@ -90,5 +97,28 @@ func WebDAVAuth(c *gin.Context) {
 		return
 	}
 	c.Set("user", user)
+	c.Set("path", reqPath)
 	c.Next()
 }
+
+// webdavUser authenticates the user by password or personal access token
+func webdavUser(username, password string) (*model.User, error) {
+	if strings.HasPrefix(password, model.TokenPrefix) {
+		user, err := db.GetUserByToken(password)
+		if err != nil {
+			return nil, err
+		}
+		if user.Username != username {
+			return nil, errors.New("the token is not of the user")
+		}
+		return user, nil
+	}
+	user, err := db.GetUserByName(username)
+	if err != nil {
+		return nil, err
+	}
+	if err := user.ValidatePassword(password); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
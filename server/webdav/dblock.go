@@ -0,0 +1,298 @@
+package webdav
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/google/uuid"
+)
+
+// NewDBLS returns a LockSystem backed by the database, so the locks are kept
+// after restart and shared by the replicas using the same database.
+// The locks held by Confirm during a request are only known by this process,
+// and so are the temporary locks, which would never be released if the process crashed.
+func NewDBLS() LockSystem {
+	return &dbLS{held: make(map[string]bool)}
+}
+
+type dbLS struct {
+	mu   sync.Mutex
+	held map[string]bool
+}
+
+// tempLocks are the temporary locks of this process by token
+var tempLocks = struct {
+	sync.Mutex
+	byToken map[string]*model.WebdavLock
+}{byToken: make(map[string]*model.WebdavLock)}
+
+// tempLocksAround returns the temporary locks on the path, its ancestors and its descendants,
+// tempLocks should be locked by the caller
+func tempLocksAround(path string) []model.WebdavLock {
+	var locks []model.WebdavLock
+	for _, l := range tempLocks.byToken {
+		if l.Root == path || isDescendant(path, l.Root) || isDescendant(l.Root, path) {
+			locks = append(locks, *l)
+		}
+	}
+	return locks
+}
+
+// collectExpired deletes the expired locks, the held ones don't expire until released
+func (m *dbLS) collectExpired(now time.Time) error {
+	held := make([]string, 0, len(m.held))
+	for token := range m.held {
+		held = append(held, token)
+	}
+	tempLocks.Lock()
+	for token, l := range tempLocks.byToken {
+		if !m.held[token] && l.Expired(now.UnixNano()) {
+			delete(tempLocks.byToken, token)
+		}
+	}
+	tempLocks.Unlock()
+	return db.DeleteExpiredWebdavLocks(now.UnixNano(), held)
+}
+
+// get returns the lock of token, or nil if it doesn't exist
+func (m *dbLS) get(token string) (*model.WebdavLock, error) {
+	tempLocks.Lock()
+	l, ok := tempLocks.byToken[token]
+	tempLocks.Unlock()
+	if ok {
+		l := *l
+		return &l, nil
+	}
+	l, err := db.GetWebdavLock(token)
+	if errors.Is(err, errs.LockNotFound) {
+		return nil, nil
+	}
+	return l, err
+}
+
+func (m *dbLS) Confirm(now time.Time, name0, name1 string, conditions ...Condition) (func(), error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.collectExpired(now); err != nil {
+		return nil, err
+	}
+
+	var t0, t1 string
+	var err error
+	if name0 != "" {
+		if t0, err = m.lookup(slashClean(name0), conditions...); err != nil {
+			return nil, err
+		}
+		if t0 == "" {
+			return nil, ErrConfirmationFailed
+		}
+	}
+	if name1 != "" {
+		if t1, err = m.lookup(slashClean(name1), conditions...); err != nil {
+			return nil, err
+		}
+		if t1 == "" {
+			return nil, ErrConfirmationFailed
+		}
+	}
+
+	// Don't hold the same lock twice.
+	if t1 == t0 {
+		t1 = ""
+	}
+	for _, t := range []string{t0, t1} {
+		if t != "" {
+			m.held[t] = true
+		}
+	}
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		delete(m.held, t0)
+		delete(m.held, t1)
+	}, nil
+}
+
+// lookup returns the token of the lock that locks the named resource, provided that
+// the lock matches at least one of the given conditions and it isn't held by another party.
+func (m *dbLS) lookup(name string, conditions ...Condition) (string, error) {
+	// TODO: support Condition.Not and Condition.ETag.
+	for _, c := range conditions {
+		if c.Token == "" || m.held[c.Token] {
+			continue
+		}
+		l, err := m.get(c.Token)
+		if err != nil {
+			return "", err
+		}
+		if l != nil && l.Covers(name) {
+			return l.Token, nil
+		}
+	}
+	return "", nil
+}
+
+// checkCreate returns ErrLocked if the lock conflicts with the locks around its root
+func checkCreate(details LockDetails, locks []model.WebdavLock) error {
+	for _, l := range locks {
+		switch {
+		case l.Root == details.Root:
+			// The target is already locked.
+			return ErrLocked
+		case isDescendant(l.Root, details.Root):
+			// A descendant of the target is locked, which conflicts with an infinite depth lock.
+			if !details.ZeroDepth {
+				return ErrLocked
+			}
+		case !l.ZeroDepth:
+			// An ancestor of the target is locked with infinite depth.
+			return ErrLocked
+		}
+	}
+	return nil
+}
+
+func (m *dbLS) Create(now time.Time, details LockDetails) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.collectExpired(now); err != nil {
+		return "", err
+	}
+	details.Root = slashClean(details.Root)
+
+	l := model.WebdavLock{
+		Token:     "opaquelocktoken:" + uuid.NewString(),
+		Root:      details.Root,
+		Duration:  int64(details.Duration),
+		OwnerXML:  details.OwnerXML,
+		ZeroDepth: details.ZeroDepth,
+	}
+	if details.Duration >= 0 {
+		l.Expiry = now.Add(details.Duration).UnixNano()
+	}
+	tempLocks.Lock()
+	defer tempLocks.Unlock()
+	temps := tempLocksAround(details.Root)
+	check := func(locks []model.WebdavLock) error {
+		return checkCreate(details, append(locks, temps...))
+	}
+	if details.Temporary {
+		locks, err := db.GetWebdavLocksAround(details.Root)
+		if err != nil {
+			return "", err
+		}
+		if err := check(locks); err != nil {
+			return "", err
+		}
+		tempLocks.byToken[l.Token] = &l
+		return l.Token, nil
+	}
+	// the locks are checked in the transaction creating it, since the other replicas may create them
+	if err := db.CreateWebdavLock(&l, check); err != nil {
+		if errors.Is(err, ErrLocked) {
+			return "", ErrLocked
+		}
+		// the unique root is violated if another replica created the same lock at the same time
+		if locks, err := db.GetWebdavLocksAround(details.Root); err == nil && check(locks) != nil {
+			return "", ErrLocked
+		}
+		return "", err
+	}
+	return l.Token, nil
+}
+
+func (m *dbLS) Refresh(now time.Time, token string, duration time.Duration) (LockDetails, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.collectExpired(now); err != nil {
+		return LockDetails{}, err
+	}
+
+	l, err := m.get(token)
+	if err != nil {
+		return LockDetails{}, err
+	}
+	if l == nil {
+		return LockDetails{}, ErrNoSuchLock
+	}
+	if m.held[token] {
+		return LockDetails{}, ErrLocked
+	}
+	l.Duration = int64(duration)
+	if duration >= 0 {
+		l.Expiry = now.Add(duration).UnixNano()
+	}
+	tempLocks.Lock()
+	_, temporary := tempLocks.byToken[token]
+	if temporary {
+		tempLocks.byToken[token] = l
+	}
+	tempLocks.Unlock()
+	if !temporary {
+		if err := db.UpdateWebdavLock(l); err != nil {
+			return LockDetails{}, err
+		}
+	}
+	return LockDetails{
+		Root:      l.Root,
+		Duration:  duration,
+		OwnerXML:  l.OwnerXML,
+		ZeroDepth: l.ZeroDepth,
+		Temporary: temporary,
+	}, nil
+}
+
+func (m *dbLS) Unlock(now time.Time, token string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.collectExpired(now); err != nil {
+		return err
+	}
+
+	l, err := m.get(token)
+	if err != nil {
+		return err
+	}
+	if l == nil {
+		return ErrNoSuchLock
+	}
+	if m.held[token] {
+		return ErrLocked
+	}
+	tempLocks.Lock()
+	_, temporary := tempLocks.byToken[token]
+	delete(tempLocks.byToken, token)
+	tempLocks.Unlock()
+	if temporary {
+		return nil
+	}
+	return db.DeleteWebdavLock(token)
+}
+
+func isDescendant(name, root string) bool {
+	return name != root && (root == "/" || strings.HasPrefix(name, root+"/"))
+}
+
+// Locked checks if the path, its ancestors with infinite depth or its descendants are locked,
+// it's used to protect the locked objects from the writes out of webdav.
+func Locked(path string) (bool, error) {
+	locks, err := db.GetWebdavLocksAround(path)
+	if err != nil {
+		return false, err
+	}
+	tempLocks.Lock()
+	locks = append(locks, tempLocksAround(path)...)
+	tempLocks.Unlock()
+	now := time.Now().UnixNano()
+	for _, l := range locks {
+		if !l.Expired(now) && (l.Covers(path) || isDescendant(l.Root, path)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
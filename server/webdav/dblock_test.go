@@ -0,0 +1,161 @@
+package webdav
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	conf.Conf = conf.DefaultConfig()
+	d, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	db.Init(d)
+}
+
+// TestDBLS runs the same random operations on a memLS and a dbLS, their results should be the same
+func TestDBLS(t *testing.T) {
+	now := time.Unix(0, 0)
+	mem, dbls := NewMemLS(), NewDBLS()
+	rng := rand.New(rand.NewSource(0))
+	// the tokens of the same lock in mem and dbls
+	type tokens struct{ mem, db string }
+	locked := map[string]tokens{}
+	names := append(lockTestNames, "/", "/z/_", "/_/z/i/x")
+	const N = 1000
+
+	for i := 0; i < N; i++ {
+		name := names[rng.Intn(len(names))]
+		duration := lockTestDurations[rng.Intn(len(lockTestDurations))]
+		if rng.Intn(10) == 0 {
+			now = now.Add(time.Duration(rng.Intn(3)) * time.Hour)
+		}
+		tk, ok := locked[name]
+		if !ok || rng.Intn(4) == 0 {
+			details := LockDetails{Root: name, Duration: duration, ZeroDepth: rng.Intn(2) == 0}
+			memToken, memErr := mem.Create(now, details)
+			dbToken, dbErr := dbls.Create(now, details)
+			if memErr != dbErr {
+				t.Fatalf("iteration #%d: Create %q: mem %v, db %v", i, name, memErr, dbErr)
+			}
+			if memErr == nil {
+				locked[name] = tokens{mem: memToken, db: dbToken}
+			}
+			continue
+		}
+		switch rng.Intn(3) {
+		case 0:
+			other := names[rng.Intn(len(names))]
+			memRelease, memErr := mem.Confirm(now, name, other, Condition{Token: tk.mem}, Condition{Token: locked[other].mem})
+			dbRelease, dbErr := dbls.Confirm(now, name, other, Condition{Token: tk.db}, Condition{Token: locked[other].db})
+			if memErr != dbErr {
+				t.Fatalf("iteration #%d: Confirm %q %q: mem %v, db %v", i, name, other, memErr, dbErr)
+			}
+			if memErr == nil {
+				// the held locks can't be refreshed or unlocked
+				if _, err := dbls.Refresh(now, tk.db, duration); err != ErrLocked {
+					t.Fatalf("iteration #%d: Refresh held %q: %v", i, name, err)
+				}
+				memRelease()
+				dbRelease()
+			}
+		case 1:
+			memDetails, memErr := mem.Refresh(now, tk.mem, duration)
+			dbDetails, dbErr := dbls.Refresh(now, tk.db, duration)
+			if memErr != dbErr || memDetails != dbDetails {
+				t.Fatalf("iteration #%d: Refresh %q: mem %v %v, db %v %v", i, name, memDetails, memErr, dbDetails, dbErr)
+			}
+		case 2:
+			memErr := mem.Unlock(now, tk.mem)
+			dbErr := dbls.Unlock(now, tk.db)
+			if memErr != dbErr {
+				t.Fatalf("iteration #%d: Unlock %q: mem %v, db %v", i, name, memErr, dbErr)
+			}
+			delete(locked, name)
+		}
+	}
+}
+
+func TestLocked(t *testing.T) {
+	ls := NewDBLS()
+	now := time.Now()
+	token, err := ls.Create(now, LockDetails{Root: "/locked/dir/file", Duration: time.Hour, ZeroDepth: true})
+	if err != nil {
+		t.Fatalf("failed create lock: %v", err)
+	}
+	defer ls.Unlock(now, token)
+	expired, err := ls.Create(now.Add(-2*time.Hour), LockDetails{Root: "/expired", Duration: time.Hour})
+	if err != nil {
+		t.Fatalf("failed create lock: %v", err)
+	}
+	defer ls.Unlock(now, expired)
+	cases := map[string]bool{
+		"/locked/dir/file":       true,
+		"/locked/dir":            true,
+		"/locked":                true,
+		"/locked/dir/file2":      false,
+		"/locked/dir/file/child": false,
+		"/expired":               false,
+	}
+	for path, want := range cases {
+		got, err := Locked(path)
+		if err != nil {
+			t.Fatalf("failed check %s: %v", path, err)
+		}
+		if got != want {
+			t.Errorf("Locked(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestTemporaryLock(t *testing.T) {
+	ls := NewDBLS()
+	now := time.Now()
+	token, err := ls.Create(now, LockDetails{Root: "/temp/file", Duration: infiniteTimeout, ZeroDepth: true, Temporary: true})
+	if err != nil {
+		t.Fatalf("failed create lock: %v", err)
+	}
+	// the temporary locks aren't left in the database if the process crashes
+	if _, err := db.GetWebdavLock(token); !errors.Is(err, errs.LockNotFound) {
+		t.Errorf("the temporary lock shouldn't be in the database: %v", err)
+	}
+	if locked, err := Locked("/temp"); err != nil || !locked {
+		t.Errorf("the temporary lock should lock the ancestors: %v %v", locked, err)
+	}
+	if _, err := ls.Create(now, LockDetails{Root: "/temp", Duration: time.Hour}); err != ErrLocked {
+		t.Errorf("the lock should conflict with the temporary one: %v", err)
+	}
+	if err := ls.Unlock(now, token); err != nil {
+		t.Fatalf("failed unlock: %v", err)
+	}
+	if locked, err := Locked("/temp/file"); err != nil || locked {
+		t.Errorf("the temporary lock should be released: %v %v", locked, err)
+	}
+}
+
+func TestUniqueLockRoot(t *testing.T) {
+	noCheck := func([]model.WebdavLock) error { return nil }
+	l := model.WebdavLock{Token: "opaquelocktoken:unique1", Root: "/unique", Duration: -1}
+	if err := db.CreateWebdavLock(&l, noCheck); err != nil {
+		t.Fatalf("failed create lock: %+v", err)
+	}
+	defer db.DeleteWebdavLock(l.Token)
+	// the database rejects the same root even if the check passed in another replica
+	l2 := model.WebdavLock{Token: "opaquelocktoken:unique2", Root: "/unique", Duration: -1}
+	if err := db.CreateWebdavLock(&l2, noCheck); err == nil {
+		t.Errorf("the lock with the same root shouldn't be created")
+	}
+	if _, err := NewDBLS().Create(time.Now(), LockDetails{Root: "/unique", Duration: time.Hour}); err != ErrLocked {
+		t.Errorf("expect ErrLocked, got %v", err)
+	}
+}
@@ -10,7 +10,10 @@ import (
 	"path"
 	"path/filepath"
 
+	"github.com/alist-org/alist/v3/internal/authz"
 	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/fs"
 	"github.com/alist-org/alist/v3/internal/model"
 )
@@ -24,6 +27,17 @@ func slashClean(name string) string {
 	return path.Clean(name)
 }
 
+// checkAction checks if the user of ctx can do the action on all the paths
+func checkAction(ctx context.Context, action model.Action, paths ...string) (status int, err error) {
+	user := ctx.Value("user").(*model.User)
+	for _, p := range paths {
+		if !authz.CanWebdav(user, action, p) {
+			return http.StatusForbidden, errs.PermissionDenied
+		}
+	}
+	return 0, nil
+}
+
 // moveFiles moves files and/or directories from src to dst.
 //
 // See section 9.9.4 for when various HTTP status codes apply.
@@ -33,9 +47,31 @@ func moveFiles(ctx context.Context, src, dst string, overwrite bool) (status int
 	srcName := path.Base(src)
 	dstName := path.Base(dst)
 	if srcDir == dstDir {
+		if status, err := checkAction(ctx, model.ActionRename, src); err != nil {
+			return status, err
+		}
 		err = fs.Rename(ctx, src, dstName)
 	} else {
-		err = fs.Move(ctx, src, dstDir)
+		if status, err := checkAction(ctx, model.ActionMove, src, dstDir); err != nil {
+			return status, err
+		}
+		if srcName != dstName {
+			if status, err := checkAction(ctx, model.ActionRename, src); err != nil {
+				return status, err
+			}
+		}
+		var srcStorage, dstStorage driver.Driver
+		if srcStorage, err = fs.GetStorage(src); err != nil {
+			return http.StatusInternalServerError, err
+		}
+		if dstStorage, err = fs.GetStorage(dstDir); err != nil {
+			return http.StatusInternalServerError, err
+		}
+		// the move between two storages runs as a task, so it can't be renamed at the same time
+		if srcStorage.GetStorage() != dstStorage.GetStorage() && srcName != dstName {
+			return http.StatusBadGateway, errs.RenameBetweenTwoStorages
+		}
+		_, err = fs.Move(ctx, src, dstDir)
 		if err != nil {
 			return http.StatusInternalServerError, err
 		}
@@ -56,6 +92,9 @@ func moveFiles(ctx context.Context, src, dst string, overwrite bool) (status int
 //
 // See section 9.8.5 for when various HTTP status codes apply.
 func copyFiles(ctx context.Context, src, dst string, overwrite bool) (status int, err error) {
+	if status, err := checkAction(ctx, model.ActionCopy, src, path.Dir(dst)); err != nil {
+		return status, err
+	}
 	_, err = fs.Copy(ctx, src, dst)
 	if err != nil {
 		return http.StatusInternalServerError, err
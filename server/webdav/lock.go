@@ -109,6 +109,9 @@ type LockDetails struct {
 	// ZeroDepth is whether the lock has zero depth. If it does not have zero
 	// depth, it has infinite depth.
 	ZeroDepth bool
+	// Temporary is whether the lock is created for a request without If header,
+	// it's released at the end of the request.
+	Temporary bool
 }
 
 // NewMemLS returns a new in-memory LockSystem.
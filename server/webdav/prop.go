@@ -15,6 +15,8 @@ import (
 	"path"
 	"strconv"
 
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/fs"
 	"github.com/alist-org/alist/v3/internal/model"
 )
 
@@ -106,6 +108,9 @@ var liveProps = map[xml.Name]struct {
 	findFn func(context.Context, LockSystem, string, model.Obj) (string, error)
 	// dir is true if the property applies to directories.
 	dir bool
+	// named is true if the property is only returned by allprop when it's included,
+	// see http://www.webdav.org/specs/rfc4331.html#rfc.section.3
+	named bool
 }{
 	{Space: "DAV:", Local: "resourcetype"}: {
 		findFn: findResourceType,
@@ -158,6 +163,42 @@ var liveProps = map[xml.Name]struct {
 		findFn: findSupportedLock,
 		dir:    true,
 	},
+	{Space: "DAV:", Local: "quota-available-bytes"}: {
+		findFn: findQuotaAvailableBytes,
+		dir:    true,
+		named:  true,
+	},
+	{Space: "DAV:", Local: "quota-used-bytes"}: {
+		findFn: findQuotaUsedBytes,
+		dir:    true,
+		named:  true,
+	},
+}
+
+// deadPropsCache loads the dead properties of all the objects in a dir at once,
+// because PROPFIND usually asks for the properties of all the children of a dir.
+type deadPropsCache map[string]map[string]map[xml.Name]Property // dir -> path -> props
+
+// get returns the dead properties of the full path name
+func (c deadPropsCache) get(name string) (map[xml.Name]Property, error) {
+	dir := path.Dir(name)
+	byPath, ok := c[dir]
+	if !ok {
+		props, err := db.GetWebdavPropsByParent(dir)
+		if err != nil {
+			return nil, err
+		}
+		byPath = make(map[string]map[xml.Name]Property)
+		for _, p := range props {
+			if byPath[p.Path] == nil {
+				byPath[p.Path] = make(map[xml.Name]Property)
+			}
+			pn := xml.Name{Space: p.Space, Local: p.Local}
+			byPath[p.Path][pn] = Property{XMLName: pn, Lang: p.Lang, InnerXML: []byte(p.InnerXML)}
+		}
+		c[dir] = byPath
+	}
+	return byPath[name], nil
 }
 
 // TODO(nigeltao) merge props and allprop?
@@ -166,27 +207,9 @@ var liveProps = map[xml.Name]struct {
 //
 // Each Propstat has a unique status and each property name will only be part
 // of one Propstat element.
-func props(ctx context.Context, ls LockSystem, fi model.Obj, pnames []xml.Name) ([]Propstat, error) {
-	//f, err := fs.OpenFile(ctx, name, os.O_RDONLY, 0)
-	//if err != nil {
-	//	return nil, err
-	//}
-	//defer f.Close()
-	//fi, err := f.Stat()
-	//if err != nil {
-	//	return nil, err
-	//}
+func props(ctx context.Context, ls LockSystem, name string, fi model.Obj, deadProps map[xml.Name]Property, pnames []xml.Name) ([]Propstat, error) {
 	isDir := fi.IsDir()
 
-	var deadProps map[xml.Name]Property
-	// ??? what is this for?
-	//if dph, ok := f.(DeadPropsHolder); ok {
-	//	deadProps, err = dph.DeadProps()
-	//	if err != nil {
-	//		return nil, err
-	//	}
-	//}
-
 	pstatOK := Propstat{Status: http.StatusOK}
 	pstatNotFound := Propstat{Status: http.StatusNotFound}
 	for _, pn := range pnames {
@@ -197,7 +220,13 @@ func props(ctx context.Context, ls LockSystem, fi model.Obj, pnames []xml.Name)
 		}
 		// Otherwise, it must either be a live property or we don't know it.
 		if prop := liveProps[pn]; prop.findFn != nil && (prop.dir || !isDir) {
-			innerXML, err := prop.findFn(ctx, ls, fi.GetName(), fi)
+			innerXML, err := prop.findFn(ctx, ls, name, fi)
+			if err == ErrNotImplemented {
+				pstatNotFound.Props = append(pstatNotFound.Props, Property{
+					XMLName: pn,
+				})
+				continue
+			}
 			if err != nil {
 				return nil, err
 			}
@@ -215,27 +244,9 @@ func props(ctx context.Context, ls LockSystem, fi model.Obj, pnames []xml.Name)
 }
 
 // Propnames returns the property names defined for resource name.
-func propnames(ctx context.Context, ls LockSystem, fi model.Obj) ([]xml.Name, error) {
-	//f, err := fs.OpenFile(ctx, name, os.O_RDONLY, 0)
-	//if err != nil {
-	//	return nil, err
-	//}
-	//defer f.Close()
-	//fi, err := f.Stat()
-	//if err != nil {
-	//	return nil, err
-	//}
+func propnames(ctx context.Context, ls LockSystem, name string, fi model.Obj, deadProps map[xml.Name]Property) ([]xml.Name, error) {
 	isDir := fi.IsDir()
 
-	var deadProps map[xml.Name]Property
-	// ??? what is this for?
-	//if dph, ok := f.(DeadPropsHolder); ok {
-	//	deadProps, err = dph.DeadProps()
-	//	if err != nil {
-	//		return nil, err
-	//	}
-	//}
-
 	pnames := make([]xml.Name, 0, len(liveProps)+len(deadProps))
 	for pn, prop := range liveProps {
 		if prop.findFn != nil && (prop.dir || !isDir) {
@@ -256,11 +267,17 @@ func propnames(ctx context.Context, ls LockSystem, fi model.Obj) ([]xml.Name, er
 // returned if they are named in 'include'.
 //
 // See http://www.webdav.org/specs/rfc4918.html#METHOD_PROPFIND
-func allprop(ctx context.Context, ls LockSystem, fi model.Obj, include []xml.Name) ([]Propstat, error) {
-	pnames, err := propnames(ctx, ls, fi)
+func allprop(ctx context.Context, ls LockSystem, name string, fi model.Obj, deadProps map[xml.Name]Property, include []xml.Name) ([]Propstat, error) {
+	names, err := propnames(ctx, ls, name, fi, deadProps)
 	if err != nil {
 		return nil, err
 	}
+	pnames := names[:0]
+	for _, pn := range names {
+		if !liveProps[pn].named {
+			pnames = append(pnames, pn)
+		}
+	}
 	// Add names from include if they are not already covered in pnames.
 	nameset := make(map[xml.Name]bool)
 	for _, pn := range pnames {
@@ -271,7 +288,7 @@ func allprop(ctx context.Context, ls LockSystem, fi model.Obj, include []xml.Nam
 			pnames = append(pnames, pn)
 		}
 	}
-	return props(ctx, ls, fi, pnames)
+	return props(ctx, ls, name, fi, deadProps, pnames)
 }
 
 // Patch patches the properties of resource name. The return values are
@@ -307,37 +324,27 @@ loop:
 		return makePropstats(pstatForbidden, pstatFailedDep), nil
 	}
 
-	// ------------------------------------------------------------
-	//f, err := fs.OpenFile(ctx, name, os.O_RDWR, 0)
-	//if err != nil {
-	//	return nil, err
-	//}
-	//defer f.Close()
-	//if dph, ok := f.(DeadPropsHolder); ok {
-	//	ret, err := dph.Patch(patches)
-	//	if err != nil {
-	//		return nil, err
-	//	}
-	//	// http://www.webdav.org/specs/rfc4918.html#ELEMENT_propstat says that
-	//	// "The contents of the prop XML element must only list the names of
-	//	// properties to which the result in the status element applies."
-	//	for _, pstat := range ret {
-	//		for i, p := range pstat.Props {
-	//			pstat.Props[i] = Property{XMLName: p.XMLName}
-	//		}
-	//	}
-	//	return ret, nil
-	//}
-	// ------------------------------------------------------------
-
-	// The file doesn't implement the optional DeadPropsHolder interface, so
-	// all patches are forbidden.
-	pstat := Propstat{Status: http.StatusForbidden}
+	// The dead properties are saved in the database by the full path name.
+	var set, remove []model.WebdavProp
+	// http://www.webdav.org/specs/rfc4918.html#ELEMENT_propstat says that
+	// "The contents of the prop XML element must only list the names of
+	// properties to which the result in the status element applies."
+	pstat := Propstat{Status: http.StatusOK}
 	for _, patch := range patches {
 		for _, p := range patch.Props {
+			prop := model.WebdavProp{Space: p.XMLName.Space, Local: p.XMLName.Local}
+			if patch.Remove {
+				remove = append(remove, prop)
+			} else {
+				prop.Lang, prop.InnerXML = p.Lang, string(p.InnerXML)
+				set = append(set, prop)
+			}
 			pstat.Props = append(pstat.Props, Property{XMLName: p.XMLName})
 		}
 	}
+	if err := db.PatchWebdavProps(name, set, remove); err != nil {
+		return nil, err
+	}
 	return []Propstat{pstat}, nil
 }
 
@@ -369,7 +376,7 @@ func findResourceType(ctx context.Context, ls LockSystem, name string, fi model.
 }
 
 func findDisplayName(ctx context.Context, ls LockSystem, name string, fi model.Obj) (string, error) {
-	if slashClean(name) == "/" {
+	if slashClean(fi.GetName()) == "/" {
 		// Hide the real name of a possibly prefixed root directory.
 		return "", nil
 	}
@@ -467,6 +474,32 @@ func findETag(ctx context.Context, ls LockSystem, name string, fi model.Obj) (st
 	return fmt.Sprintf(`"%x%x"`, fi.ModTime().UnixNano(), fi.GetSize()), nil
 }
 
+// findSpace gets the capacity of the storage that name is in,
+// the quota properties are not found if it's unknown
+func findSpace(ctx context.Context, name string) (*model.Space, error) {
+	space, err := fs.GetSpace(ctx, name)
+	if err != nil || space.Total <= 0 {
+		return nil, ErrNotImplemented
+	}
+	return space, nil
+}
+
+func findQuotaAvailableBytes(ctx context.Context, ls LockSystem, name string, fi model.Obj) (string, error) {
+	space, err := findSpace(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(space.Free(), 10), nil
+}
+
+func findQuotaUsedBytes(ctx context.Context, ls LockSystem, name string, fi model.Obj) (string, error) {
+	space, err := findSpace(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(space.Used, 10), nil
+}
+
 func findSupportedLock(ctx context.Context, ls LockSystem, name string, fi model.Obj) (string, error) {
 	return `` +
 		`<D:lockentry xmlns:D="DAV:">` +
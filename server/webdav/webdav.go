@@ -15,6 +15,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/alist-org/alist/v3/internal/audit"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/fs"
 	"github.com/alist-org/alist/v3/internal/model"
@@ -93,6 +94,7 @@ func (h *Handler) lock(now time.Time, root string) (token string, status int, er
 		Root:      root,
 		Duration:  infiniteTimeout,
 		ZeroDepth: true,
+		Temporary: true,
 	})
 	if err != nil {
 		if err == ErrLocked {
@@ -215,6 +217,9 @@ func (h *Handler) handleGetHeadPost(w http.ResponseWriter, r *http.Request) (sta
 	if err != nil {
 		return 403, err
 	}
+	if status, err := checkAction(ctx, model.ActionRead, reqPath); err != nil {
+		return status, err
+	}
 	fi, err := fs.Get(ctx, reqPath)
 	if err != nil {
 		return http.StatusNotFound, err
@@ -251,6 +256,13 @@ func (h *Handler) handleGetHeadPost(w http.ResponseWriter, r *http.Request) (sta
 		if err != nil {
 			return http.StatusInternalServerError, err
 		}
+		if link.URL == "" {
+			// the link without url can't be redirected to
+			if err := common.Proxy(w, r, link, fi); err != nil {
+				return http.StatusInternalServerError, err
+			}
+			return 0, nil
+		}
 		http.Redirect(w, r, link.URL, http.StatusFound)
 	}
 	return 0, nil
@@ -273,6 +285,9 @@ func (h *Handler) handleDelete(w http.ResponseWriter, r *http.Request) (status i
 	if err != nil {
 		return 403, err
 	}
+	if status, err := checkAction(ctx, model.ActionRemove, reqPath); err != nil {
+		return status, err
+	}
 	// TODO: return MultiStatus where appropriate.
 
 	// "godoc os RemoveAll" says that "If the path does not exist, RemoveAll
@@ -309,6 +324,9 @@ func (h *Handler) handlePut(w http.ResponseWriter, r *http.Request) (status int,
 	if err != nil {
 		return 403, err
 	}
+	if status, err := checkAction(ctx, model.ActionWrite, reqPath); err != nil {
+		return status, err
+	}
 	obj := model.Object{
 		Name:     path.Base(reqPath),
 		Size:     r.ContentLength,
@@ -358,6 +376,9 @@ func (h *Handler) handleMkcol(w http.ResponseWriter, r *http.Request) (status in
 	if err != nil {
 		return 403, err
 	}
+	if status, err := checkAction(ctx, model.ActionWrite, reqPath); err != nil {
+		return status, err
+	}
 
 	if r.ContentLength > 0 {
 		return http.StatusUnsupportedMediaType, nil
@@ -590,6 +611,9 @@ func (h *Handler) handlePropfind(w http.ResponseWriter, r *http.Request) (status
 	if err != nil {
 		return 403, err
 	}
+	if status, err := checkAction(ctx, model.ActionList, reqPath); err != nil {
+		return status, err
+	}
 	fi, err := fs.Get(ctx, reqPath)
 	if err != nil {
 		if errs.IsObjectNotFound(err) {
@@ -610,14 +634,19 @@ func (h *Handler) handlePropfind(w http.ResponseWriter, r *http.Request) (status
 	}
 
 	mw := multistatusWriter{w: w}
+	dead := deadPropsCache{}
 
 	walkFn := func(reqPath string, info model.Obj, err error) error {
+		if err != nil {
+			return err
+		}
+		deadProps, err := dead.get(reqPath)
 		if err != nil {
 			return err
 		}
 		var pstats []Propstat
 		if pf.Propname != nil {
-			pnames, err := propnames(ctx, h.LockSystem, info)
+			pnames, err := propnames(ctx, h.LockSystem, reqPath, info, deadProps)
 			if err != nil {
 				return err
 			}
@@ -627,9 +656,9 @@ func (h *Handler) handlePropfind(w http.ResponseWriter, r *http.Request) (status
 			}
 			pstats = append(pstats, pstat)
 		} else if pf.Allprop != nil {
-			pstats, err = allprop(ctx, h.LockSystem, info, pf.Prop)
+			pstats, err = allprop(ctx, h.LockSystem, reqPath, info, deadProps, pf.Prop)
 		} else {
-			pstats, err = props(ctx, h.LockSystem, info, pf.Prop)
+			pstats, err = props(ctx, h.LockSystem, reqPath, info, deadProps, pf.Prop)
 		}
 		if err != nil {
 			return err
@@ -669,6 +698,9 @@ func (h *Handler) handleProppatch(w http.ResponseWriter, r *http.Request) (statu
 	if err != nil {
 		return 403, err
 	}
+	if status, err := checkAction(ctx, model.ActionWrite, reqPath); err != nil {
+		return status, err
+	}
 	if _, err := fs.Get(ctx, reqPath); err != nil {
 		if errs.IsObjectNotFound(err) {
 			return http.StatusNotFound, err
@@ -680,6 +712,7 @@ func (h *Handler) handleProppatch(w http.ResponseWriter, r *http.Request) (statu
 		return status, err
 	}
 	pstats, err := patch(ctx, h.LockSystem, reqPath, patches)
+	audit.Log(ctx, audit.WebdavProppatch, reqPath, "", err)
 	if err != nil {
 		return http.StatusInternalServerError, err
 	}